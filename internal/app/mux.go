@@ -352,21 +352,21 @@ func websocketHandlerConfig(appCfg config.Config) centrifuge.WebsocketConfig {
 
 func httpStreamHandlerConfig(appCfg config.Config) centrifuge.HTTPStreamConfig {
 	return centrifuge.HTTPStreamConfig{
-		MaxRequestBodySize: appCfg.HTTPStream.MaxRequestBodySize,
+		MaxRequestBodySize: int(appCfg.HTTPStream.MaxRequestBodySize.Bytes()),
 		PingPongConfig:     getPingPongConfig(appCfg),
 	}
 }
 
 func sseHandlerConfig(appCfg config.Config) centrifuge.SSEConfig {
 	return centrifuge.SSEConfig{
-		MaxRequestBodySize: appCfg.SSE.MaxRequestBodySize,
+		MaxRequestBodySize: int(appCfg.SSE.MaxRequestBodySize.Bytes()),
 		PingPongConfig:     getPingPongConfig(appCfg),
 	}
 }
 
 func emulationHandlerConfig(cfg config.Config) centrifuge.EmulationConfig {
 	return centrifuge.EmulationConfig{
-		MaxRequestBodySize: cfg.Emulation.MaxRequestBodySize,
+		MaxRequestBodySize: int(cfg.Emulation.MaxRequestBodySize.Bytes()),
 	}
 }
 
@@ -1,8 +1,10 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/client"
 	"github.com/centrifugal/centrifugo/v6/internal/config"
@@ -11,8 +13,15 @@ import (
 	"github.com/centrifugal/centrifugo/v6/internal/tools"
 
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
 )
 
+// proxyWarmupTimeout bounds warming up of all proxies on start.
+const proxyWarmupTimeout = 5 * time.Second
+
+// proxyValidateTimeout bounds validation of all proxies on start.
+const proxyValidateTimeout = 5 * time.Second
+
 func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 	proxyMap := &client.ProxyMap{
 		ConnectProxy:           nil,
@@ -26,18 +35,28 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 		CacheEmptyProxies: map[string]proxy.CacheEmptyProxy{},
 	}
 
+	if len(cfg.Prometheus.ProxyCallLatencyBuckets) > 0 {
+		if err := proxy.SetCallLatencyBuckets(cfg.Prometheus.ProxyCallLatencyBuckets); err != nil {
+			return nil, false, fmt.Errorf("error setting proxy call latency buckets: %w", err)
+		}
+	}
+
 	var keepHeadersInContext bool
+	warmups := map[string]proxy.WarmupProxy{}
 
 	var err error
 	var proxyFound bool
 
+	// Global tracer provider delegates to the one configured by telemetry.SetupTracing later.
+	useProxyOpentelemetry := cfg.OpenTelemetry.Enabled && cfg.OpenTelemetry.Proxy
+
 	if cfg.Client.Proxy.Connect.Enabled {
 		p := cfg.Client.Proxy.Connect
 		proxyMap.ConnectProxy, err = proxy.GetConnectProxy("connect", cfg.Client.Proxy.Connect.Proxy)
 		if err != nil {
 			return nil, false, fmt.Errorf("error creating connect proxy: %w", err)
 		}
-		log.Info().Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("connect proxy enabled")
+		log.Info().Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("connect proxy enabled")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -49,7 +68,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 		if err != nil {
 			return nil, false, fmt.Errorf("error creating refresh proxy: %w", err)
 		}
-		log.Info().Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("refresh proxy enabled")
+		log.Info().Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("refresh proxy enabled")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -79,7 +98,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 			}
 			proxyMap.SubscribeProxies[subscribeProxyName] = sp
 		}
-		log.Info().Str("proxy_name", subscribeProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("subscribe proxy enabled for channels without namespace")
+		log.Info().Str("proxy_name", subscribeProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("subscribe proxy enabled for channels without namespace")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -104,7 +123,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 			}
 			proxyMap.PublishProxies[publishProxyName] = pp
 		}
-		log.Info().Str("proxy_name", publishProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("publish proxy enabled for channels without namespace")
+		log.Info().Str("proxy_name", publishProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("publish proxy enabled for channels without namespace")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -129,7 +148,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 			}
 			proxyMap.SubRefreshProxies[subRefreshProxyName] = srp
 		}
-		log.Info().Str("proxy_name", subRefreshProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("sub refresh proxy enabled for channels without namespace")
+		log.Info().Str("proxy_name", subRefreshProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("sub refresh proxy enabled for channels without namespace")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -147,7 +166,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 				return nil, false, fmt.Errorf("subscribe stream proxy not found: %s", subscribeStreamProxyName)
 			}
 		}
-		if strings.HasPrefix(p.Endpoint, "http") {
+		if strings.HasPrefix(string(p.Endpoint), "http") {
 			log.Fatal().Str("name", subscribeStreamProxyName).Msg("error creating subscribe stream proxy – only GRPC endpoints supported")
 		}
 		if _, ok := proxyMap.SubscribeStreamProxies[subscribeStreamProxyName]; !ok {
@@ -157,7 +176,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 			}
 			proxyMap.SubscribeStreamProxies[subscribeStreamProxyName] = sp
 		}
-		log.Info().Str("proxy_name", subscribeStreamProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("subscribe stream proxy enabled for channels without namespace")
+		log.Info().Str("proxy_name", subscribeStreamProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("subscribe stream proxy enabled for channels without namespace")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -176,13 +195,19 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 			}
 		}
 		if _, ok := proxyMap.CacheEmptyProxies[cacheEmptyProxyName]; !ok {
+			if useProxyOpentelemetry {
+				p.TracerProvider = otel.GetTracerProvider()
+			}
 			cep, err := proxy.GetCacheEmptyProxy(cacheEmptyProxyName, p)
 			if err != nil {
 				return nil, false, fmt.Errorf("error creating cache empty proxy %s: %w", cacheEmptyProxyName, err)
 			}
 			proxyMap.CacheEmptyProxies[cacheEmptyProxyName] = cep
+			if wp, ok := cep.(proxy.WarmupProxy); ok && p.Warmup {
+				warmups[cacheEmptyProxyName] = wp
+			}
 		}
-		log.Info().Str("proxy_name", cacheEmptyProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("cache empty proxy enabled for channels without namespace")
+		log.Info().Str("proxy_name", cacheEmptyProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("cache empty proxy enabled for channels without namespace")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -208,7 +233,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 				}
 				proxyMap.SubscribeProxies[subscribeProxyName] = sp
 			}
-			log.Info().Str("proxy_name", subscribeProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Str("namespace", ns.Name).Msg("subscribe proxy enabled for channels in namespace")
+			log.Info().Str("proxy_name", subscribeProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Str("namespace", ns.Name).Msg("subscribe proxy enabled for channels in namespace")
 			if len(p.HttpHeaders) > 0 {
 				keepHeadersInContext = true
 			}
@@ -233,7 +258,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 				}
 				proxyMap.PublishProxies[publishProxyName] = pp
 			}
-			log.Info().Str("proxy_name", publishProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Str("namespace", ns.Name).Msg("publish proxy enabled for channels in namespace")
+			log.Info().Str("proxy_name", publishProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Str("namespace", ns.Name).Msg("publish proxy enabled for channels in namespace")
 			if len(p.HttpHeaders) > 0 {
 				keepHeadersInContext = true
 			}
@@ -258,7 +283,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 				}
 				proxyMap.SubRefreshProxies[subRefreshProxyName] = srp
 			}
-			log.Info().Str("proxy_name", subRefreshProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Str("namespace", ns.Name).Msg("sub refresh proxy enabled for channels in namespace")
+			log.Info().Str("proxy_name", subRefreshProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Str("namespace", ns.Name).Msg("sub refresh proxy enabled for channels in namespace")
 			if len(p.HttpHeaders) > 0 {
 				keepHeadersInContext = true
 			}
@@ -276,7 +301,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 					return nil, false, fmt.Errorf("subscribe stream proxy not found: %s", subscribeStreamProxyName)
 				}
 			}
-			if strings.HasPrefix(p.Endpoint, "http") {
+			if strings.HasPrefix(string(p.Endpoint), "http") {
 				return nil, false, fmt.Errorf("error creating subscribe stream proxy %s only GRPC endpoints supported", subscribeStreamProxyName)
 			}
 			if _, ok := proxyMap.SubscribeStreamProxies[subscribeStreamProxyName]; !ok {
@@ -286,7 +311,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 				}
 				proxyMap.SubscribeStreamProxies[subscribeStreamProxyName] = sp
 			}
-			log.Info().Str("proxy_name", subscribeStreamProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Str("namespace", ns.Name).Msg("subscribe stream proxy enabled for channels in namespace")
+			log.Info().Str("proxy_name", subscribeStreamProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Str("namespace", ns.Name).Msg("subscribe stream proxy enabled for channels in namespace")
 			if len(p.HttpHeaders) > 0 {
 				keepHeadersInContext = true
 			}
@@ -305,13 +330,19 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 				}
 			}
 			if _, ok := proxyMap.CacheEmptyProxies[cacheEmptyProxyName]; !ok {
+				if useProxyOpentelemetry {
+					p.TracerProvider = otel.GetTracerProvider()
+				}
 				cep, err := proxy.GetCacheEmptyProxy(cacheEmptyProxyName, p)
 				if err != nil {
 					return nil, false, fmt.Errorf("error creating cache empty proxy %s: %w", cacheEmptyProxyName, err)
 				}
 				proxyMap.CacheEmptyProxies[cacheEmptyProxyName] = cep
+				if wp, ok := cep.(proxy.WarmupProxy); ok && p.Warmup {
+					warmups[cacheEmptyProxyName] = wp
+				}
 			}
-			log.Info().Str("proxy_name", cacheEmptyProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Str("namespace", ns.Name).Msg("cache empty proxy enabled for channels in namespace")
+			log.Info().Str("proxy_name", cacheEmptyProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Str("namespace", ns.Name).Msg("cache empty proxy enabled for channels in namespace")
 			if len(p.HttpHeaders) > 0 {
 				keepHeadersInContext = true
 			}
@@ -337,7 +368,7 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 			}
 			proxyMap.RpcProxies[rpcProxyName] = rp
 		}
-		log.Info().Str("proxy_name", rpcProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Msg("RPC proxy enabled for methods without namespace")
+		log.Info().Str("proxy_name", rpcProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Msg("RPC proxy enabled for methods without namespace")
 		if len(p.HttpHeaders) > 0 {
 			keepHeadersInContext = true
 		}
@@ -363,12 +394,28 @@ func buildProxyMap(cfg config.Config) (*client.ProxyMap, bool, error) {
 				}
 				proxyMap.RpcProxies[rpcProxyName] = rp
 			}
-			log.Info().Str("proxy_name", rpcProxyName).Str("endpoint", tools.RedactedLogURLs(p.Endpoint)[0]).Str("namespace", ns.Name).Msg("RPC proxy enabled for namespace")
+			log.Info().Str("proxy_name", rpcProxyName).Str("endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]).Str("namespace", ns.Name).Msg("RPC proxy enabled for namespace")
 			if len(p.HttpHeaders) > 0 {
 				keepHeadersInContext = true
 			}
 		}
 	}
 
+	if len(warmups) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), proxyWarmupTimeout)
+		proxy.WarmupProxies(ctx, warmups)
+		cancel()
+	}
+
+	if cfg.Channel.ValidateProxies && len(proxyMap.CacheEmptyProxies) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), proxyValidateTimeout)
+		err := proxy.ValidateProxies(ctx, proxyMap.CacheEmptyProxies)
+		cancel()
+		if err != nil {
+			return nil, false, fmt.Errorf("error validating cache empty proxies: %w", err)
+		}
+		log.Info().Int("num_proxies", len(proxyMap.CacheEmptyProxies)).Msg("cache empty proxies validated")
+	}
+
 	return proxyMap, keepHeadersInContext, nil
 }
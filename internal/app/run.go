@@ -292,14 +292,15 @@ func Run(cmd *cobra.Command, configFile string) {
 
 	handleSignals(
 		cmd, configFile, node, cfgContainer, tokenVerifier, subTokenVerifier,
-		httpServers, grpcAPIServer, grpcUniServer,
+		clientHandler, httpServers, grpcAPIServer, grpcUniServer,
 		serviceDone, serviceCancel,
 	)
 }
 
 func handleSignals(
 	cmd *cobra.Command, configFile string, n *centrifuge.Node, cfgContainer *config.Container,
-	tokenVerifier *jwtverify.VerifierJWT, subTokenVerifier *jwtverify.VerifierJWT, httpServers []*http.Server,
+	tokenVerifier *jwtverify.VerifierJWT, subTokenVerifier *jwtverify.VerifierJWT,
+	clientHandler *client.Handler, httpServers []*http.Server,
 	grpcAPIServer *grpc.Server, grpcUniServer *grpc.Server, serviceDone chan struct{},
 	serviceCancel context.CancelFunc,
 ) {
@@ -389,6 +390,10 @@ func handleSignals(
 			_ = n.Shutdown(context.Background()) // We have a separate timeout goroutine.
 			wg.Wait()
 
+			if err := clientHandler.Close(context.Background()); err != nil {
+				log.Error().Err(err).Msg("error closing client handler")
+			}
+
 			serviceCancel()
 			<-serviceDone
 
@@ -80,7 +80,7 @@ func genSubToken(
 		fmt.Print(token)
 		return
 	}
-	fmt.Printf("HMAC SHA-256 JWT for %s and channel \"%s\" %s:\n%s\n", user, genSubTokenChannel, exp, token)
+	fmt.Printf("HMAC %s JWT for %s and channel \"%s\" %s:\n%s\n", verifierConfig.HMACSigningAlgorithm(), user, genSubTokenChannel, exp, token)
 }
 
 // generateSubToken generates sample subscription JWT for user.
@@ -88,7 +88,7 @@ func generateSubToken(config jwtverify.VerifierConfig, user string, channel stri
 	if config.HMACSecretKey == "" {
 		return "", errors.New("no HMAC secret key set")
 	}
-	signer, err := jwt.NewSignerHS(jwt.HS256, []byte(config.HMACSecretKey))
+	signer, err := jwt.NewSignerHS(config.HMACSigningAlgorithm(), []byte(config.HMACSecretKey))
 	if err != nil {
 		return "", fmt.Errorf("error creating HMAC signer: %w", err)
 	}
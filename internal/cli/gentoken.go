@@ -64,7 +64,7 @@ func genToken(cmd *cobra.Command, genTokenConfigFile string, genTokenUser string
 		fmt.Print(token)
 		return
 	}
-	fmt.Printf("HMAC SHA-256 JWT for %s %s:\n%s\n", user, exp, token)
+	fmt.Printf("HMAC %s JWT for %s %s:\n%s\n", verifierConfig.HMACSigningAlgorithm(), user, exp, token)
 }
 
 // generateToken generates sample JWT for user.
@@ -72,7 +72,7 @@ func generateToken(config jwtverify.VerifierConfig, user string, ttlSeconds int6
 	if config.HMACSecretKey == "" {
 		return "", errors.New("no HMAC secret key set")
 	}
-	signer, err := jwt.NewSignerHS(jwt.HS256, []byte(config.HMACSecretKey))
+	signer, err := jwt.NewSignerHS(config.HMACSigningAlgorithm(), []byte(config.HMACSecretKey))
 	if err != nil {
 		return "", fmt.Errorf("error creating HMAC signer: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"unicode"
 
 	"github.com/centrifugal/centrifugo/v6/internal/clientcontext"
@@ -44,6 +45,8 @@ type Handler struct {
 	subTokenVerifier *jwtverify.VerifierJWT
 	proxyMap         *ProxyMap
 	rpcExtension     map[string]RPCExtensionFunc
+
+	cacheEmptyHandler *proxy.CacheEmptyHandler
 }
 
 // NewHandler creates new Handler.
@@ -129,21 +132,29 @@ func (h *Handler) Setup() error {
 	}
 
 	if len(h.proxyMap.CacheEmptyProxies) > 0 {
-		cacheEmptyHandler := proxy.NewCacheEmptyHandler(proxy.CacheEmptyHandlerConfig{
-			Proxies: h.proxyMap.CacheEmptyProxies,
+		var err error
+		h.cacheEmptyHandler, err = proxy.NewCacheEmptyHandler(proxy.CacheEmptyHandlerConfig{
+			Proxies:   h.proxyMap.CacheEmptyProxies,
+			ProxyName: h.cacheEmptyProxyName,
 		})
+		if err != nil {
+			return fmt.Errorf("error creating cache empty handler: %w", err)
+		}
+		cacheEmptyHandler := h.cacheEmptyHandler.Handle()
 		h.node.OnCacheEmpty(func(e centrifuge.CacheEmptyEvent) (centrifuge.CacheEmptyReply, error) {
+			// CacheEmptyEvent does not carry subscriber, so subscriber info can't be attached
+			// with proxy.WithCacheEmptyClient here.
 			resp, err := cacheEmptyHandler(context.Background(), e.Channel)
 			if err != nil {
+				if errors.Is(err, proxy.ErrNoCacheEmptyProxy) {
+					// Nobody tried to populate cache – keep the cache empty.
+					return centrifuge.CacheEmptyReply{}, nil
+				}
 				log.Error().Err(err).Str("channel", e.Channel).Msg("error calling cache empty proxy")
 				return centrifuge.CacheEmptyReply{}, err
 			}
-			populated := false
-			if resp != nil && resp.Result != nil {
-				populated = resp.Result.Populated
-			}
 			return centrifuge.CacheEmptyReply{
-				Populated: populated,
+				Populated: resp.Result.Populated,
 			}, nil
 		})
 	}
@@ -264,6 +275,24 @@ func tokenChannelsChanged(client *centrifuge.Client, subs map[string]centrifuge.
 	return false
 }
 
+// Close waits for in-flight proxy calls to finish (until ctx is done) and releases
+// resources held by proxies.
+func (h *Handler) Close(ctx context.Context) error {
+	if h.cacheEmptyHandler != nil {
+		return h.cacheEmptyHandler.Close(ctx)
+	}
+	return nil
+}
+
+// cacheEmptyProxyName returns name of cache empty proxy configured for channel namespace.
+func (h *Handler) cacheEmptyProxyName(channel string) (string, bool) {
+	_, _, chOpts, found, err := h.cfgContainer.ChannelOptions(channel)
+	if err != nil || !found || !chOpts.CacheEmptyProxyEnabled {
+		return "", false
+	}
+	return chOpts.CacheEmptyProxyName, true
+}
+
 func (h *Handler) runConcurrentlyIfNeeded(ctx context.Context, concurrency int, semaphore chan struct{}, fn func()) {
 	if concurrency > 1 {
 		select {
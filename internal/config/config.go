@@ -149,6 +149,7 @@ func DefineFlags(rootCmd *cobra.Command) {
 	rootCmd.Flags().BoolP("http_stream.enabled", "", false, "enable bidirectional HTTP-streaming endpoint (with emulation layer)")
 	rootCmd.Flags().BoolP("init.enabled", "", false, "enable connection init endpoint")
 	rootCmd.Flags().BoolP("client.insecure", "", false, "start in insecure client mode")
+	rootCmd.Flags().BoolP("channel.validate_proxies", "", false, "call cache empty proxies on start and exit if any of them fails")
 	rootCmd.Flags().BoolP("http_api.insecure", "", false, "use insecure API mode")
 	rootCmd.Flags().BoolP("http_api.external", "", false, "expose API handler on external port")
 	rootCmd.Flags().BoolP("admin.insecure", "", false, "use insecure admin mode – no auth required for admin socket")
@@ -162,6 +163,8 @@ func GetConfig(cmd *cobra.Command, configFile string) (Config, Meta, error) {
 	v := viper.NewWithOptions(viper.WithDecodeHook(mapstructure.ComposeDecodeHookFunc(
 		mapstructure.StringToTimeDurationHookFunc(),
 		configtypes.StringToDurationHookFunc(),
+		configtypes.StringToByteSizeHookFunc(),
+		configtypes.StringToStringHookFunc(),
 		configtypes.StringToPEMDataHookFunc(),
 		configtypes.StringToMapStringStringHookFunc(),
 		configtypes.StringToStringKeyValuesHookFunc(),
@@ -175,7 +178,7 @@ func GetConfig(cmd *cobra.Command, configFile string) (Config, Meta, error) {
 			"admin.insecure", "client.insecure", "http_api.insecure", "http_api.external", "prometheus.enabled",
 			"health.enabled", "grpc_api.enabled", "grpc_api.port", "uni_grpc.enabled", "uni_grpc.port",
 			"uni_websocket.enabled", "uni_sse.enabled", "uni_http_stream.enabled", "sse.enabled", "http_stream.enabled",
-			"swagger.enabled", "dev.enabled", "init.enabled",
+			"swagger.enabled", "dev.enabled", "init.enabled", "channel.validate_proxies",
 		}
 		for _, flag := range bindPFlags {
 			_ = v.BindPFlag(flag, cmd.Flags().Lookup(flag))
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -69,6 +71,7 @@ func TestConfigEnvVars(t *testing.T) {
 	_ = os.Setenv("CENTRIFUGO_CHANNEL_NAMESPACES", `[{"name": "env"}]`)
 	_ = os.Setenv("CENTRIFUGO_CLIENT_PROXY_CONNECT_HTTP_STATIC_HEADERS", `{"key": "value"}`)
 	_ = os.Setenv("CENTRIFUGO_WEBSOCKET_WRITE_TIMEOUT", `300ms`)
+	_ = os.Setenv("CENTRIFUGO_SSE_MAX_REQUEST_BODY_SIZE", `1MiB`)
 	_ = os.Setenv("CENTRIFUGO_PROXIES", `[]`)
 	_ = os.Setenv("CENTRIFUGO_ENABLE_UNRELEASED_FEATURES", ``)   // Empty options should be treated as unset.
 	_ = os.Setenv("CENTRIFUGO_RPC_NAMESPACES", ``)               // Empty options should be treated as unset.
@@ -81,6 +84,7 @@ func TestConfigEnvVars(t *testing.T) {
 		_ = os.Unsetenv("CENTRIFUGO_CHANNEL_NAMESPACES")
 		_ = os.Unsetenv("CENTRIFUGO_CLIENT_PROXY_CONNECT_HTTP_STATIC_HEADERS")
 		_ = os.Unsetenv("CENTRIFUGO_WEBSOCKET_WRITE_TIMEOUT")
+		_ = os.Unsetenv("CENTRIFUGO_SSE_MAX_REQUEST_BODY_SIZE")
 		_ = os.Unsetenv("CENTRIFUGO_PROXIES")
 		_ = os.Unsetenv("CENTRIFUGO_ENABLE_UNRELEASED_FEATURES")
 		_ = os.Unsetenv("CENTRIFUGO_RPC_NAMESPACES")
@@ -98,6 +102,8 @@ func TestConfigEnvVars(t *testing.T) {
 	require.Contains(t, meta.UnknownEnvs, "CENTRIFUGO_UNKNOWN_ENV")
 	require.Equal(t, configtypes.MapStringString(map[string]string{"key": "value"}), conf.Client.Proxy.Connect.HTTP.StaticHeaders)
 	require.Equal(t, configtypes.Duration(300*time.Millisecond), conf.WebSocket.WriteTimeout)
+	require.Equal(t, int64(1<<20), conf.SSE.MaxRequestBodySize.Bytes())
+	require.Equal(t, int64(65536), conf.HTTPStream.MaxRequestBodySize.Bytes())
 	require.Equal(t, time.Second, time.Duration(conf.Client.Proxy.Connect.Timeout))
 	require.Len(t, conf.RPC.Namespaces, 0)
 	require.Len(t, conf.Proxies, 0)
@@ -162,3 +168,37 @@ func TestIsKubernetesEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigSecretsExpandEnv(t *testing.T) {
+	t.Setenv("CENTRIFUGO_TEST_SECRET", "s3cr3t")
+	t.Setenv("CENTRIFUGO_CLIENT_PROXY_REFRESH_ENDPOINT", "grpc://localhost:3000")
+	t.Setenv("CENTRIFUGO_CLIENT_PROXY_REFRESH_GRPC_CREDENTIALS_VALUE", "env$$value")
+
+	writeConfig := func(signingSecret string) string {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		data, err := json.Marshal(map[string]any{
+			"proxies": []map[string]any{{
+				"name":     "p",
+				"endpoint": "http://localhost:3000",
+				"http":     map[string]any{"signing_secret": signingSecret},
+			}},
+			"client": map[string]any{"proxy": map[string]any{"connect": map[string]any{
+				"endpoint": "grpc://localhost:3000",
+				"grpc":     map[string]any{"credentials_value": "abc$$def${CENTRIFUGO_TEST_SECRET}"},
+			}}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(configFile, data, 0600))
+		return configFile
+	}
+
+	conf, _ := getConfig(t, writeConfig("pa$$word"))
+	require.Equal(t, configtypes.String("pa$word"), conf.Proxies[0].HTTP.SigningSecret)
+	require.Equal(t, configtypes.String("abc$defs3cr3t"), conf.Client.Proxy.Connect.GRPC.CredentialsValue)
+	require.Equal(t, configtypes.String("env$value"), conf.Client.Proxy.Refresh.GRPC.CredentialsValue)
+
+	// Unescaped dollar sign referencing unset variable is an error, not an empty substitution.
+	_, _, err := GetConfig(nil, writeConfig("abc$def"))
+	require.ErrorContains(t, err, `proxies[0].http.signing_secret`)
+	require.ErrorContains(t, err, `environment variable "def" not found`)
+}
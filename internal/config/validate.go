@@ -6,12 +6,14 @@ import (
 	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
 	"github.com/centrifugal/centrifugo/v6/internal/tools"
 
 	"github.com/centrifugal/centrifuge"
+	"golang.org/x/net/http/httpguts"
 )
 
 var knownBrokers = []string{"memory", "nats", "redis", "redisnats"}
@@ -52,6 +54,12 @@ func (c Config) Validate() error {
 		return fmt.Errorf("unknown channel.publication_data_format: \"%s\"", c.Channel.PublicationDataFormat)
 	}
 
+	for i := 1; i < len(c.Prometheus.ProxyCallLatencyBuckets); i++ {
+		if c.Prometheus.ProxyCallLatencyBuckets[i] <= c.Prometheus.ProxyCallLatencyBuckets[i-1] {
+			return errors.New("prometheus.proxy_call_latency_buckets must be in increasing order")
+		}
+	}
+
 	if err := validateCodeToUniDisconnectTransforms(c.Client.ConnectCodeToUnidirectionalDisconnect.Transforms); err != nil {
 		return fmt.Errorf("in client.connect_code_to_unidirectional_disconnect: %v", err)
 	}
@@ -275,6 +283,15 @@ func validateChannelOptions(c configtypes.ChannelOptions, globalHistoryMetaTTL c
 		}
 	}
 
+	if c.CacheEmptyProxyName != "" && !slices.Contains(proxyNames, c.CacheEmptyProxyName) {
+		return fmt.Errorf("cache empty proxy with name \"%s\" not found", c.CacheEmptyProxyName)
+	}
+	if c.CacheEmptyProxyEnabled && c.CacheEmptyProxyName == DefaultProxyName {
+		if err := validateProxy("default", cfg.Channel.Proxy.CacheEmpty); err != nil {
+			return fmt.Errorf("in channel.proxy.cache_empty: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -305,12 +322,78 @@ func validateProxy(name string, p configtypes.Proxy) error {
 	if p.Timeout == 0 {
 		return errors.New("timeout not set")
 	}
-	if p.Endpoint == "" {
+	if p.ConnectTimeout < 0 {
+		return errors.New("negative connect_timeout")
+	}
+	if p.SlowCallThreshold < 0 {
+		return errors.New("negative slow_call_threshold")
+	}
+	if p.Endpoint == "" && len(p.GRPC.Endpoints) == 0 {
 		return errors.New("endpoint not set")
 	}
+	for i, e := range p.GRPC.Endpoints {
+		if e.Endpoint == "" {
+			return fmt.Errorf("endpoint not set in grpc.endpoints[%d]", i)
+		}
+		if strings.HasPrefix(string(e.Endpoint), "http://") || strings.HasPrefix(string(e.Endpoint), "https://") {
+			return fmt.Errorf("HTTP endpoint not allowed in grpc.endpoints[%d]", i)
+		}
+		if e.Weight < 0 {
+			return fmt.Errorf("negative weight in grpc.endpoints[%d]", i)
+		}
+	}
+	if !slices.Contains([]string{"", "none", "trace", "debug", "info", "warn", "error"}, strings.ToLower(p.LogLevel)) {
+		return fmt.Errorf("unknown log_level: %s", p.LogLevel)
+	}
+	if !slices.Contains([]string{"", configtypes.ChannelLabelModeFull, configtypes.ChannelLabelModeNamespace, configtypes.ChannelLabelModeHash, configtypes.ChannelLabelModeNone}, p.ChannelLabelMode) {
+		return fmt.Errorf("unknown channel_label_mode: %s", p.ChannelLabelMode)
+	}
+	if p.MaxRetries < 0 {
+		return errors.New("negative max_retries")
+	}
+	if p.MaxConcurrency < 0 {
+		return errors.New("negative max_concurrency")
+	}
+	if p.ProxyCommon.GRPC.BatchMaxSize < 0 {
+		return errors.New("negative grpc.batch_max_size")
+	}
+	if p.ProxyCommon.HTTP.MaxRedirects < 0 {
+		return errors.New("negative http.max_redirects")
+	}
 	if err := validateStatusTransforms(p.ProxyCommon.HTTP.StatusToCodeTransforms); err != nil {
 		return fmt.Errorf("in status_to_code_transforms: %v", err)
 	}
+	if err := validateGRPCStatusTransforms(p.ProxyCommon.GRPC.StatusToCodeTransforms); err != nil {
+		return fmt.Errorf("in grpc.status_to_code_transforms: %v", err)
+	}
+	if !slices.Contains([]string{"", configtypes.ProxyEncodingJSON, configtypes.ProxyEncodingProtobuf, configtypes.ProxyEncodingMsgpack}, p.ProxyCommon.HTTP.Encoding) {
+		return fmt.Errorf("unknown http encoding: %s", p.ProxyCommon.HTTP.Encoding)
+	}
+	if h := p.ProxyCommon.HTTP.TimeoutHeader; h != "" && !httpguts.ValidHeaderFieldName(h) {
+		return fmt.Errorf("invalid http.timeout_header: %q", h)
+	}
+	if err := validateProxySigning(p.ProxyCommon.HTTP); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateProxySigning(h configtypes.ProxyCommonHTTP) error {
+	if len(h.SigningKeys) == 0 {
+		if h.SigningKeyID != "" && h.SigningSecret == "" {
+			return errors.New("http.signing_key_id set without http.signing_secret or http.signing_keys")
+		}
+		return nil
+	}
+	if h.SigningSecret != "" {
+		return errors.New("can not use both http.signing_secret and http.signing_keys, configure only one of them")
+	}
+	if h.SigningKeyID == "" {
+		return errors.New("http.signing_key_id must be set to choose active key from http.signing_keys")
+	}
+	if h.SigningKeys[h.SigningKeyID] == "" {
+		return fmt.Errorf("http.signing_key_id %s not found in http.signing_keys", h.SigningKeyID)
+	}
 	return nil
 }
 
@@ -361,6 +444,26 @@ func validateStatusTransforms(transforms []configtypes.HttpStatusToCodeTransform
 	return nil
 }
 
+// maxGRPCStatusCode is the largest status code defined by GRPC (UNAUTHENTICATED).
+const maxGRPCStatusCode = 16
+
+func validateGRPCStatusTransforms(transforms []configtypes.GrpcStatusToCodeTransform) error {
+	statusTransforms := make([]configtypes.HttpStatusToCodeTransform, 0, len(transforms))
+	for i, transform := range transforms {
+		if transform.StatusCode > maxGRPCStatusCode {
+			return fmt.Errorf("unknown GRPC status_code %d in status_to_code_transforms[%d]", transform.StatusCode, i)
+		}
+		statusTransforms = append(statusTransforms, configtypes.HttpStatusToCodeTransform{
+			StatusCode:   int(transform.StatusCode),
+			ToError:      transform.ToError,
+			ToDisconnect: transform.ToDisconnect,
+		})
+	}
+	// Rules for error and disconnect are the same as for HTTP transforms, zero status code
+	// (OK) is not allowed too.
+	return validateStatusTransforms(statusTransforms)
+}
+
 func validateConnectCodeTransforms(transforms []configtypes.ConnectCodeToHTTPResponseTransform) error {
 	for i, transform := range transforms {
 		if transform.Code == 0 {
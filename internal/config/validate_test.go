@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
 
@@ -148,3 +149,167 @@ func TestValidateGlobalPublicationDataFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateProxyHTTPEncoding(t *testing.T) {
+	for _, encoding := range []string{"", configtypes.ProxyEncodingJSON, configtypes.ProxyEncodingProtobuf, configtypes.ProxyEncodingMsgpack} {
+		p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+		p.HTTP.Encoding = encoding
+		require.NoError(t, validateProxy("test", p), encoding)
+	}
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	p.HTTP.Encoding = "xml"
+	err := validateProxy("test", p)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown http encoding")
+}
+
+func TestValidateProxyLogLevel(t *testing.T) {
+	for _, level := range []string{"", "none", "debug", "WARN", "error"} {
+		p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+		p.LogLevel = level
+		require.NoError(t, validateProxy("test", p), level)
+	}
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	p.LogLevel = "verbose"
+	require.ErrorContains(t, validateProxy("test", p), "unknown log_level")
+}
+
+func TestValidateProxyChannelLabelMode(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	p.ChannelLabelMode = configtypes.ChannelLabelModeHash
+	require.NoError(t, validateProxy("test", p))
+	p.ChannelLabelMode = "partial"
+	require.ErrorContains(t, validateProxy("test", p), "unknown channel_label_mode")
+}
+
+func TestValidateProxyMaxRetries(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second), MaxRetries: 3}
+	require.NoError(t, validateProxy("test", p))
+	p.MaxRetries = -1
+	require.ErrorContains(t, validateProxy("test", p), "negative max_retries")
+}
+
+func TestValidateProxyMaxConcurrency(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second), MaxConcurrency: 10}
+	require.NoError(t, validateProxy("test", p))
+	p.MaxConcurrency = -1
+	require.ErrorContains(t, validateProxy("test", p), "negative max_concurrency")
+}
+
+func TestValidateProxyConnectTimeout(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second), ConnectTimeout: configtypes.Duration(100 * time.Millisecond)}
+	require.NoError(t, validateProxy("test", p))
+	p.ConnectTimeout = -1
+	require.ErrorContains(t, validateProxy("test", p), "negative connect_timeout")
+}
+
+func TestValidateProxySlowCallThreshold(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	p.SlowCallThreshold = configtypes.Duration(500 * time.Millisecond)
+	require.NoError(t, validateProxy("test", p))
+	p.SlowCallThreshold = -1
+	require.ErrorContains(t, validateProxy("test", p), "negative slow_call_threshold")
+}
+
+func TestValidateProxyMaxRedirects(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	p.HTTP.FollowRedirects = true
+	p.HTTP.MaxRedirects = 3
+	require.NoError(t, validateProxy("test", p))
+	p.HTTP.MaxRedirects = -1
+	require.ErrorContains(t, validateProxy("test", p), "negative http.max_redirects")
+}
+
+func TestValidateProxyTimeoutHeader(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	p.HTTP.TimeoutHeader = "X-Centrifugo-Deadline-Ms"
+	require.NoError(t, validateProxy("test", p))
+	p.HTTP.TimeoutHeader = "X Deadline"
+	require.ErrorContains(t, validateProxy("test", p), "invalid http.timeout_header")
+}
+
+func TestValidateProxySigning(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	p.HTTP.SigningSecret = "secret"
+	p.HTTP.SigningKeyID = "v1"
+	require.NoError(t, validateProxy("test", p))
+
+	p.HTTP.SigningKeys = configtypes.MapStringString{"v1": "old", "v2": "new"}
+	require.ErrorContains(t, validateProxy("test", p), "can not use both")
+
+	p.HTTP.SigningSecret = ""
+	p.HTTP.SigningKeyID = "v2"
+	require.NoError(t, validateProxy("test", p))
+
+	p.HTTP.SigningKeyID = "v3"
+	require.ErrorContains(t, validateProxy("test", p), "not found in http.signing_keys")
+
+	p.HTTP.SigningKeyID = ""
+	require.ErrorContains(t, validateProxy("test", p), "http.signing_key_id must be set")
+
+	p.HTTP.SigningKeys = nil
+	p.HTTP.SigningKeyID = "v1"
+	require.ErrorContains(t, validateProxy("test", p), "without http.signing_secret")
+}
+
+func TestValidateProxyGRPCStatusTransforms(t *testing.T) {
+	p := configtypes.Proxy{Endpoint: "localhost:10001", Timeout: configtypes.Duration(time.Second)}
+	p.GRPC.StatusToCodeTransforms = configtypes.GrpcStatusToCodeTransforms{
+		{StatusCode: 7, ToError: configtypes.TransformError{Code: 403, Message: "permission denied"}},
+	}
+	require.NoError(t, validateProxy("test", p))
+
+	p.GRPC.StatusToCodeTransforms[0].StatusCode = 17
+	require.ErrorContains(t, validateProxy("test", p), "unknown GRPC status_code 17")
+
+	p.GRPC.StatusToCodeTransforms[0].StatusCode = 0
+	require.ErrorContains(t, validateProxy("test", p), "status_code should be set")
+
+	p.GRPC.StatusToCodeTransforms[0].StatusCode = 7
+	p.GRPC.StatusToCodeTransforms[0].ToError.Code = 0
+	require.ErrorContains(t, validateProxy("test", p), "no error or disconnect code set")
+}
+
+func TestValidateProxyGRPCEndpoints(t *testing.T) {
+	p := configtypes.Proxy{Timeout: configtypes.Duration(time.Second)}
+	p.GRPC.Endpoints = configtypes.GrpcWeightedEndpoints{
+		{Endpoint: "localhost:10001", Weight: 2},
+		{Endpoint: "grpc://localhost:10002"},
+	}
+	require.NoError(t, validateProxy("test", p))
+
+	p.GRPC.Endpoints = configtypes.GrpcWeightedEndpoints{{Endpoint: "http://localhost:3000"}}
+	require.ErrorContains(t, validateProxy("test", p), "HTTP endpoint not allowed")
+
+	p.GRPC.Endpoints = configtypes.GrpcWeightedEndpoints{{Endpoint: "localhost:10001", Weight: -1}}
+	require.ErrorContains(t, validateProxy("test", p), "negative weight")
+
+	p.GRPC.Endpoints = configtypes.GrpcWeightedEndpoints{{Weight: 1}}
+	require.ErrorContains(t, validateProxy("test", p), "endpoint not set")
+
+	p.GRPC.Endpoints = configtypes.GrpcWeightedEndpoints{{Endpoint: "localhost:10001"}}
+	p.GRPC.BatchMaxSize = -1
+	require.ErrorContains(t, validateProxy("test", p), "negative grpc.batch_max_size")
+}
+
+func TestValidateCacheEmptyProxyName(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Channel.Namespaces = []configtypes.ChannelNamespace{
+		{
+			Name: "test",
+			ChannelOptions: configtypes.ChannelOptions{
+				CacheEmptyProxyEnabled: true,
+				CacheEmptyProxyName:    "missing",
+			},
+		},
+	}
+	require.ErrorContains(t, cfg.Validate(), `cache empty proxy with name "missing" not found`)
+
+	cfg.Proxies = configtypes.NamedProxies{
+		{Name: "missing", Proxy: configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Channel.Namespaces[0].CacheEmptyProxyName = DefaultProxyName
+	require.ErrorContains(t, cfg.Validate(), "in channel.proxy.cache_empty")
+}
@@ -12,6 +12,7 @@ func MakeVerifierConfig(tokenConf configtypes.Token) (jwtverify.VerifierConfig,
 	cfg := jwtverify.VerifierConfig{}
 
 	cfg.HMACSecretKey = tokenConf.HMACSecretKey
+	cfg.HMACAlgorithm = tokenConf.HMACAlg
 
 	rsaPublicKey := tokenConf.RSAPublicKey
 	if rsaPublicKey != "" {
@@ -31,11 +32,22 @@ func MakeVerifierConfig(tokenConf configtypes.Token) (jwtverify.VerifierConfig,
 		cfg.ECDSAPublicKey = pubKey
 	}
 
+	ed25519PublicKey := tokenConf.Ed25519PublicKey
+	if ed25519PublicKey != "" {
+		pubKey, err := jwtutils.ParseEd25519PublicKeyFromPEM([]byte(ed25519PublicKey))
+		if err != nil {
+			return jwtverify.VerifierConfig{}, fmt.Errorf("error parsing Ed25519 public key: %w", err)
+		}
+		cfg.Ed25519PublicKey = pubKey
+	}
+
 	cfg.JWKSPublicEndpoint = tokenConf.JWKSPublicEndpoint
+	cfg.JWKSCacheTTL = tokenConf.JWKSCacheTTL.ToDuration()
 	cfg.Audience = tokenConf.Audience
 	cfg.AudienceRegex = tokenConf.AudienceRegex
 	cfg.Issuer = tokenConf.Issuer
 	cfg.IssuerRegex = tokenConf.IssuerRegex
+	cfg.Leeway = tokenConf.Leeway.ToDuration()
 
 	if tokenConf.UserIDClaim != "" {
 		cfg.UserIDClaim = tokenConf.UserIDClaim
@@ -0,0 +1,134 @@
+package configtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// ByteSize is a number of bytes. It may be set as plain integer or as human-friendly
+// string with SI (KB, MB, GB, TB) or binary (KiB, MiB, GiB, TiB) suffix, e.g. "10MB"
+// or "512KiB". Suffixes are case-insensitive.
+type ByteSize int64
+
+// Bytes returns the number of bytes.
+func (b ByteSize) Bytes() int64 {
+	return int64(b)
+}
+
+func (b ByteSize) String() string {
+	return strconv.FormatInt(int64(b), 10)
+}
+
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// ParseByteSize parses byte size string like "1024", "10MB" or "512KiB".
+func ParseByteSize(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	i := 0
+	for i < len(str) && (str[i] == '.' || '0' <= str[i] && str[i] <= '9') {
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	value, unit := str[:i], strings.ToLower(strings.TrimSpace(str[i:]))
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", s, str[i:])
+	}
+	if !strings.Contains(value, ".") {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid byte size %q: %w", s, err)
+		}
+		if n > math.MaxInt64/multiplier {
+			return 0, fmt.Errorf("invalid byte size %q: overflow", s)
+		}
+		return ByteSize(n * multiplier), nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q: %w", s, err)
+	}
+	result := f * float64(multiplier)
+	if result >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte size %q: overflow", s)
+	}
+	return ByteSize(result), nil
+}
+
+// UnmarshalJSON supports both numeric values and strings with size suffix.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		parsed, err := ParseByteSize(str)
+		if err != nil {
+			return err
+		}
+		*b = parsed
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("invalid byte size %d: must not be negative", n)
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+// MarshalJSON encodes ByteSize as a plain number of bytes.
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(b))
+}
+
+// UnmarshalText parses string using ParseByteSize. Used by env vars decoding.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	parsed, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+func StringToByteSizeHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		if t != reflect.TypeOf(ByteSize(0)) {
+			return data, nil
+		}
+		return ParseByteSize(data.(string))
+	}
+}
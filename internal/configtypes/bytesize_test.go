@@ -0,0 +1,96 @@
+package configtypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{name: "plain integer", input: "1024", expected: 1024},
+		{name: "bytes suffix", input: "100B", expected: 100},
+		{name: "kilobytes", input: "10KB", expected: 10_000},
+		{name: "kibibytes", input: "512KiB", expected: 512 << 10},
+		{name: "megabytes", input: "10MB", expected: 10_000_000},
+		{name: "mebibytes", input: "10MiB", expected: 10 << 20},
+		{name: "gigabytes", input: "2GB", expected: 2_000_000_000},
+		{name: "gibibytes", input: "2GiB", expected: 2 << 30},
+		{name: "terabytes", input: "1TB", expected: 1_000_000_000_000},
+		{name: "tebibytes", input: "1TiB", expected: 1 << 40},
+		{name: "short suffix", input: "64k", expected: 64_000},
+		{name: "lower case", input: "10mb", expected: 10_000_000},
+		{name: "mixed case binary", input: "1mIb", expected: 1 << 20},
+		{name: "space before unit", input: "1 MiB", expected: 1 << 20},
+		{name: "fractional", input: "1.5KiB", expected: 1536},
+		{name: "zero", input: "0", expected: 0},
+		{name: "unknown unit", input: "10XB", wantErr: true},
+		{name: "negative", input: "-1MB", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+		{name: "unit only", input: "MB", wantErr: true},
+		{name: "overflow", input: "100000000TiB", wantErr: true},
+		{name: "invalid number", input: "1.2.3MB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseByteSize(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, b.Bytes())
+		})
+	}
+}
+
+func TestByteSize_JSON(t *testing.T) {
+	var b ByteSize
+	require.NoError(t, json.Unmarshal([]byte(`"10MiB"`), &b))
+	require.Equal(t, int64(10<<20), b.Bytes())
+
+	require.NoError(t, json.Unmarshal([]byte(`2048`), &b))
+	require.Equal(t, int64(2048), b.Bytes())
+
+	require.Error(t, json.Unmarshal([]byte(`-1`), &b))
+	require.Error(t, json.Unmarshal([]byte(`"10 apples"`), &b))
+
+	// Marshalling is stable and round-trips.
+	b = ByteSize(512 << 10)
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+	require.Equal(t, `524288`, string(data))
+	var decoded ByteSize
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, b, decoded)
+}
+
+func TestByteSize_UnmarshalText(t *testing.T) {
+	var b ByteSize
+	require.NoError(t, b.UnmarshalText([]byte("64KiB")))
+	require.Equal(t, int64(65536), b.Bytes())
+	require.Error(t, b.UnmarshalText([]byte("64 KiBs")))
+}
+
+func TestStringToByteSizeHookFunc(t *testing.T) {
+	hook := StringToByteSizeHookFunc().(func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error))
+	result, err := hook(reflect.TypeOf(""), reflect.TypeOf(ByteSize(0)), "1MB")
+	require.NoError(t, err)
+	require.Equal(t, ByteSize(1_000_000), result)
+
+	result, err = hook(reflect.TypeOf(1), reflect.TypeOf(ByteSize(0)), 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, result)
+}
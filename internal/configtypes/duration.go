@@ -2,7 +2,11 @@ package configtypes
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -20,7 +24,7 @@ func (d Duration) ToDuration() time.Duration {
 }
 
 // UnmarshalJSON interprets numeric values as nanoseconds (default behavior),
-// and strings using time.ParseDuration.
+// and strings using ParseDuration.
 func (d *Duration) UnmarshalJSON(b []byte) error {
 	// Check if it's a string (starts with a quote).
 	if len(b) > 0 && b[0] == '"' {
@@ -28,7 +32,7 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 		if err := json.Unmarshal(b, &str); err != nil {
 			return err
 		}
-		parsed, err := time.ParseDuration(str)
+		parsed, err := ParseDuration(str)
 		if err != nil {
 			return err
 		}
@@ -44,6 +48,16 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// UnmarshalText parses string using ParseDuration. Used by env vars and TOML decoding.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
 // MarshalJSON for JSON encoding.
 func (d Duration) MarshalJSON() ([]byte, error) {
 	durationStr := time.Duration(d).String()
@@ -75,6 +89,78 @@ func StringToDurationHookFunc() mapstructure.DecodeHookFunc {
 		}
 
 		// Convert it by parsing
-		return time.ParseDuration(data.(string))
+		return ParseDuration(data.(string))
+	}
+}
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// ParseDuration parses a duration string like time.ParseDuration does, but additionally
+// supports "d" (day, 24h) and "w" (week, 7d) units. Units may be combined, e.g. "1d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	orig := s
+	var neg bool
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if !strings.ContainsAny(s, "dw") {
+		return time.ParseDuration(orig)
+	}
+	var (
+		extended time.Duration
+		standard strings.Builder
+	)
+	for s != "" {
+		i := 0
+		for i < len(s) && (s[i] == '.' || '0' <= s[i] && s[i] <= '9') {
+			i++
+		}
+		if i == 0 {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && (s[j] < '0' || s[j] > '9') {
+			j++
+		}
+		value, unit := s[:i], s[i:j]
+		s = s[j:]
+		var multiplier time.Duration
+		switch unit {
+		case "d":
+			multiplier = day
+		case "w":
+			multiplier = week
+		default:
+			standard.WriteString(value)
+			standard.WriteString(unit)
+			continue
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		if f*float64(multiplier) > float64(math.MaxInt64-extended) {
+			return 0, fmt.Errorf("invalid duration %q: overflow", orig)
+		}
+		extended += time.Duration(f * float64(multiplier))
+	}
+	result := extended
+	if standard.Len() > 0 {
+		parsed, err := time.ParseDuration(standard.String())
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", orig, err)
+		}
+		if parsed > math.MaxInt64-result {
+			return 0, fmt.Errorf("invalid duration %q: overflow", orig)
+		}
+		result += parsed
+	}
+	if neg {
+		result = -result
 	}
+	return result, nil
 }
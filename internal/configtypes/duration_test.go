@@ -0,0 +1,101 @@
+package configtypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{name: "standard units", input: "1h30m", expected: 90 * time.Minute},
+		{name: "milliseconds", input: "300ms", expected: 300 * time.Millisecond},
+		{name: "days", input: "7d", expected: 7 * 24 * time.Hour},
+		{name: "weeks", input: "2w", expected: 14 * 24 * time.Hour},
+		{name: "days and hours", input: "1d12h", expected: 36 * time.Hour},
+		{name: "weeks days and minutes", input: "1w1d30m", expected: 8*24*time.Hour + 30*time.Minute},
+		{name: "fractional days", input: "1.5d", expected: 36 * time.Hour},
+		{name: "negative", input: "-1d", expected: -24 * time.Hour},
+		{name: "zero", input: "0", expected: 0},
+		{name: "years not supported", input: "1y", wantErr: true},
+		{name: "years with days", input: "1y2d", wantErr: true},
+		{name: "missing number", input: "d", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+		{name: "garbage", input: "1d-", wantErr: true},
+		{name: "overflow", input: "100000000w", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDuration(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, d)
+		})
+	}
+}
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	var d Duration
+	require.NoError(t, json.Unmarshal([]byte(`"2w"`), &d))
+	require.Equal(t, 14*24*time.Hour, d.ToDuration())
+
+	require.NoError(t, json.Unmarshal([]byte(`1000`), &d))
+	require.Equal(t, time.Microsecond, d.ToDuration())
+
+	require.Error(t, json.Unmarshal([]byte(`"1y"`), &d))
+}
+
+func TestDuration_UnmarshalText(t *testing.T) {
+	var d Duration
+	require.NoError(t, d.UnmarshalText([]byte("1d12h")))
+	require.Equal(t, 36*time.Hour, d.ToDuration())
+	require.Error(t, d.UnmarshalText([]byte("1y")))
+}
+
+func TestDuration_RoundTrip(t *testing.T) {
+	for _, input := range []string{"7d", "2w", "1d12h", "1.5s"} {
+		t.Run(input, func(t *testing.T) {
+			var d Duration
+			require.NoError(t, d.UnmarshalText([]byte(input)))
+
+			text, err := d.MarshalText()
+			require.NoError(t, err)
+			var fromText Duration
+			require.NoError(t, fromText.UnmarshalText(text))
+			require.Equal(t, d, fromText)
+
+			data, err := json.Marshal(d)
+			require.NoError(t, err)
+			var fromJSON Duration
+			require.NoError(t, json.Unmarshal(data, &fromJSON))
+			require.Equal(t, d, fromJSON)
+			// Marshalling is stable.
+			data2, err := json.Marshal(fromJSON)
+			require.NoError(t, err)
+			require.Equal(t, data, data2)
+		})
+	}
+}
+
+func TestStringToDurationHookFunc(t *testing.T) {
+	hook := StringToDurationHookFunc().(func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error))
+	result, err := hook(reflect.TypeOf(""), reflect.TypeOf(Duration(0)), "1w")
+	require.NoError(t, err)
+	require.Equal(t, 7*24*time.Hour, result)
+}
@@ -0,0 +1,130 @@
+package configtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// String is a string which expands environment variable references upon decoding.
+// Supported forms are $VAR, ${VAR} and ${VAR:-default} – default is used when VAR
+// is unset or empty. Referencing unset variable without default is an error. Literal
+// dollar sign may be escaped as $$.
+type String string
+
+// ExpandEnv expands environment variable references in s, see String for syntax.
+func ExpandEnv(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		next := s[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			expr := s[i+2 : i+2+end]
+			name, defaultValue, hasDefault := strings.Cut(expr, ":-")
+			if !isEnvVarName(name) {
+				return "", fmt.Errorf("invalid variable name %q", name)
+			}
+			value, ok := os.LookupEnv(name)
+			if hasDefault && value == "" {
+				value, ok = defaultValue, true
+			}
+			if !ok {
+				return "", fmt.Errorf("environment variable %q not found", name)
+			}
+			b.WriteString(value)
+			i += 2 + end
+		case isEnvVarNameStart(next):
+			j := i + 1
+			for j < len(s) && isEnvVarNameChar(s[j]) {
+				j++
+			}
+			name := s[i+1 : j]
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("environment variable %q not found", name)
+			}
+			b.WriteString(value)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+func isEnvVarNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isEnvVarNameChar(c byte) bool {
+	return isEnvVarNameStart(c) || '0' <= c && c <= '9'
+}
+
+func isEnvVarName(name string) bool {
+	if name == "" || !isEnvVarNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isEnvVarNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalText expands environment variables. Used by env vars decoding.
+func (s *String) UnmarshalText(text []byte) error {
+	expanded, err := ExpandEnv(string(text))
+	if err != nil {
+		return err
+	}
+	*s = String(expanded)
+	return nil
+}
+
+// UnmarshalJSON expands environment variables in JSON string.
+func (s *String) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return s.UnmarshalText([]byte(str))
+}
+
+func StringToStringHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		if t != reflect.TypeOf(String("")) {
+			return data, nil
+		}
+		expanded, err := ExpandEnv(data.(string))
+		if err != nil {
+			return nil, err
+		}
+		return String(expanded), nil
+	}
+}
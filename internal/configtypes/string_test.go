@@ -0,0 +1,85 @@
+package configtypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("CENTRIFUGO_TEST_HOST", "backend.local")
+	t.Setenv("CENTRIFUGO_TEST_PORT", "8080")
+	t.Setenv("CENTRIFUGO_TEST_EMPTY", "")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "no variables", input: "http://localhost:3000", expected: "http://localhost:3000"},
+		{name: "braced variable", input: "http://${CENTRIFUGO_TEST_HOST}/proxy", expected: "http://backend.local/proxy"},
+		{name: "plain variable", input: "http://$CENTRIFUGO_TEST_HOST:$CENTRIFUGO_TEST_PORT/", expected: "http://backend.local:8080/"},
+		{name: "default not used", input: "${CENTRIFUGO_TEST_HOST:-fallback}", expected: "backend.local"},
+		{name: "default for missing", input: "${CENTRIFUGO_TEST_MISSING:-fallback}", expected: "fallback"},
+		{name: "default for empty", input: "${CENTRIFUGO_TEST_EMPTY:-fallback}", expected: "fallback"},
+		{name: "empty default", input: "a${CENTRIFUGO_TEST_MISSING:-}b", expected: "ab"},
+		{name: "empty without default", input: "a${CENTRIFUGO_TEST_EMPTY}b", expected: "ab"},
+		{name: "escaped dollar", input: "pa$$word", expected: "pa$word"},
+		{name: "escaped dollar before name", input: "abc$$def", expected: "abc$def"},
+		{name: "escaped variable", input: "$${CENTRIFUGO_TEST_HOST}", expected: "${CENTRIFUGO_TEST_HOST}"},
+		{name: "lone dollar", input: "cost: 5$", expected: "cost: 5$"},
+		{name: "dollar before digit", input: "$1", expected: "$1"},
+		{name: "missing braced variable", input: "${CENTRIFUGO_TEST_MISSING}", wantErr: true},
+		{name: "missing plain variable", input: "$CENTRIFUGO_TEST_MISSING", wantErr: true},
+		{name: "unterminated", input: "${CENTRIFUGO_TEST_HOST", wantErr: true},
+		{name: "invalid name", input: "${1ABC}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExpandEnv(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestString_Unmarshal(t *testing.T) {
+	t.Setenv("CENTRIFUGO_TEST_SECRET", "s3cr3t")
+
+	var s String
+	require.NoError(t, s.UnmarshalText([]byte("${CENTRIFUGO_TEST_SECRET}")))
+	require.Equal(t, String("s3cr3t"), s)
+	require.Error(t, s.UnmarshalText([]byte("${CENTRIFUGO_TEST_MISSING}")))
+
+	var p Proxy
+	require.NoError(t, json.Unmarshal([]byte(`{"endpoint": "http://host/$CENTRIFUGO_TEST_SECRET"}`), &p))
+	require.Equal(t, String("http://host/s3cr3t"), p.Endpoint)
+}
+
+func TestStringToStringHookFunc(t *testing.T) {
+	t.Setenv("CENTRIFUGO_TEST_SECRET", "s3cr3t")
+	hook := StringToStringHookFunc().(func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error))
+	result, err := hook(reflect.TypeOf(""), reflect.TypeOf(String("")), "${CENTRIFUGO_TEST_SECRET}")
+	require.NoError(t, err)
+	require.Equal(t, String("s3cr3t"), result)
+
+	_, err = hook(reflect.TypeOf(""), reflect.TypeOf(String("")), "${CENTRIFUGO_TEST_MISSING}")
+	require.Error(t, err)
+
+	// Plain strings are not touched.
+	result, err = hook(reflect.TypeOf(""), reflect.TypeOf(""), "${CENTRIFUGO_TEST_SECRET}")
+	require.NoError(t, err)
+	require.Equal(t, "${CENTRIFUGO_TEST_SECRET}", result)
+}
@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"reflect"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPServer configuration.
@@ -51,15 +55,19 @@ type Log struct {
 
 // Token common configuration.
 type Token struct {
-	HMACSecretKey      string `mapstructure:"hmac_secret_key" json:"hmac_secret_key" envconfig:"hmac_secret_key" yaml:"hmac_secret_key" toml:"hmac_secret_key"`
-	RSAPublicKey       string `mapstructure:"rsa_public_key" json:"rsa_public_key" envconfig:"rsa_public_key" yaml:"rsa_public_key" toml:"rsa_public_key"`
-	ECDSAPublicKey     string `mapstructure:"ecdsa_public_key" json:"ecdsa_public_key" envconfig:"ecdsa_public_key" yaml:"ecdsa_public_key" toml:"ecdsa_public_key"`
-	JWKSPublicEndpoint string `mapstructure:"jwks_public_endpoint" json:"jwks_public_endpoint" envconfig:"jwks_public_endpoint" yaml:"jwks_public_endpoint" toml:"jwks_public_endpoint"`
-	Audience           string `mapstructure:"audience" json:"audience" envconfig:"audience" yaml:"audience" toml:"audience"`
-	AudienceRegex      string `mapstructure:"audience_regex" json:"audience_regex" envconfig:"audience_regex" yaml:"audience_regex" toml:"audience_regex"`
-	Issuer             string `mapstructure:"issuer" json:"issuer" envconfig:"issuer" yaml:"issuer" toml:"issuer"`
-	IssuerRegex        string `mapstructure:"issuer_regex" json:"issuer_regex" envconfig:"issuer_regex" yaml:"issuer_regex" toml:"issuer_regex"`
-	UserIDClaim        string `mapstructure:"user_id_claim" json:"user_id_claim" envconfig:"user_id_claim" yaml:"user_id_claim" toml:"user_id_claim"`
+	HMACSecretKey      string   `mapstructure:"hmac_secret_key" json:"hmac_secret_key" envconfig:"hmac_secret_key" yaml:"hmac_secret_key" toml:"hmac_secret_key"`
+	HMACAlg            string   `mapstructure:"hmac_alg" json:"hmac_alg" envconfig:"hmac_alg" yaml:"hmac_alg" toml:"hmac_alg"`
+	RSAPublicKey       string   `mapstructure:"rsa_public_key" json:"rsa_public_key" envconfig:"rsa_public_key" yaml:"rsa_public_key" toml:"rsa_public_key"`
+	ECDSAPublicKey     string   `mapstructure:"ecdsa_public_key" json:"ecdsa_public_key" envconfig:"ecdsa_public_key" yaml:"ecdsa_public_key" toml:"ecdsa_public_key"`
+	Ed25519PublicKey   string   `mapstructure:"ed25519_public_key" json:"ed25519_public_key" envconfig:"ed25519_public_key" yaml:"ed25519_public_key" toml:"ed25519_public_key"`
+	JWKSPublicEndpoint string   `mapstructure:"jwks_public_endpoint" json:"jwks_public_endpoint" envconfig:"jwks_public_endpoint" yaml:"jwks_public_endpoint" toml:"jwks_public_endpoint"`
+	JWKSCacheTTL       Duration `mapstructure:"jwks_cache_ttl" json:"jwks_cache_ttl" envconfig:"jwks_cache_ttl" yaml:"jwks_cache_ttl" toml:"jwks_cache_ttl"`
+	Audience           string   `mapstructure:"audience" json:"audience" envconfig:"audience" yaml:"audience" toml:"audience"`
+	AudienceRegex      string   `mapstructure:"audience_regex" json:"audience_regex" envconfig:"audience_regex" yaml:"audience_regex" toml:"audience_regex"`
+	Issuer             string   `mapstructure:"issuer" json:"issuer" envconfig:"issuer" yaml:"issuer" toml:"issuer"`
+	IssuerRegex        string   `mapstructure:"issuer_regex" json:"issuer_regex" envconfig:"issuer_regex" yaml:"issuer_regex" toml:"issuer_regex"`
+	UserIDClaim        string   `mapstructure:"user_id_claim" json:"user_id_claim" envconfig:"user_id_claim" yaml:"user_id_claim" toml:"user_id_claim"`
+	Leeway             Duration `mapstructure:"leeway" json:"leeway" envconfig:"leeway" yaml:"leeway" toml:"leeway"`
 }
 
 // SubscriptionToken can be used to set custom configuration for subscription tokens.
@@ -90,16 +98,16 @@ type WebSocket struct {
 
 // SSE client real-time transport configuration.
 type SSE struct {
-	Enabled            bool   `mapstructure:"enabled" json:"enabled" envconfig:"enabled" yaml:"enabled" toml:"enabled"`
-	HandlerPrefix      string `mapstructure:"handler_prefix" json:"handler_prefix" envconfig:"handler_prefix" default:"/connection/sse" yaml:"handler_prefix" toml:"handler_prefix"`
-	MaxRequestBodySize int    `mapstructure:"max_request_body_size" json:"max_request_body_size" envconfig:"max_request_body_size" default:"65536" yaml:"max_request_body_size" toml:"max_request_body_size"`
+	Enabled            bool     `mapstructure:"enabled" json:"enabled" envconfig:"enabled" yaml:"enabled" toml:"enabled"`
+	HandlerPrefix      string   `mapstructure:"handler_prefix" json:"handler_prefix" envconfig:"handler_prefix" default:"/connection/sse" yaml:"handler_prefix" toml:"handler_prefix"`
+	MaxRequestBodySize ByteSize `mapstructure:"max_request_body_size" json:"max_request_body_size" envconfig:"max_request_body_size" default:"65536" yaml:"max_request_body_size" toml:"max_request_body_size"`
 }
 
 // HTTPStream client real-time transport configuration.
 type HTTPStream struct {
-	Enabled            bool   `mapstructure:"enabled" json:"enabled" envconfig:"enabled" yaml:"enabled" toml:"enabled"`
-	HandlerPrefix      string `mapstructure:"handler_prefix" json:"handler_prefix" envconfig:"handler_prefix" default:"/connection/http_stream" yaml:"handler_prefix" toml:"handler_prefix"`
-	MaxRequestBodySize int    `mapstructure:"max_request_body_size" json:"max_request_body_size" envconfig:"max_request_body_size" default:"65536" yaml:"max_request_body_size" toml:"max_request_body_size"`
+	Enabled            bool     `mapstructure:"enabled" json:"enabled" envconfig:"enabled" yaml:"enabled" toml:"enabled"`
+	HandlerPrefix      string   `mapstructure:"handler_prefix" json:"handler_prefix" envconfig:"handler_prefix" default:"/connection/http_stream" yaml:"handler_prefix" toml:"handler_prefix"`
+	MaxRequestBodySize ByteSize `mapstructure:"max_request_body_size" json:"max_request_body_size" envconfig:"max_request_body_size" default:"65536" yaml:"max_request_body_size" toml:"max_request_body_size"`
 }
 
 // WebTransport client real-time transport configuration.
@@ -271,6 +279,7 @@ type OpenTelemetry struct {
 	Enabled   bool `mapstructure:"enabled" json:"enabled" envconfig:"enabled" yaml:"enabled" toml:"enabled"`
 	API       bool `mapstructure:"api" json:"api" envconfig:"api" yaml:"api" toml:"api"`
 	Consuming bool `mapstructure:"consuming" json:"consuming" envconfig:"consuming" yaml:"consuming" toml:"consuming"`
+	Proxy     bool `mapstructure:"proxy" json:"proxy" envconfig:"proxy" yaml:"proxy" toml:"proxy"`
 }
 
 type HttpAPI struct {
@@ -303,8 +312,8 @@ type Graphite struct {
 }
 
 type Emulation struct {
-	HandlerPrefix      string `mapstructure:"handler_prefix" json:"handler_prefix" envconfig:"handler_prefix" default:"/emulation" yaml:"handler_prefix" toml:"handler_prefix"`
-	MaxRequestBodySize int    `mapstructure:"max_request_body_size" json:"max_request_body_size" envconfig:"max_request_body_size" default:"65536" yaml:"max_request_body_size" toml:"max_request_body_size"`
+	HandlerPrefix      string   `mapstructure:"handler_prefix" json:"handler_prefix" envconfig:"handler_prefix" default:"/emulation" yaml:"handler_prefix" toml:"handler_prefix"`
+	MaxRequestBodySize ByteSize `mapstructure:"max_request_body_size" json:"max_request_body_size" envconfig:"max_request_body_size" default:"65536" yaml:"max_request_body_size" toml:"max_request_body_size"`
 }
 
 type UsageStats struct {
@@ -319,6 +328,9 @@ type Prometheus struct {
 	InstrumentHTTPHandlers bool `mapstructure:"instrument_http_handlers" json:"instrument_http_handlers" envconfig:"instrument_http_handlers" yaml:"instrument_http_handlers" toml:"instrument_http_handlers"`
 	// RecoveredPublicationsHistogram enables a histogram to track the distribution of recovered publications number.
 	RecoveredPublicationsHistogram bool `mapstructure:"recovered_publications_histogram" json:"recovered_publications_histogram" envconfig:"recovered_publications_histogram" yaml:"recovered_publications_histogram" toml:"recovered_publications_histogram"`
+	// ProxyCallLatencyBuckets allows overriding buckets (in seconds) of proxy call latency histogram.
+	// Buckets must be in increasing order. By default, buckets tuned for sub-second calls are used.
+	ProxyCallLatencyBuckets []float64 `mapstructure:"proxy_call_latency_buckets" json:"proxy_call_latency_buckets" envconfig:"proxy_call_latency_buckets" yaml:"proxy_call_latency_buckets" toml:"proxy_call_latency_buckets"`
 }
 
 type Health struct {
@@ -477,6 +489,10 @@ type ChannelProxyContainer struct {
 type Channel struct {
 	// Proxy configuration for channel-related events. All types inside can be referenced by the name "default".
 	Proxy ChannelProxyContainer `mapstructure:"proxy" json:"proxy" envconfig:"proxy" toml:"proxy" yaml:"proxy"`
+	// ValidateProxies makes Centrifugo call every cache empty proxy on start with a synthetic
+	// channel and exit if any call fails, so that misconfigured proxies are found before serving
+	// clients. Validation does not block start for longer than 5 seconds.
+	ValidateProxies bool `mapstructure:"validate_proxies" json:"validate_proxies" envconfig:"validate_proxies" toml:"validate_proxies" yaml:"validate_proxies"`
 
 	// WithoutNamespace is a configuration of channels options for channels which do not have namespace.
 	// Generally, we recommend always use channel namespaces but this option can be useful for simple setups.
@@ -620,6 +636,26 @@ func (d *HttpStatusToCodeTransforms) Decode(value string) error {
 	return nil
 }
 
+const (
+	// ProxyEncodingJSON makes HTTP proxy send and receive JSON payloads.
+	ProxyEncodingJSON = "json"
+	// ProxyEncodingProtobuf makes HTTP proxy send and receive Protobuf payloads.
+	ProxyEncodingProtobuf = "protobuf"
+	// ProxyEncodingMsgpack makes HTTP proxy send and receive MessagePack payloads.
+	ProxyEncodingMsgpack = "msgpack"
+)
+
+const (
+	// ChannelLabelModeFull makes proxy log channels as is.
+	ChannelLabelModeFull = "full"
+	// ChannelLabelModeNamespace makes proxy log only channel namespace.
+	ChannelLabelModeNamespace = "namespace"
+	// ChannelLabelModeHash makes proxy log short hash of channel instead of channel.
+	ChannelLabelModeHash = "hash"
+	// ChannelLabelModeNone makes proxy omit channel in logs.
+	ChannelLabelModeNone = "none"
+)
+
 type ProxyCommonHTTP struct {
 	// TLS for HTTP client.
 	TLS TLSConfig `mapstructure:"tls" json:"tls" envconfig:"tls" yaml:"tls" toml:"tls"`
@@ -629,6 +665,79 @@ type ProxyCommonHTTP struct {
 	StaticHeaders MapStringString `mapstructure:"static_headers" default:"{}" json:"static_headers" envconfig:"static_headers" yaml:"static_headers" toml:"static_headers"`
 	// StatusToCodeTransforms allow to map HTTP status codes from proxy to Disconnect or Error messages.
 	StatusToCodeTransforms HttpStatusToCodeTransforms `mapstructure:"status_to_code_transforms" default:"[]" json:"status_to_code_transforms" envconfig:"status_to_code_transforms" yaml:"status_to_code_transforms" toml:"status_to_code_transforms"`
+	// Encoding of proxy request and response payloads: "json" (default), "protobuf" or "msgpack".
+	// Protobuf uses messages from Centrifugo proxy.proto schema, MessagePack uses the same field
+	// names as JSON. With binary encodings byte fields are sent as is – without base64 even
+	// if BinaryEncoding is on. Currently supported by cache empty proxy only.
+	Encoding string `mapstructure:"encoding" json:"encoding" envconfig:"encoding" yaml:"encoding" toml:"encoding"`
+	// RequestTemplate is a Go text/template producing JSON request payload instead of default
+	// one – to adapt to existing backends expecting other shape. Template data is request with
+	// Channel, Client, User and Meta fields, json function encodes value as JSON, for example:
+	// {"event":"cache_empty","data":{"ch":{{json .Channel}}}}. Only supported with JSON encoding.
+	// Currently supported by cache empty proxy only.
+	RequestTemplate string `mapstructure:"request_template" json:"request_template" envconfig:"request_template" yaml:"request_template" toml:"request_template"`
+	// JSONIndent makes JSON encoded request payloads indented which may be useful for debugging.
+	// By default JSON is compact and has no trailing newline. Currently supported by cache empty
+	// proxy only.
+	JSONIndent bool `mapstructure:"json_indent" json:"json_indent" envconfig:"json_indent" yaml:"json_indent" toml:"json_indent"`
+	// CompressRequest enables gzip compression of request payloads larger than 1KB (request is
+	// then sent with Content-Encoding: gzip header). Currently supported by cache empty proxy only.
+	CompressRequest bool `mapstructure:"compress_request" json:"compress_request" envconfig:"compress_request" yaml:"compress_request" toml:"compress_request"`
+	// AcceptCompressedResponse makes proxy send Accept-Encoding: gzip header and decompress
+	// responses with Content-Encoding: gzip. Responses are not compressed if not set.
+	AcceptCompressedResponse bool `mapstructure:"accept_compressed_response" json:"accept_compressed_response" envconfig:"accept_compressed_response" yaml:"accept_compressed_response" toml:"accept_compressed_response"`
+	// UserAgent overrides default "Centrifugo/<version>" User-Agent header of proxy requests.
+	// Not applied when User-Agent is already set by static headers or proxied client headers.
+	UserAgent string `mapstructure:"user_agent" json:"user_agent" envconfig:"user_agent" yaml:"user_agent" toml:"user_agent"`
+	// TimeoutHeader overrides name of header with remaining time of proxy call in milliseconds
+	// (computed from call deadline), default is X-Centrifugo-Timeout-Ms. Header is not sent when
+	// call has no deadline.
+	TimeoutHeader string `mapstructure:"timeout_header" json:"timeout_header" envconfig:"timeout_header" yaml:"timeout_header" toml:"timeout_header"`
+	// LogPayloads enables debug level logging of proxy request and response payloads. Authorization,
+	// cookie and static header values are never logged. Binary encoded payloads are logged by size
+	// only. Currently supported by cache empty proxy only.
+	LogPayloads bool `mapstructure:"log_payloads" json:"log_payloads" envconfig:"log_payloads" yaml:"log_payloads" toml:"log_payloads"`
+	// RedactFields is a list of JSON payload field names which values are replaced with *** when
+	// logging payloads. Matched case-insensitively on any nesting level.
+	RedactFields []string `mapstructure:"redact_fields" json:"redact_fields" envconfig:"redact_fields" yaml:"redact_fields" toml:"redact_fields"`
+	// ForwardProxyURL routes HTTP proxy requests through an upstream forward proxy. Supported schemes
+	// are http://, https:// and socks5://. Proxy credentials may be set as URL user info. Environment
+	// variable references like ${VAR} are expanded.
+	ForwardProxyURL String `mapstructure:"forward_proxy_url" json:"forward_proxy_url" envconfig:"forward_proxy_url" yaml:"forward_proxy_url" toml:"forward_proxy_url"`
+	// ForwardProxyNoProxy is a list of hosts, domains (like .example.com), IPs or CIDRs to reach
+	// directly bypassing forward proxy – same syntax as NO_PROXY environment variable. Requests to
+	// localhost and loopback addresses are never proxied.
+	ForwardProxyNoProxy []string `mapstructure:"forward_proxy_no_proxy" json:"forward_proxy_no_proxy" envconfig:"forward_proxy_no_proxy" yaml:"forward_proxy_no_proxy" toml:"forward_proxy_no_proxy"`
+	// MaxResponseSize limits size of HTTP proxy response body, larger responses result into error
+	// instead of being buffered in memory. Zero value means 64MiB.
+	MaxResponseSize ByteSize `mapstructure:"max_response_size" json:"max_response_size" envconfig:"max_response_size" yaml:"max_response_size" toml:"max_response_size"`
+	// MaxForwardedHeaderBytes drops client headers (allowed by http_headers or grpc_metadata)
+	// which name and values together are larger than the limit – like huge cookies – instead of
+	// forwarding them to proxy. Static headers are never dropped unless overridden by a client
+	// value. Zero means no limit.
+	MaxForwardedHeaderBytes ByteSize `mapstructure:"max_forwarded_header_bytes" json:"max_forwarded_header_bytes" envconfig:"max_forwarded_header_bytes" yaml:"max_forwarded_header_bytes" toml:"max_forwarded_header_bytes"`
+	// ValidateContentType makes proxy check that Content-Type of successful responses matches
+	// Encoding (application/json by default) – so that HTML error page returned with 200 status
+	// results into clear error with the beginning of response body instead of decoding error.
+	// Currently supported by cache empty proxy only.
+	ValidateContentType bool `mapstructure:"validate_content_type" json:"validate_content_type" envconfig:"validate_content_type" yaml:"validate_content_type" toml:"validate_content_type"`
+	// FollowRedirects makes proxy follow redirects returned by endpoint. By default redirects are
+	// not followed and result into error with redirect target – so that request body and headers
+	// are not sent to unexpected host. Signed requests are signed again for every redirect.
+	FollowRedirects bool `mapstructure:"follow_redirects" json:"follow_redirects" envconfig:"follow_redirects" yaml:"follow_redirects" toml:"follow_redirects"`
+	// MaxRedirects limits number of redirects followed with FollowRedirects. Zero means 10.
+	MaxRedirects int `mapstructure:"max_redirects" json:"max_redirects" envconfig:"max_redirects" yaml:"max_redirects" toml:"max_redirects"`
+	// SigningSecret enables signing of proxy requests: hex encoded HMAC-SHA256 of request body (as
+	// sent, i.e. after compression) is passed in X-Centrifugo-Signature header. Environment
+	// variable references like ${VAR} are expanded, unset variable is a configuration error –
+	// literal dollar sign must be escaped as $$. Currently supported by cache empty proxy only.
+	SigningSecret String `mapstructure:"signing_secret" json:"signing_secret" envconfig:"signing_secret" yaml:"signing_secret" toml:"signing_secret"`
+	// SigningKeyID is sent in X-Centrifugo-Key-Id header together with signature, so backend can
+	// choose verification key. When SigningKeys is set it selects the active key from there.
+	SigningKeyID string `mapstructure:"signing_key_id" json:"signing_key_id" envconfig:"signing_key_id" yaml:"signing_key_id" toml:"signing_key_id"`
+	// SigningKeys maps key IDs to signing secrets, used instead of SigningSecret. Keys may be
+	// rotated without downtime: add new key, let backend accept it, then switch SigningKeyID.
+	SigningKeys MapStringString `mapstructure:"signing_keys" json:"signing_keys" envconfig:"signing_keys" yaml:"signing_keys" toml:"signing_keys"`
 }
 
 type ProxyCommonGRPC struct {
@@ -636,28 +745,113 @@ type ProxyCommonGRPC struct {
 	TLS TLSConfig `mapstructure:"tls" json:"tls" envconfig:"tls" yaml:"tls" toml:"tls"`
 	// CredentialsKey is a custom key to add into per-RPC credentials.
 	CredentialsKey string `mapstructure:"credentials_key" json:"credentials_key" envconfig:"credentials_key" yaml:"credentials_key" toml:"credentials_key"`
-	// CredentialsValue is a custom value for GrpcCredentialsKey.
-	CredentialsValue string `mapstructure:"credentials_value" json:"credentials_value" envconfig:"credentials_value" yaml:"credentials_value" toml:"credentials_value"`
+	// CredentialsValue is a custom value for GrpcCredentialsKey. Environment variable
+	// references like ${VAR} are expanded, unset variable is a configuration error – literal
+	// dollar sign must be escaped as $$.
+	CredentialsValue String `mapstructure:"credentials_value" json:"credentials_value" envconfig:"credentials_value" yaml:"credentials_value" toml:"credentials_value"`
 	// Compression enables compression for outgoing calls (gzip).
 	Compression bool `mapstructure:"compression" json:"compression" envconfig:"compression" yaml:"compression" toml:"compression"`
 	// StaticMetadata is a static set of key/value pairs to attach to GRPC proxy request as
 	// metadata. Headers received from HTTP client request or metadata from GRPC client request
 	// both have priority over values set in StaticMetadata map (but only if explicitly allowed).
 	StaticMetadata MapStringString `mapstructure:"static_metadata" default:"{}" json:"static_metadata" envconfig:"static_metadata" yaml:"static_metadata" toml:"static_metadata"`
+	// Endpoints is a list of equivalent GRPC endpoints with weights. When set, proxy calls are
+	// distributed between endpoints using weighted round-robin and Endpoint of proxy is ignored.
+	// Currently supported by cache empty proxy only.
+	Endpoints GrpcWeightedEndpoints `mapstructure:"endpoints" default:"[]" json:"endpoints" envconfig:"endpoints" yaml:"endpoints" toml:"endpoints"`
+	// DNSRefreshInterval enables periodic re-resolution of endpoint names (dns:/// scheme is used
+	// by default) and balancing over all resolved addresses with round_robin policy. Useful for
+	// headless services where addresses change upon rollout. GRPC DNS resolver does not resolve
+	// more often than every 30 seconds, so smaller intervals are rejected for dns targets.
+	DNSRefreshInterval Duration `mapstructure:"dns_refresh_interval" json:"dns_refresh_interval" envconfig:"dns_refresh_interval" yaml:"dns_refresh_interval" toml:"dns_refresh_interval"`
+	// BatchWindow enables batching – channels observed within the window are sent to the backend in
+	// one NotifyCacheEmptyBatch call of CentrifugoProxyBatch service. If backend does not implement
+	// it then Centrifugo falls back to per-channel calls. Currently supported by cache empty proxy only.
+	BatchWindow Duration `mapstructure:"batch_window" json:"batch_window" envconfig:"batch_window" yaml:"batch_window" toml:"batch_window"`
+	// BatchMaxSize is a maximum number of channels in one batch, batch is sent immediately when
+	// reaching it. Zero means 100.
+	BatchMaxSize int `mapstructure:"batch_max_size" json:"batch_max_size" envconfig:"batch_max_size" yaml:"batch_max_size" toml:"batch_max_size"`
+	// EagerConnect makes Centrifugo connect to backend upon proxy creation and wait until connection
+	// is READY – so that first proxy calls after start do not fail while connection is established.
+	// Proxy creation fails if connection is not ready within EagerConnectTimeout. Currently supported
+	// by cache empty proxy only.
+	EagerConnect bool `mapstructure:"eager_connect" json:"eager_connect" envconfig:"eager_connect" yaml:"eager_connect" toml:"eager_connect"`
+	// EagerConnectTimeout bounds waiting for connection readiness when EagerConnect is on. Zero
+	// means proxy timeout.
+	EagerConnectTimeout Duration `mapstructure:"eager_connect_timeout" json:"eager_connect_timeout" envconfig:"eager_connect_timeout" yaml:"eager_connect_timeout" toml:"eager_connect_timeout"`
+	// Streaming makes cache empty proxy call server-streaming NotifyCacheEmptyStream instead of
+	// NotifyCacheEmpty. Readers are unblocked as soon as backend streams populated result, stream is
+	// closed by Centrifugo then. Proxy timeout applies to the whole stream.
+	Streaming bool `mapstructure:"streaming" json:"streaming" envconfig:"streaming" yaml:"streaming" toml:"streaming"`
+	// StatusToCodeTransforms allow to map GRPC status codes from proxy to Disconnect or Error messages,
+	// like HTTP StatusToCodeTransforms do for HTTP status codes. Currently supported by cache empty
+	// proxy only.
+	StatusToCodeTransforms GrpcStatusToCodeTransforms `mapstructure:"status_to_code_transforms" default:"[]" json:"status_to_code_transforms" envconfig:"status_to_code_transforms" yaml:"status_to_code_transforms" toml:"status_to_code_transforms"`
+}
+
+// GrpcWeightedEndpoint is a GRPC endpoint used for client-side load balancing.
+type GrpcWeightedEndpoint struct {
+	// Endpoint - GRPC service endpoint. Environment variable references like ${VAR} are expanded.
+	Endpoint String `mapstructure:"endpoint" json:"endpoint" envconfig:"endpoint" yaml:"endpoint" toml:"endpoint"`
+	// Weight of endpoint relative to other endpoints. Zero value means 1.
+	Weight int `mapstructure:"weight" json:"weight" envconfig:"weight" yaml:"weight" toml:"weight"`
+}
+
+type GrpcWeightedEndpoints []GrpcWeightedEndpoint
+
+// Decode to implement the envconfig.Decoder interface
+func (d *GrpcWeightedEndpoints) Decode(value string) error {
+	// If the source is a string and the target is a slice, try to parse it as JSON.
+	var items GrpcWeightedEndpoints
+	err := json.Unmarshal([]byte(value), &items)
+	if err != nil {
+		return fmt.Errorf("error parsing items from JSON: %v", err)
+	}
+	*d = items
+	return nil
+}
+
+type GrpcStatusToCodeTransform struct {
+	// StatusCode is a GRPC status code number to transform, like 7 for PERMISSION_DENIED.
+	StatusCode uint32 `mapstructure:"status_code" json:"status_code" envconfig:"status_code" yaml:"status_code" toml:"status_code"`
+	// ToError is a transform to protocol error.
+	ToError TransformError `mapstructure:"to_error" json:"to_error" envconfig:"to_error" yaml:"to_error" toml:"to_error"`
+	// ToDisconnect is a transform to protocol disconnect.
+	ToDisconnect TransformDisconnect `mapstructure:"to_disconnect" json:"to_disconnect" envconfig:"to_disconnect" yaml:"to_disconnect" toml:"to_disconnect"`
+}
+
+type GrpcStatusToCodeTransforms []GrpcStatusToCodeTransform
+
+// Decode to implement the envconfig.Decoder interface
+func (d *GrpcStatusToCodeTransforms) Decode(value string) error {
+	// If the source is a string and the target is a slice, try to parse it as JSON.
+	var items GrpcStatusToCodeTransforms
+	err := json.Unmarshal([]byte(value), &items)
+	if err != nil {
+		return fmt.Errorf("error parsing items from JSON: %v", err)
+	}
+	*d = items
+	return nil
 }
 
 type ProxyCommon struct {
 	// HttpHeaders is a list of HTTP headers to proxy. No headers used by proxy by default.
-	// If GRPC proxy is used then request HTTP headers set to outgoing request metadata.
+	// If GRPC proxy is used then request HTTP headers set to outgoing request metadata. With
+	// BinaryEncoding on, header values which are not printable ASCII are sent under metadata key
+	// with -bin suffix (like x-channel-bin instead of x-channel), otherwise GRPC rejects them.
 	HttpHeaders []string `mapstructure:"http_headers" json:"http_headers" envconfig:"http_headers" yaml:"http_headers" toml:"http_headers"`
 	// GrpcMetadata is a list of GRPC metadata keys to proxy. No meta keys used by proxy by
 	// default. If HTTP proxy is used then these keys become outgoing request HTTP headers.
 	GrpcMetadata []string `mapstructure:"grpc_metadata" json:"grpc_metadata" envconfig:"grpc_metadata" yaml:"grpc_metadata" toml:"grpc_metadata"`
 	// BinaryEncoding makes proxy send data as base64 string (assuming it contains custom
-	// non-JSON payload).
+	// non-JSON payload). For GRPC proxy, metadata values which are not printable ASCII are
+	// also moved to -bin suffixed keys, see HttpHeaders.
 	BinaryEncoding bool `mapstructure:"binary_encoding" json:"binary_encoding" envconfig:"binary_encoding" yaml:"binary_encoding" toml:"binary_encoding"`
 	// IncludeConnectionMeta to each proxy request (except connect proxy where it's obtained).
 	IncludeConnectionMeta bool `mapstructure:"include_connection_meta" json:"include_connection_meta" envconfig:"include_connection_meta" yaml:"include_connection_meta" toml:"include_connection_meta"`
+	// TraceChannel adds channel attribute to OpenTelemetry proxy spans. Channels usually have
+	// high cardinality so attribute is omitted by default.
+	TraceChannel bool `mapstructure:"trace_channel" json:"trace_channel" envconfig:"trace_channel" yaml:"trace_channel" toml:"trace_channel"`
 
 	HTTP ProxyCommonHTTP `mapstructure:"http" json:"http" envconfig:"http" yaml:"http" toml:"http"`
 	GRPC ProxyCommonGRPC `mapstructure:"grpc" json:"grpc" envconfig:"grpc" yaml:"grpc" toml:"grpc"`
@@ -665,14 +859,64 @@ type ProxyCommon struct {
 
 // Proxy configuration.
 type Proxy struct {
-	// Endpoint - HTTP address or GRPC service endpoint.
-	Endpoint string `mapstructure:"endpoint" json:"endpoint" envconfig:"endpoint" yaml:"endpoint" toml:"endpoint"`
-	// Timeout for proxy request.
+	// Endpoint - HTTP address or GRPC service endpoint. Environment variable references
+	// like ${VAR} are expanded. HTTP endpoint of cache empty proxy may contain {channel}
+	// placeholder substituted with URL-escaped channel.
+	Endpoint String `mapstructure:"endpoint" json:"endpoint" envconfig:"endpoint" yaml:"endpoint" toml:"endpoint"`
+	// Timeout for proxy request. Proxies created from configuration with zero Timeout use
+	// Defaults.Timeout of proxy package.
 	Timeout Duration `mapstructure:"timeout" default:"1s" json:"timeout" envconfig:"timeout" yaml:"timeout" toml:"timeout"`
+	// ConnectTimeout bounds establishing connection to backend (including TLS handshake for HTTP
+	// proxy), while Timeout still bounds the entire call. Zero means half of Timeout.
+	ConnectTimeout Duration `mapstructure:"connect_timeout" json:"connect_timeout" envconfig:"connect_timeout" yaml:"connect_timeout" toml:"connect_timeout"`
+	// SlowCallThreshold makes proxy log a warning for every call attempt which took longer, so
+	// that latency regressions are noticed. Zero disables slow call logging. Currently supported
+	// by cache empty proxy only.
+	SlowCallThreshold Duration `mapstructure:"slow_call_threshold" json:"slow_call_threshold" envconfig:"slow_call_threshold" yaml:"slow_call_threshold" toml:"slow_call_threshold"`
+	// Warmup makes Centrifugo establish connection to backend on start, so that the first call
+	// does not pay connection setup latency: GRPC proxy waits for READY connection, HTTP proxy
+	// sends HEAD request to endpoint host. Warmup does not block start for longer than 5 seconds,
+	// failures are only logged. Currently supported by cache empty proxy only.
+	Warmup bool `mapstructure:"warmup" json:"warmup" envconfig:"warmup" yaml:"warmup" toml:"warmup"`
+	// LogLevel for messages related to this proxy. Supported values: `none`, `trace`, `debug`,
+	// `info`, `warn`, `error`. By default, global log level is used. Messages below global log
+	// level are not logged even if proxy log level allows them.
+	LogLevel string `mapstructure:"log_level" json:"log_level" envconfig:"log_level" yaml:"log_level" toml:"log_level"`
+	// ChannelLabelMode defines how channel appears in proxy logs to keep cardinality of log fields
+	// bounded: "full", "namespace" (default), "hash" or "none".
+	ChannelLabelMode string `mapstructure:"channel_label_mode" json:"channel_label_mode" envconfig:"channel_label_mode" yaml:"channel_label_mode" toml:"channel_label_mode"`
+	// MaxRetries of failed proxy call. Calls are retried on network errors, timeouts, 5xx and 429
+	// HTTP statuses, UNAVAILABLE, DEADLINE_EXCEEDED, RESOURCE_EXHAUSTED and ABORTED GRPC codes.
+	// Timeout applies to every attempt. Zero means no retries. Currently supported by cache empty
+	// proxy only.
+	MaxRetries int `mapstructure:"max_retries" json:"max_retries" envconfig:"max_retries" yaml:"max_retries" toml:"max_retries"`
+	// RetryBackoff is a delay before the first retry, doubled for every next one up to RetryMaxDelay.
+	// Zero means 100ms.
+	RetryBackoff Duration `mapstructure:"retry_backoff" json:"retry_backoff" envconfig:"retry_backoff" yaml:"retry_backoff" toml:"retry_backoff"`
+	// RetryMaxDelay limits delay between retries, including delay requested by HTTP backend with
+	// Retry-After header of 429 and 503 responses. Zero means 5s.
+	RetryMaxDelay Duration `mapstructure:"retry_max_delay" json:"retry_max_delay" envconfig:"retry_max_delay" yaml:"retry_max_delay" toml:"retry_max_delay"`
+	// RetryBudget limits total time of proxy call including all retry attempts and delays between
+	// them. When exhausted the last error is returned. Zero means no limit.
+	RetryBudget Duration `mapstructure:"retry_budget" json:"retry_budget" envconfig:"retry_budget" yaml:"retry_budget" toml:"retry_budget"`
+	// MaxConcurrency limits the number of concurrent calls to proxy including retries, calls
+	// over limit wait for a free slot until their context is done. Zero means no limit.
+	// Currently supported by cache empty proxy only.
+	MaxConcurrency int `mapstructure:"max_concurrency" json:"max_concurrency" envconfig:"max_concurrency" yaml:"max_concurrency" toml:"max_concurrency"`
 
 	ProxyCommon `mapstructure:",squash" yaml:",inline"`
 
 	TestGrpcDialer func(context.Context, string) (net.Conn, error) `json:"-" yaml:"-" toml:"-" envconfig:"-"`
+	// TracerProvider enables OpenTelemetry spans for proxy calls when set.
+	TracerProvider trace.TracerProvider `json:"-" yaml:"-" toml:"-" envconfig:"-"`
+	// HTTPRoundTripperFactory when set wraps transport of HTTP proxy client – for request recording,
+	// fault injection and so on. Factories may be layered by calling previous one inside.
+	HTTPRoundTripperFactory func(base http.RoundTripper) http.RoundTripper `json:"-" yaml:"-" toml:"-" envconfig:"-"`
+	// GRPCCredentialsProvider when set returns value for GRPC.CredentialsKey per-RPC credentials
+	// (authorization by default) instead of static GRPC.CredentialsValue – for short-lived tokens.
+	// Provider also returns expiration time of value, value is cached and refreshed shortly before
+	// it expires. Zero expiration time means value does not expire.
+	GRPCCredentialsProvider func(ctx context.Context) (string, time.Time, error) `json:"-" yaml:"-" toml:"-" envconfig:"-"`
 }
 
 const (
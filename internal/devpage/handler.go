@@ -1,6 +1,7 @@
 package devpage
 
 import (
+	"cmp"
 	"embed"
 	"encoding/json"
 	"net/http"
@@ -120,7 +121,7 @@ func (h *Handler) signToken(claims interface{}) (string, error) {
 		return "", jwt.ErrInvalidKey
 	}
 
-	signer, err := jwt.NewSignerHS(jwt.HS256, []byte(h.config.Client.Token.HMACSecretKey))
+	signer, err := jwt.NewSignerHS(jwt.Algorithm(cmp.Or(h.config.Client.Token.HMACAlg, string(jwt.HS256))), []byte(h.config.Client.Token.HMACSecretKey))
 	if err != nil {
 		return "", err
 	}
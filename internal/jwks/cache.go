@@ -2,16 +2,75 @@ package jwks
 
 import (
 	"errors"
+	"sync"
+	"time"
 )
 
 var (
 	// ErrCacheNotFound returned when cache value not found.
+	//
+	// Deprecated: not returned by Manager anymore, kept together with Cache for compatibility.
 	ErrCacheNotFound = errors.New("cache: value not found")
 )
 
 // Cache works with cache layer.
+//
+// Deprecated: Manager caches key sets per JWKS URL itself, see WithCache.
 type Cache interface {
 	Add(key *JWK) error
 	Get(kid string) (*JWK, error)
 	Len() (int, error)
 }
+
+// keySet is a set of signing keys fetched from one JWKS URL.
+type keySet struct {
+	keys      map[string]*JWK
+	expiresAt time.Time
+}
+
+func (s *keySet) fresh(now time.Time) bool {
+	return now.Before(s.expiresAt)
+}
+
+// keySetCache keeps fetched key sets keyed by JWKS URL. Expired sets are not removed
+// right away – they are kept during staleTTL to be used as a fallback when JWKS
+// endpoint is temporary unavailable.
+type keySetCache struct {
+	mu       sync.RWMutex
+	staleTTL time.Duration
+	sets     map[string]*keySet
+}
+
+func newKeySetCache(staleTTL time.Duration) *keySetCache {
+	return &keySetCache{
+		staleTTL: staleTTL,
+		sets:     make(map[string]*keySet),
+	}
+}
+
+// get returns cached key set for URL, possibly expired.
+func (c *keySetCache) get(url string) (*keySet, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s, ok := c.sets[url]
+	return s, ok
+}
+
+func (c *keySetCache) set(url string, keys map[string]*JWK, ttl time.Duration) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for u, s := range c.sets {
+		if now.After(s.expiresAt.Add(c.staleTTL)) {
+			delete(c.sets, u)
+		}
+	}
+	c.sets[url] = &keySet{keys: keys, expiresAt: now.Add(ttl)}
+}
+
+// Len returns number of cached key sets.
+func (c *keySetCache) Len() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.sets), nil
+}
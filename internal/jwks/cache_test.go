@@ -0,0 +1,124 @@
+package jwks
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKeySet(n int) map[string]*JWK {
+	keys := make(map[string]*JWK, n)
+	for i := 0; i < n; i++ {
+		kid := fmt.Sprintf("key-%d", i+1)
+		keys[kid] = &JWK{
+			Kid: kid,
+			Kty: "RSA",
+			Alg: "RS256",
+			Use: "sig",
+		}
+	}
+	return keys
+}
+
+func TestKeySetCacheInit(t *testing.T) {
+	cache := newKeySetCache(5 * time.Minute)
+	require.NotNil(t, cache)
+	n, err := cache.Len()
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
+func TestKeySetCacheSet(t *testing.T) {
+	cache := newKeySetCache(5 * time.Minute)
+
+	for i := 0; i < 100; i++ {
+		cache.set(fmt.Sprintf("https://example.com/%d", i), testKeySet(2), 5*time.Second)
+	}
+	n, err := cache.Len()
+	require.NoError(t, err)
+	require.Equal(t, 100, n)
+
+	// Setting same URL again replaces key set.
+	cache.set("https://example.com/0", testKeySet(3), 5*time.Second)
+	n, err = cache.Len()
+	require.NoError(t, err)
+	require.Equal(t, 100, n)
+	set, ok := cache.get("https://example.com/0")
+	require.True(t, ok)
+	require.Len(t, set.keys, 3)
+}
+
+func TestKeySetCacheGet(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		URL   string
+		Kid   string
+		Found bool
+	}{
+		{
+			Name:  "OK",
+			URL:   "https://example.com",
+			Kid:   "key-1",
+			Found: true,
+		},
+		{
+			Name: "KeyNotFound",
+			URL:  "https://example.com",
+			Kid:  "key-2",
+		},
+		{
+			Name: "URLNotFound",
+			URL:  "https://example.com/other",
+			Kid:  "key-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cache := newKeySetCache(5 * time.Minute)
+			keys := testKeySet(1)
+			cache.set("https://example.com", keys, 5*time.Minute)
+
+			set, ok := cache.get(tc.URL)
+			if !ok {
+				require.False(t, tc.Found)
+				return
+			}
+			require.True(t, set.fresh(time.Now()))
+			key, ok := set.keys[tc.Kid]
+			require.Equal(t, tc.Found, ok)
+			if tc.Found {
+				require.EqualValues(t, keys[tc.Kid], key)
+			}
+		})
+	}
+}
+
+func TestKeySetCacheExpired(t *testing.T) {
+	cache := newKeySetCache(5 * time.Minute)
+	cache.set("https://example.com", testKeySet(1), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// Expired key set is still returned to be used as a stale fallback.
+	set, ok := cache.get("https://example.com")
+	require.True(t, ok)
+	require.False(t, set.fresh(time.Now()))
+}
+
+func TestKeySetCacheCleanup(t *testing.T) {
+	cache := newKeySetCache(time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		cache.set(fmt.Sprintf("https://example.com/%d", i), testKeySet(1), time.Millisecond)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	// Sets expired beyond stale TTL are removed upon next set.
+	cache.set("https://example.com/new", testKeySet(1), time.Minute)
+	n, err := cache.Len()
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
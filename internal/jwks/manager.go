@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rakutentech/jwk-go/jwk"
@@ -20,6 +22,9 @@ const (
 	_defaultTimeout            = 1 * time.Second
 	_defaultMaxIdleConnPerHost = 255
 	_defaultTTL                = 1 * time.Hour
+	_defaultMaxTTL             = 24 * time.Hour
+	_minMaxAgeTTL              = 5 * time.Second
+	_defaultStaleTTL           = 24 * time.Hour
 )
 
 // JWK represents an unparsed JSON Web Key (JWK) in its wire format.
@@ -43,9 +48,11 @@ var (
 // Manager fetches and returns JWK from public source.
 type Manager struct {
 	url      *fasttemplate.Template
-	cache    Cache
+	cache    *keySetCache
 	client   *http.Client
 	useCache bool
+	ttl      time.Duration
+	maxTTL   time.Duration
 	retries  uint
 	group    singleflight.Group
 }
@@ -73,9 +80,11 @@ func NewManager(rawURL string, opts ...Option) (*Manager, error) {
 	mng := &Manager{
 		url: urlTemplate,
 
-		cache:    NewTTLCache(_defaultTTL),
+		cache:    newKeySetCache(_defaultStaleTTL),
 		client:   defaultHTTPClient(),
 		useCache: true,
+		ttl:      _defaultTTL,
+		maxTTL:   _defaultMaxTTL,
 		retries:  _defaultRetries,
 	}
 
@@ -83,6 +92,13 @@ func NewManager(rawURL string, opts ...Option) (*Manager, error) {
 		opt(mng)
 	}
 
+	if mng.ttl <= 0 {
+		mng.ttl = _defaultTTL
+	}
+	if mng.maxTTL < mng.ttl {
+		mng.maxTTL = mng.ttl
+	}
+
 	if mng.retries == 0 {
 		return nil, ErrInvalidNumRetries
 	}
@@ -90,46 +106,102 @@ func NewManager(rawURL string, opts ...Option) (*Manager, error) {
 	return mng, nil
 }
 
-// FetchKey fetches JWKS from public source or cache.
+// FetchKey fetches JWKS from public source or cache. Fetched key sets are cached per
+// JWKS URL. If JWKS endpoint fails then previously fetched (even expired) key set is
+// used as a fallback.
 func (m *Manager) FetchKey(ctx context.Context, kid string, tokenVars map[string]any) (*JWK, error) {
 	if kid == "" {
 		return nil, ErrKeyIDNotProvided
 	}
 
+	jwkURL := m.url.ExecuteString(tokenVars)
+
 	// If useCache is true, first try to get key from cache.
 	if m.useCache {
-		key, err := m.cache.Get(kid)
-		if err == nil {
-			return key, nil
+		if set, ok := m.cache.get(jwkURL); ok && set.fresh(time.Now()) {
+			if key, ok := set.keys[kid]; ok {
+				return key, nil
+			}
 		}
 	}
 
 	// Otherwise fetch from public JWKS.
-	v, err, _ := m.group.Do(kid, func() (any, error) {
-		return m.fetchKey(ctx, kid, tokenVars)
+	v, err, _ := m.group.Do(jwkURL, func() (any, error) {
+		return m.fetchKeys(ctx, jwkURL)
 	})
 	if err != nil {
+		if m.useCache {
+			if key, ok := m.staleKey(jwkURL, kid); ok {
+				return key, nil
+			}
+		}
 		return nil, err
 	}
 
-	return v.(*JWK), nil
+	key, ok := v.(map[string]*JWK)[kid]
+	if !ok {
+		return nil, ErrPublicKeyNotFound
+	}
+	return key, nil
+}
+
+func (m *Manager) staleKey(jwkURL string, kid string) (*JWK, bool) {
+	set, ok := m.cache.get(jwkURL)
+	if !ok || time.Now().After(set.expiresAt.Add(m.cache.staleTTL)) {
+		return nil, false
+	}
+	key, ok := set.keys[kid]
+	return key, ok
 }
 
-func (m *Manager) loadData(req *http.Request) ([]byte, error) {
+func (m *Manager) loadData(req *http.Request) ([]byte, time.Duration, error) {
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %d", errUnexpectedStatusCode, resp.StatusCode)
+		return nil, 0, fmt.Errorf("%w: %d", errUnexpectedStatusCode, resp.StatusCode)
 	}
-	return io.ReadAll(resp.Body)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, m.cacheTTL(resp.Header), nil
 }
 
-func (m *Manager) fetchKey(ctx context.Context, kid string, tokenVars map[string]any) (*JWK, error) {
-	jwkURL := m.url.ExecuteString(tokenVars)
+// cacheTTL returns TTL for fetched key set. Cache-Control max-age of JWKS response is
+// respected when present, but can't exceed maxTTL. Too small max-age (like max-age=0) is
+// raised to several seconds, otherwise every token verification would fetch JWKS.
+func (m *Manager) cacheTTL(header http.Header) time.Duration {
+	maxAge, ok := parseMaxAge(header.Get("Cache-Control"))
+	if !ok {
+		return m.ttl
+	}
+	if maxAge > int64(m.maxTTL/time.Second) {
+		return m.maxTTL
+	}
+	return max(time.Duration(maxAge)*time.Second, _minMaxAgeTTL)
+}
+
+// parseMaxAge extracts max-age directive value (in seconds) from Cache-Control header.
+func parseMaxAge(cacheControl string) (int64, bool) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+		return seconds, true
+	}
+	return 0, false
+}
+
+func (m *Manager) fetchKeys(ctx context.Context, jwkURL string) (map[string]*JWK, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwkURL, nil)
 	if err != nil {
 		return nil, err
@@ -137,6 +209,7 @@ func (m *Manager) fetchKey(ctx context.Context, kid string, tokenVars map[string
 
 	var set jwk.KeySpecSet
 	var data []byte
+	var ttl time.Duration
 	var lastError error
 
 	retries := m.retries
@@ -146,7 +219,7 @@ func (m *Manager) fetchKey(ctx context.Context, kid string, tokenVars map[string
 		}
 		retries--
 		var err error
-		data, err = m.loadData(req)
+		data, ttl, err = m.loadData(req)
 		if err != nil {
 			lastError = err
 			continue
@@ -162,9 +235,7 @@ func (m *Manager) fetchKey(ctx context.Context, kid string, tokenVars map[string
 		return nil, ErrPublicKeyNotFound
 	}
 
-	var res *JWK
-
-	// Save new set into cache.
+	keys := make(map[string]*JWK, len(set.Keys))
 	for _, spec := range set.Keys {
 		key, err := spec.ToJWK()
 		if err != nil {
@@ -175,19 +246,13 @@ func (m *Manager) fetchKey(ctx context.Context, kid string, tokenVars map[string
 			// Not interested in other types of Use in Centrifugo.
 			continue
 		}
-
-		if m.useCache {
-			_ = m.cache.Add(key)
-		}
-
-		if key.Kid == kid {
-			res = key
-		}
+		keys[key.Kid] = key
 	}
 
-	if res == nil {
-		return nil, ErrPublicKeyNotFound
+	// Save new set into cache.
+	if m.useCache {
+		m.cache.set(jwkURL, keys, ttl)
 	}
 
-	return res, nil
+	return keys, nil
 }
@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/rakutentech/jwk-go/jwk"
 	"github.com/stretchr/testify/require"
@@ -186,3 +188,167 @@ func TestManagerCachedFetchKey(t *testing.T) {
 		})
 	}
 }
+
+type countingHandler struct {
+	handler http.Handler
+	fetches atomic.Int32
+	fail    atomic.Bool
+	maxAge  string
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.fetches.Add(1)
+	if h.fail.Load() {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if h.maxAge != "" {
+		w.Header().Set("Cache-Control", "public, max-age="+h.maxAge)
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+func TestManagerFetchKey_CacheTTL(t *testing.T) {
+	_, pubKey, err := randomKeys()
+	require.NoError(t, err)
+
+	h := &countingHandler{handler: jwksHandler(testKey{"202101", pubKey})}
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	manager, err := NewManager(ts.URL, WithCacheTTL(200*time.Millisecond), WithMaxRetries(1))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		key, err := manager.FetchKey(context.Background(), "202101", nil)
+		require.NoError(t, err)
+		require.Equal(t, "202101", key.Kid)
+	}
+	require.Equal(t, int32(1), h.fetches.Load())
+
+	time.Sleep(300 * time.Millisecond)
+	_, err = manager.FetchKey(context.Background(), "202101", nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), h.fetches.Load())
+}
+
+func TestManagerFetchKey_CacheKeyedByURL(t *testing.T) {
+	_, pubKey, err := randomKeys()
+	require.NoError(t, err)
+
+	h := &countingHandler{handler: jwksHandler(testKey{"202101", pubKey})}
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	manager, err := NewManager(ts.URL + "/{{tenant}}")
+	require.NoError(t, err)
+
+	_, err = manager.FetchKey(context.Background(), "202101", map[string]any{"tenant": "a"})
+	require.NoError(t, err)
+	_, err = manager.FetchKey(context.Background(), "202101", map[string]any{"tenant": "b"})
+	require.NoError(t, err)
+	_, err = manager.FetchKey(context.Background(), "202101", map[string]any{"tenant": "a"})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), h.fetches.Load())
+
+	size, err := manager.cache.Len()
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+}
+
+func TestManagerFetchKey_StaleOnError(t *testing.T) {
+	_, pubKey, err := randomKeys()
+	require.NoError(t, err)
+
+	h := &countingHandler{handler: jwksHandler(testKey{"202101", pubKey})}
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	manager, err := NewManager(ts.URL, WithCacheTTL(50*time.Millisecond), WithMaxRetries(1))
+	require.NoError(t, err)
+
+	_, err = manager.FetchKey(context.Background(), "202101", nil)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	h.fail.Store(true)
+
+	// JWKS endpoint returns 500 – stale key set is used.
+	key, err := manager.FetchKey(context.Background(), "202101", nil)
+	require.NoError(t, err)
+	require.Equal(t, "202101", key.Kid)
+	require.Equal(t, int32(2), h.fetches.Load())
+
+	// Unknown key is still not found.
+	_, err = manager.FetchKey(context.Background(), "202102", nil)
+	require.ErrorIs(t, err, errUnexpectedStatusCode)
+}
+
+func TestManagerFetchKey_CacheControlMaxAge(t *testing.T) {
+	_, pubKey, err := randomKeys()
+	require.NoError(t, err)
+
+	h := &countingHandler{handler: jwksHandler(testKey{"202101", pubKey}), maxAge: "0"}
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	manager, err := NewManager(ts.URL, WithCacheTTL(time.Hour))
+	require.NoError(t, err)
+
+	_, err = manager.FetchKey(context.Background(), "202101", nil)
+	require.NoError(t, err)
+	_, err = manager.FetchKey(context.Background(), "202101", nil)
+	require.NoError(t, err)
+	// max-age=0 from response overrides configured TTL, but is raised to minimal TTL.
+	require.Equal(t, int32(1), h.fetches.Load())
+	set, ok := manager.cache.get(ts.URL)
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(_minMaxAgeTTL), set.expiresAt, time.Second)
+}
+
+func TestManagerCacheTTL(t *testing.T) {
+	manager, err := NewManager("https://example.com", WithCacheTTL(time.Minute), WithCacheMaxTTL(time.Hour))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		CacheControl string
+		TTL          time.Duration
+	}{
+		{CacheControl: "", TTL: time.Minute},
+		{CacheControl: "no-cache", TTL: time.Minute},
+		{CacheControl: "public, max-age=120", TTL: 2 * time.Minute},
+		{CacheControl: "MAX-AGE=30", TTL: 30 * time.Second},
+		{CacheControl: "max-age=0", TTL: 5 * time.Second},
+		{CacheControl: "max-age=1", TTL: 5 * time.Second},
+		{CacheControl: "max-age=86400", TTL: time.Hour},
+		{CacheControl: "max-age=9223372036854775807", TTL: time.Hour},
+		{CacheControl: "max-age=invalid", TTL: time.Minute},
+		{CacheControl: "max-age=-1", TTL: time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.CacheControl, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Cache-Control", tc.CacheControl)
+			require.Equal(t, tc.TTL, manager.cacheTTL(header))
+		})
+	}
+}
+
+func TestManagerWithCacheDeprecated(t *testing.T) {
+	_, pubKey, err := randomKeys()
+	require.NoError(t, err)
+
+	h := &countingHandler{handler: jwksHandler(testKey{"202101", pubKey})}
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	// Custom cache is ignored, key sets are still cached per URL.
+	manager, err := NewManager(ts.URL, WithCache(nil))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = manager.FetchKey(context.Background(), "202101", nil)
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), h.fetches.Load())
+}
@@ -2,14 +2,30 @@ package jwks
 
 import (
 	"net/http"
+	"time"
 )
 
 // Option is used for configuring key manager.
 type Option func(m *Manager)
 
-// WithCache sets custom cache. Default is TTLCache.
-func WithCache(c Cache) Option {
-	return func(m *Manager) { m.cache = c }
+// WithCache was used to set custom cache of keys by kid.
+//
+// Deprecated: key sets are now cached per JWKS URL by Manager itself, custom cache is
+// ignored. Use WithCacheTTL and WithCacheMaxTTL to tune caching.
+func WithCache(_ Cache) Option {
+	return func(m *Manager) {}
+}
+
+// WithCacheTTL sets how long fetched JWKS is cached when JWKS response does not
+// contain Cache-Control max-age directive. Default is 1 hour.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(m *Manager) { m.ttl = ttl }
+}
+
+// WithCacheMaxTTL limits TTL taken from Cache-Control max-age of JWKS response.
+// Default is 24 hours.
+func WithCacheMaxTTL(ttl time.Duration) Option {
+	return func(m *Manager) { m.maxTTL = ttl }
 }
 
 // WithHTTPClient sets custom http client. By default client with 1 sec timeout used.
@@ -3,12 +3,14 @@ package jwtverify
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +32,10 @@ type VerifierConfig struct {
 	// tokens generated using HMAC. Zero value means that HMAC tokens won't be allowed.
 	HMACSecretKey string
 
+	// HMACAlgorithm restricts HMAC tokens to one of HS256, HS384 or HS512 algorithms. Tokens
+	// signed with other HMAC algorithm are rejected. Zero value means that any of them is allowed.
+	HMACAlgorithm string
+
 	// RSAPublicKey is a public key used to validate connection and subscription
 	// tokens generated using RSA. Zero value means that RSA tokens won't be allowed.
 	RSAPublicKey *rsa.PublicKey
@@ -38,6 +44,10 @@ type VerifierConfig struct {
 	// tokens generated using ECDSA. Zero value means that ECDSA tokens won't be allowed.
 	ECDSAPublicKey *ecdsa.PublicKey
 
+	// Ed25519PublicKey is a public key used to validate connection and subscription
+	// tokens generated using EdDSA. Zero value means that EdDSA tokens won't be allowed.
+	Ed25519PublicKey ed25519.PublicKey
+
 	// JWKSPublicEndpoint is a public url used to validate connection and subscription
 	// tokens generated using rotating RSA public keys. Zero value means that JSON Web Key Sets
 	// extension won't be used.
@@ -62,6 +72,16 @@ type VerifierConfig struct {
 	// UserIDClaim allows overriding default claim used to extract user ID from token.
 	// By default, Centrifugo uses "sub" and we recommend keeping the default if possible.
 	UserIDClaim string
+
+	// JWKSCacheTTL is how long JSON Web Key Sets fetched from JWKSPublicEndpoint are cached.
+	// Zero value means 1 hour. Cache-Control max-age of JWKS response takes precedence
+	// (raised to 5 seconds at least).
+	JWKSCacheTTL time.Duration
+
+	// Leeway is a clock skew tolerance applied when validating exp, nbf and iat claims.
+	// Zero value means strict exp and nbf validation, iat is not checked then. Note that non-zero leeway extends the lifetime
+	// of every token, including leaked ones, so keep it small (several seconds).
+	Leeway time.Duration
 }
 
 func (c VerifierConfig) Validate() error {
@@ -71,9 +91,23 @@ func (c VerifierConfig) Validate() error {
 	if c.Issuer != "" && c.IssuerRegex != "" {
 		return errors.New("can not use both token_issuer and token_issuer_regex, configure only one of them")
 	}
+	if c.HMACAlgorithm != "" && !slices.Contains(hmacAlgorithms, jwt.Algorithm(c.HMACAlgorithm)) {
+		return fmt.Errorf("unsupported token_hmac_alg %q, must be one of HS256, HS384, HS512", c.HMACAlgorithm)
+	}
 	return nil
 }
 
+var hmacAlgorithms = []jwt.Algorithm{jwt.HS256, jwt.HS384, jwt.HS512}
+
+// HMACSigningAlgorithm returns algorithm which should be used to sign HMAC tokens for
+// this config. HS256 is used when HMACAlgorithm is not set.
+func (c VerifierConfig) HMACSigningAlgorithm() jwt.Algorithm {
+	if c.HMACAlgorithm != "" {
+		return jwt.Algorithm(c.HMACAlgorithm)
+	}
+	return jwt.HS256
+}
+
 func NewTokenVerifierJWT(config VerifierConfig, cfgContainer *config.Container) (*VerifierJWT, error) {
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("error validating token verifier config: %w", err)
@@ -101,10 +135,11 @@ func NewTokenVerifierJWT(config VerifierConfig, cfgContainer *config.Container)
 		audience:     config.Audience,
 		audienceRe:   audienceRe,
 		userIDClaim:  config.UserIDClaim,
+		leeway:       config.Leeway,
 	}
 
 	if config.JWKSPublicEndpoint != "" {
-		mng, err := jwks.NewManager(config.JWKSPublicEndpoint)
+		mng, err := jwks.NewManager(config.JWKSPublicEndpoint, jwks.WithCacheTTL(config.JWKSCacheTTL))
 		if err != nil {
 			return nil, fmt.Errorf("error creating JWK manager: %w", err)
 		}
@@ -112,7 +147,7 @@ func NewTokenVerifierJWT(config VerifierConfig, cfgContainer *config.Container)
 		log.Info().Str("endpoint", strings.Join(tools.RedactedLogURLs(config.JWKSPublicEndpoint), ",")).
 			Msg("JWKS manager created")
 	} else {
-		alg, err := newAlgorithms(config.HMACSecretKey, config.RSAPublicKey, config.ECDSAPublicKey)
+		alg, err := newAlgorithms(config.HMACSecretKey, config.HMACAlgorithm, config.RSAPublicKey, config.ECDSAPublicKey, config.Ed25519PublicKey)
 		if err != nil {
 			return nil, fmt.Errorf("error initializing token algorithms: %w", err)
 		}
@@ -132,6 +167,7 @@ type VerifierJWT struct {
 	issuer       string
 	issuerRe     *regexp.Regexp
 	userIDClaim  string
+	leeway       time.Duration
 }
 
 var (
@@ -294,31 +330,36 @@ type algorithms struct {
 	ES256 jwt.Verifier
 	ES384 jwt.Verifier
 	ES512 jwt.Verifier
+	EdDSA jwt.Verifier
 }
 
-func newAlgorithms(tokenHMACSecretKey string, rsaPubKey *rsa.PublicKey, ecdsaPubKey *ecdsa.PublicKey) (*algorithms, error) {
+func newAlgorithms(tokenHMACSecretKey string, hmacAlgorithm string, rsaPubKey *rsa.PublicKey, ecdsaPubKey *ecdsa.PublicKey, ed25519PubKey ed25519.PublicKey) (*algorithms, error) {
 	alg := &algorithms{}
 
 	var algorithms []string
 
 	// HMAC SHA.
 	if tokenHMACSecretKey != "" {
-		verifierHS256, err := jwt.NewVerifierHS(jwt.HS256, []byte(tokenHMACSecretKey))
-		if err != nil {
-			return nil, err
-		}
-		verifierHS384, err := jwt.NewVerifierHS(jwt.HS384, []byte(tokenHMACSecretKey))
-		if err != nil {
-			return nil, err
+		enabled := hmacAlgorithms
+		if hmacAlgorithm != "" {
+			// Only configured algorithm is allowed to prevent downgrade to weaker one.
+			enabled = []jwt.Algorithm{jwt.Algorithm(hmacAlgorithm)}
 		}
-		verifierHS512, err := jwt.NewVerifierHS(jwt.HS512, []byte(tokenHMACSecretKey))
-		if err != nil {
-			return nil, err
+		for _, hmacAlg := range enabled {
+			verifierHS, err := jwt.NewVerifierHS(hmacAlg, []byte(tokenHMACSecretKey))
+			if err != nil {
+				return nil, err
+			}
+			switch hmacAlg {
+			case jwt.HS256:
+				alg.HS256 = verifierHS
+			case jwt.HS384:
+				alg.HS384 = verifierHS
+			case jwt.HS512:
+				alg.HS512 = verifierHS
+			}
+			algorithms = append(algorithms, string(hmacAlg))
 		}
-		alg.HS256 = verifierHS256
-		alg.HS384 = verifierHS384
-		alg.HS512 = verifierHS512
-		algorithms = append(algorithms, []string{"HS256", "HS384", "HS512"}...)
 	}
 
 	// RSA.
@@ -377,6 +418,16 @@ func newAlgorithms(tokenHMACSecretKey string, rsaPubKey *rsa.PublicKey, ecdsaPub
 		}
 	}
 
+	// EdDSA.
+	if ed25519PubKey != nil {
+		verifierEdDSA, err := jwt.NewVerifierEdDSA(ed25519PubKey)
+		if err != nil {
+			return nil, err
+		}
+		alg.EdDSA = verifierEdDSA
+		algorithms = append(algorithms, "EdDSA")
+	}
+
 	if len(algorithms) > 0 {
 		log.Info().Str("algorithms", strings.Join(algorithms, ", ")).Msg("enabled JWT verifiers")
 	}
@@ -405,6 +456,8 @@ func (s *algorithms) verify(token *jwt.Token) error {
 		verifier = s.ES384
 	case jwt.ES512:
 		verifier = s.ES512
+	case jwt.EdDSA:
+		verifier = s.EdDSA
 	default:
 		return fmt.Errorf("%w: %s", errUnsupportedAlgorithm, string(token.Header().Algorithm))
 	}
@@ -472,6 +525,16 @@ func (verifier *VerifierJWT) validateClaims(claims jwt.RegisteredClaims, tokenVa
 	return nil
 }
 
+// isValidTime checks exp and nbf claims taking configured leeway into account. The iat
+// claim was never validated before leeway existed, so it is only checked when leeway is set.
+func (verifier *VerifierJWT) isValidTime(claims jwt.RegisteredClaims, now time.Time) bool {
+	leeway := verifier.leeway
+	if !claims.IsValidExpiresAt(now.Add(-leeway)) || !claims.IsValidNotBefore(now.Add(leeway)) {
+		return false
+	}
+	return leeway == 0 || claims.IsValidIssuedAt(now.Add(leeway))
+}
+
 func (verifier *VerifierJWT) VerifyConnectToken(t string, skipVerify bool) (ConnectToken, error) {
 	token, err := jwt.ParseNoVerify([]byte(t)) // Will be verified later.
 	if err != nil {
@@ -505,8 +568,7 @@ func (verifier *VerifierJWT) VerifyConnectToken(t string, skipVerify bool) (Conn
 			"%w: connection JWT can not contain channel claim, only subscription JWT can", ErrInvalidToken)
 	}
 
-	now := time.Now()
-	if !claims.IsValidExpiresAt(now) || !claims.IsValidNotBefore(now) {
+	if !verifier.isValidTime(claims.RegisteredClaims, time.Now()) {
 		return ConnectToken{}, ErrTokenExpired
 	}
 
@@ -666,8 +728,7 @@ func (verifier *VerifierJWT) VerifySubscribeToken(t string, skipVerify bool) (Su
 		}
 	}
 
-	now := time.Now()
-	if !claims.IsValidExpiresAt(now) || !claims.IsValidNotBefore(now) {
+	if !verifier.isValidTime(claims.RegisteredClaims, time.Now()) {
 		return SubscribeToken{}, ErrTokenExpired
 	}
 
@@ -788,14 +849,14 @@ func (verifier *VerifierJWT) Reload(config VerifierConfig) error {
 	}
 
 	if config.JWKSPublicEndpoint != "" {
-		mng, err := jwks.NewManager(config.JWKSPublicEndpoint)
+		mng, err := jwks.NewManager(config.JWKSPublicEndpoint, jwks.WithCacheTTL(config.JWKSCacheTTL))
 		if err != nil {
 			return fmt.Errorf("error creating JWK manager: %w", err)
 		}
 		verifier.jwksManager = &jwksManager{mng}
 		verifier.algorithms = nil
 	} else {
-		alg, err := newAlgorithms(config.HMACSecretKey, config.RSAPublicKey, config.ECDSAPublicKey)
+		alg, err := newAlgorithms(config.HMACSecretKey, config.HMACAlgorithm, config.RSAPublicKey, config.ECDSAPublicKey, config.Ed25519PublicKey)
 		if err != nil {
 			return err
 		}
@@ -808,5 +869,6 @@ func (verifier *VerifierJWT) Reload(config VerifierConfig) error {
 	verifier.issuer = config.Issuer
 	verifier.issuerRe = issuerRe
 	verifier.userIDClaim = config.UserIDClaim
+	verifier.leeway = config.Leeway
 	return nil
 }
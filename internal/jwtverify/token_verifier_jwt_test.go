@@ -2,20 +2,25 @@ package jwtverify
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/config"
+	"github.com/centrifugal/centrifugo/v6/internal/jwtutils"
 
 	"github.com/centrifugal/centrifuge"
 	"github.com/cristalhq/jwt/v5"
@@ -177,6 +182,30 @@ func generateTestECDSAKeys(t *testing.T) (*ecdsa.PrivateKey, *ecdsa.PublicKey) {
 	return key, &key.PublicKey
 }
 
+func generateTestEd25519Keys(t *testing.T) (ed25519.PrivateKey, ed25519.PublicKey) {
+	pubKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return privateKey, pubKey
+}
+
+func getEdDSAConnToken(user string, exp int64, privateKey ed25519.PrivateKey) string {
+	signer, _ := jwt.NewSignerEdDSA(privateKey)
+	claims := &ConnectTokenClaims{
+		Base64Info: "e30=",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: user,
+		},
+	}
+	if exp > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(exp, 0))
+	}
+	token, err := jwt.NewBuilder(signer).Build(claims)
+	if err != nil {
+		panic(err)
+	}
+	return token.String()
+}
+
 func getRSATokenBuilder(rsaPrivateKey *rsa.PrivateKey, opts ...jwt.BuilderOption) *jwt.Builder {
 	var signer jwt.Signer
 	if rsaPrivateKey != nil {
@@ -302,7 +331,7 @@ func getJWKServer(pubKey *rsa.PublicKey, kty, use, kid string) *httptest.Server
 func Test_tokenVerifierJWT_Signer(t *testing.T) {
 	_, rsaPubKey := generateTestRSAKeys(t)
 	_, ecdsaPubKey := generateTestECDSAKeys(t)
-	signer, err := newAlgorithms("secret", rsaPubKey, ecdsaPubKey)
+	signer, err := newAlgorithms("secret", "", rsaPubKey, ecdsaPubKey, nil)
 	require.NoError(t, err)
 	require.NotNil(t, signer)
 }
@@ -311,7 +340,7 @@ func Test_tokenVerifierJWT_Valid(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	ct, err := verifier.VerifyConnectToken(jwtValid, false)
 	require.NoError(t, err)
@@ -326,35 +355,35 @@ func Test_tokenVerifierJWT_Valid_CustomClaim(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test that by default `user_id` claim is ignored.
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	ct, err := verifier.VerifyConnectToken(jwtValidCustomUserClaim, false)
 	require.NoError(t, err)
 	require.Equal(t, "", ct.UserID)
 
 	// Now test that custom `user_id` claim works for connection token.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", "user_id"}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "user_id", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	ct, err = verifier.VerifyConnectToken(jwtValidCustomUserClaim, false)
 	require.NoError(t, err)
 	require.Equal(t, "test", ct.UserID)
 
 	// And the same for subscription token.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	st, err := verifier.VerifySubscribeToken(subJWTValidCustomUserClaim, false)
 	require.NoError(t, err)
 	require.Equal(t, "", st.UserID)
 	require.Equal(t, "channel", st.Channel)
 
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", "user_id"}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "user_id", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	st, err = verifier.VerifySubscribeToken(subJWTValidCustomUserClaim, false)
 	require.NoError(t, err)
 	require.Equal(t, "test", st.UserID)
 
 	// Also make sure custom claim returns empty user ID from empty object claims token.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", "user_id"}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "user_id", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	ct, err = verifier.VerifyConnectToken(emptyObjectClaimsJWT, false)
 	require.NoError(t, err)
@@ -365,7 +394,7 @@ func Test_tokenVerifierJWT_Audience(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "test2", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "test2", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 
 	// Token without aud.
@@ -376,26 +405,26 @@ func Test_tokenVerifierJWT_Audience(t *testing.T) {
 	token := getRSAConnToken("user", time.Now().Add(time.Hour).Unix(), nil)
 
 	// Verifier with audience which does not match aud in token.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "test2", "", "", "", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "test2", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.ErrorIs(t, err, ErrInvalidToken)
 
 	// Verifier with token audience.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "test", "", "", "", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "test", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.NoError(t, err)
 
 	// Verifier with token audience - valid.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "test", "", "", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "test", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.NoError(t, err)
 
 	// Verifier with token audience - invalid.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "test2", "", "", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "test2", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.Error(t, err)
@@ -405,7 +434,7 @@ func Test_tokenVerifierJWT_Issuer(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "test2", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "test2", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 
 	// Token without iss.
@@ -416,25 +445,25 @@ func Test_tokenVerifierJWT_Issuer(t *testing.T) {
 	token := getRSAConnToken("user", time.Now().Add(time.Hour).Unix(), nil)
 
 	// Verifier with issuer which does not match token iss.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "test2", "", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "test2", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.ErrorIs(t, err, ErrInvalidToken)
 
 	// Verifier with token issuer.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "test", "", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "test", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.NoError(t, err)
 
 	// Verifier with token issuer regex - valid.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "test", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "test", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.NoError(t, err)
 
 	// Verifier with token issuer regex - invalid.
-	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "test2", ""}, cfgContainer)
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "test2", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(token, false)
 	require.Error(t, err)
@@ -444,7 +473,7 @@ func Test_tokenVerifierJWT_Expired(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(jwtExpired, false)
 	require.Error(t, err)
@@ -455,7 +484,7 @@ func Test_tokenVerifierJWT_DisabledAlgorithm(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(jwtExpired, false)
 	require.Error(t, err)
@@ -466,7 +495,7 @@ func Test_tokenVerifierJWT_InvalidSignature(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(jwtInvalidSignature, false)
 	require.Error(t, err)
@@ -481,7 +510,7 @@ func Test_tokenVerifierJWT_InvalidSignature_SkipVerify(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	ct, err := verifier.VerifyConnectToken(jwtValid+"xxx", true)
 	require.NoError(t, err)
@@ -492,7 +521,7 @@ func Test_tokenVerifierJWT_WithNotBefore(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	_, err = verifier.VerifyConnectToken(jwtNotBefore, false)
 	require.Error(t, err)
@@ -506,7 +535,7 @@ func Test_tokenVerifierJWT_StringAudience(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	ct, err := verifier.VerifyConnectToken(jwtStringAud, false)
 	require.NoError(t, err)
@@ -517,13 +546,261 @@ func Test_tokenVerifierJWT_ArrayAudience(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 	ct, err := verifier.VerifyConnectToken(jwtArrayAud, false)
 	require.NoError(t, err)
 	require.Equal(t, "2694", ct.UserID)
 }
 
+func Test_tokenVerifierJWT_AudienceCheck(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		audience string
+		token    string
+		wantErr  bool
+	}{
+		{name: "string aud matches", audience: "foo", token: jwtStringAud},
+		{name: "array aud matches first", audience: "foo", token: jwtArrayAud},
+		{name: "array aud matches second", audience: "bar", token: jwtArrayAud},
+		{name: "string aud does not match", audience: "baz", token: jwtStringAud, wantErr: true},
+		{name: "array aud does not match", audience: "baz", token: jwtArrayAud, wantErr: true},
+		{name: "missing aud", audience: "foo", token: jwtValid, wantErr: true},
+		{name: "no check", audience: "", token: jwtValid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", tc.audience, "", "", "", "", 0, 0}, cfgContainer)
+			require.NoError(t, err)
+			_, err = verifier.VerifyConnectToken(tc.token, false)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorContains(t, err, "invalid audience")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_tokenVerifierJWT_IssuerCheck(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+
+	// Token with iss "test" and aud "test".
+	token := getRSAConnToken("user", time.Now().Add(time.Hour).Unix(), nil)
+
+	testCases := []struct {
+		name     string
+		issuer   string
+		audience string
+		token    string
+		errorMsg string
+	}{
+		{name: "issuer matches", issuer: "test", token: token},
+		{name: "issuer does not match", issuer: "other", token: token, errorMsg: "invalid issuer"},
+		{name: "missing iss", issuer: "test", token: jwtValid, errorMsg: "invalid issuer"},
+		{name: "issuer and audience match", issuer: "test", audience: "test", token: token},
+		{name: "issuer matches, audience does not", issuer: "test", audience: "other", token: token, errorMsg: "invalid audience"},
+		{name: "audience matches, issuer does not", issuer: "other", audience: "test", token: token, errorMsg: "invalid issuer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", tc.audience, "", tc.issuer, "", "", 0, 0}, cfgContainer)
+			require.NoError(t, err)
+			_, err = verifier.VerifyConnectToken(tc.token, false)
+			if tc.errorMsg != "" {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorContains(t, err, tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func getConnTokenWithTimeClaims(exp, nbf, iat time.Time) string {
+	builder := getRSATokenBuilder(nil)
+	claims := &ConnectTokenClaims{
+		Base64Info: "e30=",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "user",
+		},
+	}
+	if !exp.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(exp)
+	}
+	if !nbf.IsZero() {
+		claims.NotBefore = jwt.NewNumericDate(nbf)
+	}
+	if !iat.IsZero() {
+		claims.IssuedAt = jwt.NewNumericDate(iat)
+	}
+	token, err := builder.Build(claims)
+	if err != nil {
+		panic(err)
+	}
+	return token.String()
+}
+
+func Test_tokenVerifierJWT_Leeway(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	testCases := []struct {
+		name    string
+		leeway  time.Duration
+		token   string
+		wantErr bool
+	}{
+		{name: "expired no leeway", token: getConnTokenWithTimeClaims(now.Add(-5*time.Second), time.Time{}, time.Time{}), wantErr: true},
+		{name: "expired within leeway", leeway: 30 * time.Second, token: getConnTokenWithTimeClaims(now.Add(-5*time.Second), time.Time{}, time.Time{})},
+		{name: "expired beyond leeway", leeway: 30 * time.Second, token: getConnTokenWithTimeClaims(now.Add(-time.Minute), time.Time{}, time.Time{}), wantErr: true},
+		{name: "nbf no leeway", token: getConnTokenWithTimeClaims(time.Time{}, now.Add(5*time.Second), time.Time{}), wantErr: true},
+		{name: "nbf within leeway", leeway: 30 * time.Second, token: getConnTokenWithTimeClaims(time.Time{}, now.Add(5*time.Second), time.Time{})},
+		{name: "nbf beyond leeway", leeway: 30 * time.Second, token: getConnTokenWithTimeClaims(time.Time{}, now.Add(time.Minute), time.Time{}), wantErr: true},
+		{name: "iat in future no leeway", token: getConnTokenWithTimeClaims(time.Time{}, time.Time{}, now.Add(time.Second))},
+		{name: "iat in future within leeway", leeway: 30 * time.Second, token: getConnTokenWithTimeClaims(time.Time{}, time.Time{}, now.Add(5*time.Second))},
+		{name: "iat in future beyond leeway", leeway: 30 * time.Second, token: getConnTokenWithTimeClaims(time.Time{}, time.Time{}, now.Add(time.Minute)), wantErr: true},
+		{name: "iat in past", token: getConnTokenWithTimeClaims(time.Time{}, time.Time{}, now.Add(-time.Minute))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, tc.leeway}, cfgContainer)
+			require.NoError(t, err)
+			_, err = verifier.VerifyConnectToken(tc.token, false)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrTokenExpired)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func getHMACConnToken(t *testing.T, alg jwt.Algorithm, secret string) string {
+	t.Helper()
+	signer, err := jwt.NewSignerHS(alg, []byte(secret))
+	require.NoError(t, err)
+	token, err := jwt.NewBuilder(signer).Build(&ConnectTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user"},
+	})
+	require.NoError(t, err)
+	return token.String()
+}
+
+func Test_tokenVerifierJWT_HMACAlgorithm(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+
+	for _, configured := range hmacAlgorithms {
+		t.Run(string(configured), func(t *testing.T) {
+			verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", string(configured), nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
+			require.NoError(t, err)
+			for _, alg := range hmacAlgorithms {
+				ct, err := verifier.VerifyConnectToken(getHMACConnToken(t, alg, "secret"), false)
+				if alg == configured {
+					require.NoError(t, err, alg)
+					require.Equal(t, "user", ct.UserID)
+					continue
+				}
+				require.ErrorIs(t, err, ErrInvalidToken, alg)
+				require.Contains(t, err.Error(), errDisabledAlgorithm.Error(), alg)
+			}
+		})
+	}
+}
+
+func Test_tokenVerifierJWT_HMACAlgorithmDefault(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
+	require.NoError(t, err)
+	for _, alg := range hmacAlgorithms {
+		_, err := verifier.VerifyConnectToken(getHMACConnToken(t, alg, "secret"), false)
+		require.NoError(t, err, alg)
+	}
+}
+
+func Test_tokenVerifierJWT_HMACAlgorithmInvalid(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+	for _, alg := range []string{"hs256", "RS256", "none"} {
+		_, err := NewTokenVerifierJWT(VerifierConfig{"secret", alg, nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
+		require.Error(t, err, alg)
+	}
+}
+
+func TestVerifierConfigHMACSigningAlgorithm(t *testing.T) {
+	require.Equal(t, jwt.HS256, VerifierConfig{}.HMACSigningAlgorithm())
+	require.Equal(t, jwt.HS512, VerifierConfig{HMACAlgorithm: "HS512"}.HMACSigningAlgorithm())
+}
+
+func Test_tokenVerifierJWT_EdDSA(t *testing.T) {
+	privateKey, pubKey := generateTestEd25519Keys(t)
+	otherPrivateKey, _ := generateTestEd25519Keys(t)
+
+	// Public key goes through PEM as it's passed in configuration.
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	require.NoError(t, err)
+	parsedPubKey, err := jwtutils.ParseEd25519PublicKeyFromPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	cfg := config.DefaultConfig()
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, parsedPubKey, "", "", "", "", "", "", 0, 0}, cfgContainer)
+	require.NoError(t, err)
+
+	token := getEdDSAConnToken("user1", time.Now().Add(time.Hour).Unix(), privateKey)
+	ct, err := verifier.VerifyConnectToken(token, false)
+	require.NoError(t, err)
+	require.Equal(t, "user1", ct.UserID)
+
+	// Tampered signature.
+	lastDot := strings.LastIndex(token, ".")
+	signature, err := base64.RawURLEncoding.DecodeString(token[lastDot+1:])
+	require.NoError(t, err)
+	signature[0] ^= 0xFF
+	tampered := token[:lastDot+1] + base64.RawURLEncoding.EncodeToString(signature)
+	_, err = verifier.VerifyConnectToken(tampered, false)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Signed with another key.
+	_, err = verifier.VerifyConnectToken(getEdDSAConnToken("user1", time.Now().Add(time.Hour).Unix(), otherPrivateKey), false)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Unsigned token.
+	_, err = verifier.VerifyConnectToken(jwtNoneAlgorithm(), false)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// EdDSA is not allowed if Ed25519 public key not configured.
+	verifier, err = NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
+	require.NoError(t, err)
+	_, err = verifier.VerifyConnectToken(token, false)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func jwtNoneAlgorithm() string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user1"}`))
+	return header + "." + payload + "."
+}
+
 func Test_tokenVerifierJWT_VerifyConnectToken(t *testing.T) {
 	type args struct {
 		token string
@@ -536,7 +813,7 @@ func Test_tokenVerifierJWT_VerifyConnectToken(t *testing.T) {
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
 
-	verifierJWT, err := NewTokenVerifierJWT(VerifierConfig{"secret", rsaPubKey, ecdsaPubKey, "", "", "", "", "", ""}, cfgContainer)
+	verifierJWT, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", rsaPubKey, ecdsaPubKey, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 
 	_time := time.Now()
@@ -698,7 +975,7 @@ func Test_tokenVerifierJWT_VerifyConnectTokenWithJWK(t *testing.T) {
 			cfgContainer, err := config.NewContainer(cfg)
 			require.NoError(t, err)
 
-			verifier, err := NewTokenVerifierJWT(VerifierConfig{"", nil, nil, ts.URL, "", "", "", "", ""}, cfgContainer)
+			verifier, err := NewTokenVerifierJWT(VerifierConfig{"", "", nil, nil, nil, ts.URL, "", "", "", "", "", 0, 0}, cfgContainer)
 			require.NoError(t, err)
 
 			token := getRSAConnToken(tt.token.user, tt.token.exp, privKey, jwt.WithKeyID(tt.jwk.kid))
@@ -732,7 +1009,7 @@ func Test_tokenVerifierJWT_VerifySubscribeToken(t *testing.T) {
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
 
-	verifierJWT, err := NewTokenVerifierJWT(VerifierConfig{"secret", rsaPubKey, ecdsaPubKey, "", "", "", "", "", ""}, cfgContainer)
+	verifierJWT, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", rsaPubKey, ecdsaPubKey, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 
 	_time := time.Now()
@@ -878,7 +1155,7 @@ func TestJWKS(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"", nil, nil, ts.URL, "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"", "", nil, nil, nil, ts.URL, "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 
 	// Validate an RSA token
@@ -920,7 +1197,7 @@ func TestJWKS_NoAlg(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(t, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"", nil, nil, ts.URL, "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"", "", nil, nil, nil, ts.URL, "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(t, err)
 
 	// Validate an RSA token
@@ -957,7 +1234,7 @@ func BenchmarkConnectTokenVerify_Valid(b *testing.B) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(b, err)
-	verifierJWT, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifierJWT, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(b, err)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -974,7 +1251,7 @@ func BenchmarkConnectTokenVerify_Expired(b *testing.B) {
 	cfg := config.DefaultConfig()
 	cfgContainer, err := config.NewContainer(cfg)
 	require.NoError(b, err)
-	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", nil, nil, "", "", "", "", "", ""}, cfgContainer)
+	verifier, err := NewTokenVerifierJWT(VerifierConfig{"secret", "", nil, nil, nil, "", "", "", "", "", "", 0, 0}, cfgContainer)
 	require.NoError(b, err)
 	for i := 0; i < b.N; i++ {
 		_, err := verifier.VerifyConnectToken(jwtExpired, false)
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuth middleware authorizes request using HTTP Basic authentication. Passwords
+// are stored as bcrypt hashes. If credentials are missing or invalid then 401 response
+// code with WWW-Authenticate header is returned.
+type BasicAuth struct {
+	realm       string
+	credentials map[string][]byte
+	// dummyHash is compared against for unknown users so that response time does not
+	// reveal whether user exists.
+	dummyHash []byte
+}
+
+// NewBasicAuth creates BasicAuth middleware. The credentials map contains usernames
+// as keys and bcrypt hashed passwords as values.
+func NewBasicAuth(realm string, credentials map[string]string) (*BasicAuth, error) {
+	if len(credentials) == 0 {
+		return nil, errors.New("no credentials provided")
+	}
+	hashes := make(map[string][]byte, len(credentials))
+	maxCost := bcrypt.MinCost
+	for username, hash := range credentials {
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bcrypt hash for user %q: %w", username, err)
+		}
+		if cost > maxCost {
+			maxCost = cost
+		}
+		hashes[username] = []byte(hash)
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("centrifugo"), maxCost)
+	if err != nil {
+		return nil, fmt.Errorf("error generating dummy hash: %w", err)
+	}
+	return &BasicAuth{realm: realm, credentials: hashes, dummyHash: dummyHash}, nil
+}
+
+// NewBasicAuthUser creates BasicAuth middleware for a single user.
+func NewBasicAuthUser(realm string, username string, passwordHash string) (*BasicAuth, error) {
+	return NewBasicAuth(realm, map[string]string{username: passwordHash})
+}
+
+func (a *BasicAuth) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Basic realm="+strconv.Quote(a.realm)+", charset=\"UTF-8\"")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (a *BasicAuth) authorized(r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	hash, userFound := a.credentials[username]
+	if !userFound {
+		hash = a.dummyHash
+	}
+	// Always run bcrypt comparison to keep the same timing for unknown
+	// users and wrong passwords.
+	passwordValid := bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	return userFound && passwordValid
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testBasicAuth(t *testing.T) *BasicAuth {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	auth, err := NewBasicAuthUser("centrifugo", "admin", string(hash))
+	require.NoError(t, err)
+	return auth
+}
+
+func TestNewBasicAuthInvalidHash(t *testing.T) {
+	_, err := NewBasicAuth("centrifugo", map[string]string{"admin": "secret"})
+	require.Error(t, err)
+	_, err = NewBasicAuth("centrifugo", nil)
+	require.Error(t, err)
+}
+
+func TestBasicAuthCorrectCredentials(t *testing.T) {
+	handler := testBasicAuth(t).Middleware(testHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "secret")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("WWW-Authenticate"))
+}
+
+func TestBasicAuthWrongPassword(t *testing.T) {
+	handler := testBasicAuth(t).Middleware(testHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "wrong")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.Equal(t, `Basic realm="centrifugo", charset="UTF-8"`, rr.Header().Get("WWW-Authenticate"))
+}
+
+func TestBasicAuthUnknownUser(t *testing.T) {
+	handler := testBasicAuth(t).Middleware(testHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("guest", "secret")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestBasicAuthMissingHeader(t *testing.T) {
+	handler := testBasicAuth(t).Middleware(testHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+}
@@ -0,0 +1,307 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultCompressMinSize = 1024
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// Compress middleware compresses response bodies using gzip or deflate depending
+// on request Accept-Encoding header. Responses smaller than minimal size, responses
+// with already compressed content types, SSE streams and WebSocket upgrade requests
+// are passed as is.
+type Compress struct {
+	minSize int
+}
+
+// NewCompress creates Compress middleware. Response body is compressed only when it
+// reaches minSize bytes, if minSize <= 0 then default value of 1024 bytes is used.
+func NewCompress(minSize int) *Compress {
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+	return &Compress{minSize: minSize}
+}
+
+func (c *Compress) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isUpgradeRequest(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding, minSize: c.minSize}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
+
+func isUpgradeRequest(r *http.Request) bool {
+	return strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") ||
+		r.Header.Get("Upgrade") != ""
+}
+
+// negotiateEncoding selects supported encoding from Accept-Encoding header value. Gzip
+// is preferred over deflate when both have the same quality.
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	var (
+		selected  string
+		selectedQ float64
+		wildcardQ = -1.0
+		gzipQ     = -1.0
+		deflateQ  = -1.0
+	)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseEncodingPart(part)
+		if name == "" {
+			continue
+		}
+		switch name {
+		case encodingGzip:
+			gzipQ = q
+		case encodingDeflate:
+			deflateQ = q
+		case "*":
+			wildcardQ = q
+		}
+	}
+	if gzipQ < 0 {
+		gzipQ = wildcardQ
+	}
+	if deflateQ < 0 {
+		deflateQ = wildcardQ
+	}
+	if gzipQ > 0 {
+		selected, selectedQ = encodingGzip, gzipQ
+	}
+	if deflateQ > 0 && deflateQ > selectedQ {
+		selected = encodingDeflate
+	}
+	return selected
+}
+
+func parseEncodingPart(part string) (string, float64) {
+	name, params, _ := strings.Cut(part, ";")
+	name = strings.ToLower(strings.TrimSpace(name))
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.TrimSpace(key) != "q" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", 0
+		}
+		q = parsed
+	}
+	return name, q
+}
+
+// compressibleContentType returns false for content types which are already
+// compressed or represent streaming responses.
+func compressibleContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"):
+		return false
+	}
+	switch mediaType {
+	case "text/event-stream",
+		"application/zip",
+		"application/gzip",
+		"application/x-gzip",
+		"application/zstd",
+		"application/x-bzip2",
+		"application/x-7z-compressed",
+		"application/x-rar-compressed",
+		"application/grpc":
+		return false
+	}
+	return true
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var (
+	gzipWriterPool = sync.Pool{
+		New: func() any {
+			return gzip.NewWriter(io.Discard)
+		},
+	}
+	// Deflate content coding is zlib format (RFC 9110), not raw DEFLATE.
+	zlibWriterPool = sync.Pool{
+		New: func() any {
+			zw, _ := zlib.NewWriterLevel(io.Discard, zlib.DefaultCompression)
+			return zw
+		},
+	}
+)
+
+func getCompressor(encoding string, w io.Writer) compressor {
+	var c compressor
+	switch encoding {
+	case encodingGzip:
+		c = gzipWriterPool.Get().(*gzip.Writer)
+	default:
+		c = zlibWriterPool.Get().(*zlib.Writer)
+	}
+	c.Reset(w)
+	return c
+}
+
+func putCompressor(c compressor) {
+	switch v := c.(type) {
+	case *gzip.Writer:
+		gzipWriterPool.Put(v)
+	case *zlib.Writer:
+		zlibWriterPool.Put(v)
+	}
+}
+
+// compressResponseWriter buffers response body until minimal size reached and then
+// decides whether to compress it.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding   string
+	minSize    int
+	status     int
+	buf        []byte
+	decided    bool
+	compressor compressor
+}
+
+func (cw *compressResponseWriter) WriteHeader(status int) {
+	if status < http.StatusOK {
+		// Informational responses are sent as is.
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.decided {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressResponseWriter) Write(p []byte) (int, error) {
+	if cw.decided {
+		if cw.compressor != nil {
+			return cw.compressor.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (cw *compressResponseWriter) decide(allowCompression bool) error {
+	cw.decided = true
+	header := cw.Header()
+	if header.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	status := cw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if allowCompression &&
+		status != http.StatusNoContent && status != http.StatusNotModified &&
+		header.Get("Content-Encoding") == "" &&
+		compressibleContentType(header.Get("Content-Type")) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", cw.encoding)
+		cw.compressor = getCompressor(cw.encoding, cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(status)
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.compressor != nil {
+		_, err = cw.compressor.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (cw *compressResponseWriter) close() {
+	if !cw.decided {
+		_ = cw.decide(false)
+	}
+	if cw.compressor != nil {
+		_ = cw.compressor.Close()
+		putCompressor(cw.compressor)
+		cw.compressor = nil
+	}
+}
+
+// Flush implements http.Flusher. Flushing before minimal size reached sends
+// response without compression.
+func (cw *compressResponseWriter) Flush() {
+	if !cw.decided {
+		_ = cw.decide(false)
+	}
+	if cw.compressor != nil {
+		_ = cw.compressor.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (cw *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("ResponseWriter doesn't support Hijacker interface")
+	}
+	cw.decided = true
+	return hijacker.Hijack()
+}
+
+// Unwrap is required for http.ResponseController.
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func compressTestHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestCompressGzip(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+	req := httptest.NewRequest(http.MethodPost, "/api", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rr := httptest.NewRecorder()
+
+	NewCompress(1024).Middleware(compressTestHandler(body)).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	gr, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	require.Equal(t, body, string(data))
+}
+
+func TestCompressDeflate(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+	req := httptest.NewRequest(http.MethodPost, "/api", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0.5, deflate")
+	rr := httptest.NewRecorder()
+
+	NewCompress(1024).Middleware(compressTestHandler(body)).ServeHTTP(rr, req)
+
+	require.Equal(t, "deflate", rr.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(rr.Body)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Equal(t, body, string(data))
+}
+
+func TestCompressNotAccepted(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+	req := httptest.NewRequest(http.MethodPost, "/api", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0, br")
+	rr := httptest.NewRecorder()
+
+	NewCompress(1024).Middleware(compressTestHandler(body)).ServeHTTP(rr, req)
+
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Equal(t, body, rr.Body.String())
+}
+
+func TestCompressBelowMinSize(t *testing.T) {
+	body := strings.Repeat("a", 100)
+	req := httptest.NewRequest(http.MethodPost, "/api", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	NewCompress(1024).Middleware(compressTestHandler(body)).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Equal(t, body, rr.Body.String())
+}
+
+func TestCompressSkipCompressedContentType(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+	req := httptest.NewRequest(http.MethodGet, "/image", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(body))
+	})
+	NewCompress(1024).Middleware(handler).ServeHTTP(rr, req)
+
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Equal(t, body, rr.Body.String())
+}
+
+func TestCompressSkipWebSocketUpgrade(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/connection/websocket", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(*compressResponseWriter)
+		require.False(t, ok, "response writer must not be wrapped for upgrade requests")
+		_, _ = w.Write([]byte(strings.Repeat("a", 2048)))
+	})
+	NewCompress(1024).Middleware(handler).ServeHTTP(rr, req)
+
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Empty(t, rr.Header().Get("Vary"))
+}
+
+func TestCompressFlushPassthrough(t *testing.T) {
+	flushed := make(chan struct{})
+	proceed := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("a", 2048)))
+		w.(http.Flusher).Flush()
+		close(flushed)
+		<-proceed
+	})
+	ts := httptest.NewServer(NewCompress(1024).Middleware(handler))
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	// Set header explicitly to disable transparent decompression by http.Transport.
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+	<-flushed
+	require.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+
+	gr, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+	buf := make([]byte, 2048)
+	// Must be readable before handler finished its work.
+	_, err = io.ReadFull(gr, buf)
+	require.NoError(t, err)
+	require.Equal(t, bytes.Repeat([]byte("a"), 2048), buf)
+	close(proceed)
+}
+
+func TestCompressFlushBeforeMinSize(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(strings.Repeat("a", 2048)))
+	})
+	NewCompress(1024).Middleware(handler).ServeHTTP(rr, req)
+
+	require.True(t, rr.Flushed)
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Equal(t, "data"+strings.Repeat("a", 2048), rr.Body.String())
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.1, deflate;q=0.9", "deflate"},
+		{"gzip;q=0", ""},
+		{"*", "gzip"},
+		{"*;q=0", ""},
+		{"br, *;q=0.5", "gzip"},
+		{"br", ""},
+		{"identity", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			require.Equal(t, tc.expected, negotiateEncoding(tc.header))
+		})
+	}
+}
@@ -1,28 +1,221 @@
 package middleware
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
 
 type OriginCheck func(r *http.Request) bool
 
+// OriginResolver returns origin to reflect in Access-Control-Allow-Origin header for request, which
+// may differ from request Origin – e.g. canonical one of equivalent origins. Returns false if
+// request origin is not allowed.
+type OriginResolver func(r *http.Request) (allowedOrigin string, ok bool)
+
 // CORS middleware.
 type CORS struct {
-	originCheck OriginCheck
+	originCheck    OriginCheck
+	originResolver OriginResolver
+	// allowAny is set when constructed from origins list containing "*". In this case
+	// Access-Control-Allow-Origin is "*" and credentials are not allowed as required by spec.
+	allowAny bool
+	reject   *CORSRejectConfig
+}
+
+// CORSRejectConfig configures rejection of cross-origin requests with disallowed Origin.
+type CORSRejectConfig struct {
+	// RejectDisallowedOrigin makes middleware respond with Status instead of passing cross-origin
+	// request with disallowed Origin to the next handler. Requests with Origin matching request
+	// Host are same-origin and are never rejected.
+	RejectDisallowedOrigin bool
+	// Status of rejection response. Default is 403.
+	Status int
+	// Body is an optional JSON body of rejection response.
+	Body []byte
+}
+
+// WithReject sets rejection of disallowed cross-origin requests, by default they are passed to
+// the next handler without CORS headers. Must be called before middleware is used.
+func (c *CORS) WithReject(cfg CORSRejectConfig) *CORS {
+	if !cfg.RejectDisallowedOrigin {
+		c.reject = nil
+		return c
+	}
+	if cfg.Status == 0 {
+		cfg.Status = http.StatusForbidden
+	}
+	c.reject = &cfg
+	return c
 }
 
 func NewCORS(originCheck OriginCheck) *CORS {
 	return &CORS{originCheck: originCheck}
 }
 
+// NewCORSWithResolver creates CORS which reflects origin returned by originResolver.
+func NewCORSWithResolver(originResolver OriginResolver) *CORS {
+	return &CORS{originResolver: originResolver}
+}
+
+// NewCORSFromOrigins creates CORS which allows requests from the static list of origins. Origins
+// are matched exactly, except scheme and host which are compared case-insensitively. Special "*"
+// entry allows any origin.
+func NewCORSFromOrigins(origins []string) *CORS {
+	allowed := make(map[string]struct{}, len(origins))
+	for _, origin := range origins {
+		if origin == "*" {
+			return &CORS{
+				originCheck: func(r *http.Request) bool { return r.Header.Get("Origin") != "" },
+				allowAny:    true,
+			}
+		}
+		if normalized, ok := normalizeOrigin(origin); ok {
+			allowed[normalized] = struct{}{}
+		}
+	}
+	return &CORS{originCheck: func(r *http.Request) bool {
+		normalized, ok := normalizeOrigin(r.Header.Get("Origin"))
+		if !ok {
+			return false
+		}
+		_, ok = allowed[normalized]
+		return ok
+	}}
+}
+
+// normalizeOrigin lowercases scheme and host of origin. Returns false if origin is not
+// a scheme://host[:port] string.
+func normalizeOrigin(origin string) (string, bool) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", false
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host), true
+}
+
+// RegexpOriginCheck returns OriginCheck which allows origins fully matching any of regular
+// expression patterns, like `https://pr-\d+\.app\.example\.com`. Patterns are always anchored,
+// so they can't match part of origin. Returns error if any pattern is invalid.
+func RegexpOriginCheck(patterns []string) (OriginCheck, error) {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid origin pattern %q: %w", pattern, err)
+		}
+		regexps = append(regexps, re)
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return false
+		}
+		for _, re := range regexps {
+			if re.MatchString(origin) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// allowedOrigin returns value of Access-Control-Allow-Origin header for request.
+func (c *CORS) allowedOrigin(r *http.Request) (string, bool) {
+	if c.originResolver != nil {
+		return c.originResolver(r)
+	}
+	if !c.originCheck(r) {
+		return "", false
+	}
+	if c.allowAny {
+		return "*", true
+	}
+	return r.Header.Get("Origin"), true
+}
+
+// isSameOrigin reports whether request Origin host is the host request was sent to.
+func isSameOrigin(r *http.Request) bool {
+	u, err := url.Parse(r.Header.Get("Origin"))
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// Middleware sets CORS headers for requests with allowed Origin. Requests without Origin (same
+// origin or server-to-server) are passed through as is, preflight requests always carry Origin.
+// Requests with disallowed Origin are passed through without CORS headers unless rejection
+// is configured with WithReject.
 func (c *CORS) Middleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
 		header := w.Header()
-		if c.originCheck(r) {
-			header.Set("Access-Control-Allow-Origin", r.Header.Get("origin"))
+		if allowedOrigin, ok := c.allowedOrigin(r); ok {
+			header.Set("Access-Control-Allow-Origin", allowedOrigin)
 			if allowHeaders := r.Header.Get("Access-Control-Request-Headers"); allowHeaders != "" && allowHeaders != "null" {
 				header.Add("Access-Control-Allow-Headers", allowHeaders)
 			}
-			header.Set("Access-Control-Allow-Credentials", "true")
+			if !c.allowAny {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+		} else if c.reject != nil && !isSameOrigin(r) {
+			if len(c.reject.Body) > 0 {
+				header.Set("Content-Type", "application/json")
+			}
+			w.WriteHeader(c.reject.Status)
+			if len(c.reject.Body) > 0 {
+				_, _ = w.Write(c.reject.Body)
+			}
+			return
 		}
 		h.ServeHTTP(w, r)
 	})
 }
+
+// CORSRouter applies different CORS policies depending on request URL path.
+type CORSRouter struct {
+	prefixes []string // Sorted by length descending, so the most specific prefix is found first.
+	routes   map[string]*CORS
+	fallback *CORS
+}
+
+// NewCORSRouter creates CORSRouter which applies CORS of the longest path prefix in routes
+// matching request URL path. Requests matching no prefix are handled by fallback, or passed
+// through without CORS headers if fallback is nil.
+func NewCORSRouter(routes map[string]*CORS, fallback *CORS) *CORSRouter {
+	prefixes := make([]string, 0, len(routes))
+	for prefix := range routes {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.SortFunc(prefixes, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+	return &CORSRouter{prefixes: prefixes, routes: routes, fallback: fallback}
+}
+
+func (c *CORSRouter) Middleware(h http.Handler) http.Handler {
+	handlers := make(map[string]http.Handler, len(c.routes))
+	for prefix, cors := range c.routes {
+		handlers[prefix] = cors.Middleware(h)
+	}
+	fallback := h
+	if c.fallback != nil {
+		fallback = c.fallback.Middleware(h)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range c.prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				handlers[prefix].ServeHTTP(w, r)
+				return
+			}
+		}
+		fallback.ServeHTTP(w, r)
+	})
+}
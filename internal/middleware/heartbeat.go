@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var heartbeatComment = []byte(": keep-alive\n")
+
+// Heartbeat middleware periodically writes SSE comment lines into text/event-stream
+// responses and flushes them, so intermediaries between client and server do not buffer
+// the stream or close it as idle. Heartbeats start once handler sent response headers
+// and stop when handler returns or client disconnects. Comment is never injected into
+// the middle of a line written by handler. Other responses are passed as is.
+type Heartbeat struct {
+	interval time.Duration
+}
+
+// NewHeartbeat creates Heartbeat middleware which writes heartbeat every interval.
+func NewHeartbeat(interval time.Duration) *Heartbeat {
+	return &Heartbeat{interval: interval}
+}
+
+func (m *Heartbeat) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.interval <= 0 || isUpgradeRequest(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		hw := &heartbeatResponseWriter{
+			ResponseWriter: w,
+			request:        r,
+			interval:       m.interval,
+			stop:           make(chan struct{}),
+			lineStart:      true,
+		}
+		defer hw.close()
+		h.ServeHTTP(hw, r)
+	})
+}
+
+// heartbeatResponseWriter starts heartbeat goroutine when text/event-stream response
+// headers are written. Handler writes and heartbeats are serialized with mu.
+type heartbeatResponseWriter struct {
+	http.ResponseWriter
+	request  *http.Request
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+
+	mu          sync.Mutex
+	wroteHeader bool
+	stopped     bool
+	// lineStart is true if handler did not write anything yet or its last write ended
+	// with new line, so that comment line can be safely written.
+	lineStart bool
+}
+
+func (w *heartbeatResponseWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeaderLocked(status)
+}
+
+func (w *heartbeatResponseWriter) writeHeaderLocked(status int) {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if status >= http.StatusOK {
+		w.wroteHeader = true
+		if status == http.StatusOK && isEventStreamResponse(w.Header()) {
+			w.wg.Add(1)
+			go w.run()
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *heartbeatResponseWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.wroteHeader {
+		w.writeHeaderLocked(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(p)
+	if n > 0 {
+		w.lineStart = p[n-1] == '\n'
+	}
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *heartbeatResponseWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.wroteHeader {
+		w.writeHeaderLocked(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap is required for http.ResponseController.
+func (w *heartbeatResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *heartbeatResponseWriter) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.request.Context().Done():
+			return
+		case <-ticker.C:
+			if !w.heartbeat() {
+				return
+			}
+		}
+	}
+}
+
+// heartbeat writes comment line and flushes it. Returns false if heartbeats must stop.
+func (w *heartbeatResponseWriter) heartbeat() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return false
+	}
+	if !w.lineStart {
+		return true
+	}
+	if _, err := w.ResponseWriter.Write(heartbeatComment); err != nil {
+		return false
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return true
+}
+
+// close stops heartbeats and waits for heartbeat goroutine to exit, so that nothing is
+// written into response after handler returned.
+func (w *heartbeatResponseWriter) close() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+	close(w.stop)
+	w.wg.Wait()
+}
+
+func isEventStreamResponse(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "text/event-stream"
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// syncRecorder is a http.ResponseWriter safe for reading while heartbeats are written.
+type syncRecorder struct {
+	mu      sync.Mutex
+	header  http.Header
+	body    bytes.Buffer
+	flushes int
+}
+
+func newSyncRecorder() *syncRecorder {
+	return &syncRecorder{header: http.Header{}}
+}
+
+func (r *syncRecorder) Header() http.Header { return r.header }
+
+func (r *syncRecorder) WriteHeader(int) {}
+
+func (r *syncRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.Write(p)
+}
+
+func (r *syncRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushes++
+}
+
+func (r *syncRecorder) heartbeats() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Count(r.body.String(), string(heartbeatComment))
+}
+
+func (r *syncRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.String()
+}
+
+func eventStreamHandler(contentType string, d time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("data: hello\n\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+		}
+	})
+}
+
+func TestHeartbeatInterval(t *testing.T) {
+	rec := newSyncRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	NewHeartbeat(20*time.Millisecond).Middleware(eventStreamHandler("text/event-stream; charset=utf-8", 110*time.Millisecond)).ServeHTTP(rec, req)
+	heartbeats := rec.heartbeats()
+	require.GreaterOrEqual(t, heartbeats, 3)
+	require.LessOrEqual(t, heartbeats, 6)
+	require.True(t, strings.HasPrefix(rec.String(), "data: hello\n\n: keep-alive\n"))
+	require.Greater(t, rec.flushes, heartbeats)
+
+	// Nothing written after handler returned.
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, heartbeats, rec.heartbeats())
+}
+
+func TestHeartbeatStopsOnContextCancel(t *testing.T) {
+	rec := newSyncRecorder()
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewHeartbeat(10*time.Millisecond).Middleware(eventStreamHandler("text/event-stream", time.Minute)).ServeHTTP(rec, req)
+	}()
+	require.Eventually(t, func() bool { return rec.heartbeats() >= 2 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "handler not stopped")
+	}
+	heartbeats := rec.heartbeats()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, heartbeats, rec.heartbeats())
+}
+
+func TestHeartbeatNotEventStream(t *testing.T) {
+	rec := newSyncRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	NewHeartbeat(10*time.Millisecond).Middleware(eventStreamHandler("application/json", 60*time.Millisecond)).ServeHTTP(rec, req)
+	require.Zero(t, rec.heartbeats())
+}
+
+func TestHeartbeatPartialLine(t *testing.T) {
+	rec := newSyncRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: "))
+		time.Sleep(60 * time.Millisecond)
+		_, _ = w.Write([]byte("hello\n\n"))
+	})
+	NewHeartbeat(10*time.Millisecond).Middleware(handler).ServeHTTP(rec, req)
+	require.Equal(t, "data: hello\n\n", rec.String())
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+)
+
+// IPFilterConfig configures IPFilter middleware.
+type IPFilterConfig struct {
+	// Allow is a list of CIDR ranges allowed to access handler. Empty list allows all.
+	Allow []string
+	// Deny is a list of CIDR ranges denied to access handler. Deny takes precedence over Allow.
+	Deny []string
+	// TrustedProxies is a list of CIDR ranges of upstream proxies. X-Forwarded-For header
+	// is only used to find client IP when request came from one of trusted proxies. Not
+	// used if client IP was already resolved by RealIP middleware.
+	TrustedProxies []string
+}
+
+// IPFilter middleware allows or denies requests based on client IP address. Denied
+// requests get 403 response code.
+type IPFilter struct {
+	allow          []netip.Prefix
+	deny           []netip.Prefix
+	trustedProxies []netip.Prefix
+}
+
+func NewIPFilter(cfg IPFilterConfig) (*IPFilter, error) {
+	allow, err := parsePrefixes(cfg.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing allow list: %w", err)
+	}
+	deny, err := parsePrefixes(cfg.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing deny list: %w", err)
+	}
+	trustedProxies, err := parsePrefixes(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trusted proxies: %w", err)
+	}
+	return &IPFilter{allow: allow, deny: deny, trustedProxies: trustedProxies}, nil
+}
+
+func (f *IPFilter) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, ok := ClientIPFromContext(r.Context())
+		if !ok {
+			ip, ok = resolveClientIP(r, f.trustedProxies)
+		}
+		if !ok || !f.allowed(ip) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (f *IPFilter) allowed(ip netip.Addr) bool {
+	if prefixesContain(f.deny, ip) {
+		return false
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	return prefixesContain(f.allow, ip)
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIPFilterMalformedCIDR(t *testing.T) {
+	_, err := NewIPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/33"}})
+	require.Error(t, err)
+	_, err = NewIPFilter(IPFilterConfig{Deny: []string{"not-a-cidr"}})
+	require.Error(t, err)
+	_, err = NewIPFilter(IPFilterConfig{TrustedProxies: []string{"10.0.0.1"}})
+	require.Error(t, err)
+}
+
+func TestIPFilter(t *testing.T) {
+	filter, err := NewIPFilter(IPFilterConfig{
+		Allow: []string{"10.0.0.0/8", "2001:db8::/32"},
+		Deny:  []string{"10.1.0.0/16", "2001:db8:dead::/48"},
+	})
+	require.NoError(t, err)
+	handler := filter.Middleware(testHandler())
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		expected   int
+	}{
+		{"ipv4 allowed", "10.0.0.1:1234", http.StatusOK},
+		{"ipv4 denied takes precedence", "10.1.2.3:1234", http.StatusForbidden},
+		{"ipv4 not in allow list", "192.168.1.1:1234", http.StatusForbidden},
+		{"ipv6 allowed", "[2001:db8::1]:1234", http.StatusOK},
+		{"ipv6 denied takes precedence", "[2001:db8:dead::1]:1234", http.StatusForbidden},
+		{"ipv6 not in allow list", "[2001:db9::1]:1234", http.StatusForbidden},
+		{"ipv4-mapped ipv6", "[::ffff:10.0.0.1]:1234", http.StatusOK},
+		{"malformed remote addr", "bad", http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, tc.expected, rr.Code)
+		})
+	}
+}
+
+func TestIPFilterEmptyAllowList(t *testing.T) {
+	filter, err := NewIPFilter(IPFilterConfig{Deny: []string{"192.168.0.0/16"}})
+	require.NoError(t, err)
+	handler := filter.Middleware(testHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "8.8.8.8:1234"
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestIPFilterSpoofedForwardedForIgnored(t *testing.T) {
+	filter, err := NewIPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+	handler := filter.Middleware(testHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.10:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestIPFilterTrustedProxy(t *testing.T) {
+	filter, err := NewIPFilter(IPFilterConfig{
+		Allow:          []string{"10.0.0.0/8"},
+		TrustedProxies: []string{"172.16.0.0/12"},
+	})
+	require.NoError(t, err)
+	handler := filter.Middleware(testHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "172.16.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Client prepended an allowed address, but the first untrusted hop is used.
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "172.16.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1, 203.0.113.10")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// MaintenanceConfig configures Maintenance middleware.
+type MaintenanceConfig struct {
+	// RetryAfter is a value of Retry-After header sent to clients. Default is 30 seconds.
+	RetryAfter time.Duration
+	// Body is an optional JSON body of 503 response.
+	Body []byte
+	// ExemptPaths is a list of URL paths (for example, health check endpoint) which are
+	// still served while in maintenance mode.
+	ExemptPaths []string
+}
+
+const defaultMaintenanceRetryAfter = 30 * time.Second
+
+// Maintenance middleware rejects requests with 503 response code while maintenance
+// mode is enabled. Mode may be toggled at runtime with Enable and Disable methods.
+type Maintenance struct {
+	enabled     atomic.Bool
+	retryAfter  string
+	body        []byte
+	exemptPaths map[string]struct{}
+}
+
+func NewMaintenance(cfg MaintenanceConfig) *Maintenance {
+	retryAfter := cfg.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultMaintenanceRetryAfter
+	}
+	exemptPaths := make(map[string]struct{}, len(cfg.ExemptPaths))
+	for _, path := range cfg.ExemptPaths {
+		exemptPaths[path] = struct{}{}
+	}
+	return &Maintenance{
+		retryAfter:  strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10),
+		body:        cfg.Body,
+		exemptPaths: exemptPaths,
+	}
+}
+
+// Enable turns maintenance mode on.
+func (m *Maintenance) Enable() {
+	m.enabled.Store(true)
+}
+
+// Disable turns maintenance mode off.
+func (m *Maintenance) Disable() {
+	m.enabled.Store(false)
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *Maintenance) Enabled() bool {
+	return m.enabled.Load()
+}
+
+func (m *Maintenance) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.enabled.Load() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := m.exemptPaths[r.URL.Path]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", m.retryAfter)
+		if len(m.body) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if len(m.body) > 0 {
+			_, _ = w.Write(m.body)
+		}
+	})
+}
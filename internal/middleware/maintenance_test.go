@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceToggle(t *testing.T) {
+	m := NewMaintenance(MaintenanceConfig{})
+	handler := m.Middleware(testHandler())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.False(t, m.Enabled())
+
+	m.Enable()
+	require.True(t, m.Enabled())
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Equal(t, "30", rr.Header().Get("Retry-After"))
+	require.Empty(t, rr.Body.String())
+
+	m.Disable()
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestMaintenanceBodyAndRetryAfter(t *testing.T) {
+	m := NewMaintenance(MaintenanceConfig{
+		RetryAfter: 1500 * time.Millisecond,
+		Body:       []byte(`{"error":"maintenance"}`),
+	})
+	m.Enable()
+	rr := httptest.NewRecorder()
+	m.Middleware(testHandler()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Equal(t, "2", rr.Header().Get("Retry-After"))
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"error":"maintenance"}`, rr.Body.String())
+}
+
+func TestMaintenanceHealthPathExempt(t *testing.T) {
+	m := NewMaintenance(MaintenanceConfig{ExemptPaths: []string{"/health"}})
+	m.Enable()
+	handler := m.Middleware(testHandler())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/connection/websocket", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// MaxBodySize middleware limits the size of request body. Requests with body larger
+// than limit get 413 response code with JSON error. WebSocket upgrade requests and
+// requests to exempt paths (for example, streaming endpoints) are not limited.
+type MaxBodySize struct {
+	limit        int64
+	exemptPrefix []string
+}
+
+// NewMaxBodySize creates MaxBodySize middleware. Requests which URL path starts with
+// one of exemptPathPrefixes are passed without limiting body size.
+func NewMaxBodySize(limit int64, exemptPathPrefixes ...string) *MaxBodySize {
+	return &MaxBodySize{limit: limit, exemptPrefix: exemptPathPrefixes}
+}
+
+func (m *MaxBodySize) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.limit <= 0 || isUpgradeRequest(r) || hasPathPrefix(r.URL.Path, m.exemptPrefix) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength > m.limit {
+			writeBodyTooLarge(w, m.limit)
+			return
+		}
+		body := &maxBodyReader{ReadCloser: http.MaxBytesReader(w, r.Body, m.limit)}
+		r.Body = body
+		mw := &maxBodyResponseWriter{ResponseWriter: w, body: body, limit: m.limit}
+		h.ServeHTTP(mw, r)
+		if !mw.wroteHeader && body.exceeded {
+			writeBodyTooLarge(w, m.limit)
+		}
+	})
+}
+
+func writeBodyTooLarge(w http.ResponseWriter, limit int64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	_, _ = w.Write([]byte(`{"error":"request body too large","limit":` + strconv.FormatInt(limit, 10) + `}`))
+}
+
+type maxBodyReader struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (r *maxBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			r.exceeded = true
+		}
+	}
+	return n, err
+}
+
+// maxBodyResponseWriter replaces response with 413 error if handler tries to respond
+// after request body limit was exceeded.
+type maxBodyResponseWriter struct {
+	http.ResponseWriter
+	body        *maxBodyReader
+	limit       int64
+	wroteHeader bool
+	rejected    bool
+}
+
+func (w *maxBodyResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.body.exceeded {
+		w.rejected = true
+		writeBodyTooLarge(w.ResponseWriter, w.limit)
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *maxBodyResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher.
+func (w *maxBodyResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap is required for http.ResponseController.
+func (w *maxBodyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
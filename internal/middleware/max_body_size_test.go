@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readBodyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(data)
+	})
+}
+
+func TestMaxBodySizeUnderLimit(t *testing.T) {
+	body := strings.Repeat("a", 100)
+	req := httptest.NewRequest(http.MethodPost, "/api", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	NewMaxBodySize(1024).Middleware(readBodyHandler()).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, body, rr.Body.String())
+}
+
+func TestMaxBodySizeContentLengthOverLimit(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api", strings.NewReader(strings.Repeat("a", 2048)))
+	rr := httptest.NewRecorder()
+	NewMaxBodySize(1024).Middleware(readBodyHandler()).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"error":"request body too large","limit":1024}`, rr.Body.String())
+}
+
+func TestMaxBodySizeChunkedOverLimit(t *testing.T) {
+	ts := httptest.NewServer(NewMaxBodySize(1024).Middleware(readBodyHandler()))
+	defer ts.Close()
+
+	// Hide body length from client so request is sent chunked.
+	body := io.MultiReader(bytes.NewReader(bytes.Repeat([]byte("a"), 2048)))
+	req, err := http.NewRequest(http.MethodPost, ts.URL, body)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"error":"request body too large","limit":1024}`, string(data))
+}
+
+func TestMaxBodySizeHandlerIgnoresError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api", strings.NewReader(strings.Repeat("a", 2048)))
+	req.ContentLength = -1
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+	})
+	NewMaxBodySize(1024).Middleware(handler).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
+
+func TestMaxBodySizeExemptPath(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+	req := httptest.NewRequest(http.MethodPost, "/connection/uni_http_stream", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	NewMaxBodySize(1024, "/connection/").Middleware(readBodyHandler()).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, body, rr.Body.String())
+}
+
+func TestMaxBodySizeWebSocketUpgrade(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/connection/websocket", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(*maxBodyResponseWriter)
+		require.False(t, ok)
+	})
+	NewMaxBodySize(1024).Middleware(handler).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
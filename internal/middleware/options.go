@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Options middleware responds to OPTIONS requests with 204 and Allow header listing
+// supported methods. CORS preflight requests are passed through to the next handler,
+// so Options should be placed before CORS middleware in chain.
+type Options struct {
+	allow string
+}
+
+// NewOptions creates Options middleware for a route supporting methods. OPTIONS method
+// is always included in Allow header.
+func NewOptions(methods []string) *Options {
+	allowed := make([]string, 0, len(methods)+1)
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		if !slices.Contains(allowed, method) {
+			allowed = append(allowed, method)
+		}
+	}
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return &Options{allow: strings.Join(allowed, ", ")}
+}
+
+// isPreflight reports whether request is a CORS preflight request.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+func (o *Options) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions || isPreflight(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Allow", o.allow)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsPlain(t *testing.T) {
+	var called bool
+	handler := NewOptions([]string{"post", http.MethodGet, http.MethodPost}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/api", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, "POST, GET, OPTIONS", rr.Header().Get("Allow"))
+	require.False(t, called)
+
+	req = httptest.NewRequest(http.MethodPost, "/api", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("Allow"))
+	require.True(t, called)
+}
+
+func TestOptionsCORSPreflightPassThrough(t *testing.T) {
+	var called bool
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	cors := NewCORSFromOrigins([]string{"https://example.com"})
+	handler := NewOptions([]string{http.MethodPost}).Middleware(cors.Middleware(final))
+
+	req := httptest.NewRequest(http.MethodOptions, "/api", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.True(t, called)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Empty(t, rr.Header().Get("Allow"))
+	require.Equal(t, "https://example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Content-Type", rr.Header().Get("Access-Control-Allow-Headers"))
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// OriginSanitizer middleware restores Origin header rewritten by APM agents (like ARMS probes)
+// to internal loopback or private address. When such Origin comes in request from trusted proxy
+// with X-Forwarded-Host and X-Forwarded-Proto headers set, Origin is replaced with forwarded
+// scheme and host. Must be placed before CORS middleware in chain.
+type OriginSanitizer struct {
+	trustedProxies []netip.Prefix
+}
+
+func NewOriginSanitizer(trustedProxies []string) (*OriginSanitizer, error) {
+	prefixes, err := parsePrefixes(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trusted proxies: %w", err)
+	}
+	return &OriginSanitizer{trustedProxies: prefixes}, nil
+}
+
+func (m *OriginSanitizer) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin, ok := m.forwardedOrigin(r); ok {
+			r.Header.Set("Origin", origin)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// forwardedOrigin returns origin built from forwarded headers if request Origin must be replaced.
+func (m *OriginSanitizer) forwardedOrigin(r *http.Request) (string, bool) {
+	if !isInternalOrigin(r.Header.Get("Origin")) {
+		return "", false
+	}
+	remoteIP, ok := parseIP(r.RemoteAddr)
+	if !ok || !prefixesContain(m.trustedProxies, remoteIP) {
+		return "", false
+	}
+	proto := strings.ToLower(firstHeaderValue(r.Header.Get("X-Forwarded-Proto")))
+	host := firstHeaderValue(r.Header.Get("X-Forwarded-Host"))
+	if (proto != "http" && proto != "https") || host == "" {
+		return "", false
+	}
+	origin := proto + "://" + host
+	if _, ok := normalizeOrigin(origin); !ok {
+		return "", false
+	}
+	return origin, true
+}
+
+// isInternalOrigin reports whether origin host is localhost or loopback, private or
+// unspecified IP address.
+func isInternalOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip, ok := parseIP(host)
+	if !ok {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified()
+}
+
+// firstHeaderValue returns the first element of comma-separated header value, i.e. the one
+// set by proxy closest to client.
+func firstHeaderValue(value string) string {
+	value, _, _ = strings.Cut(value, ",")
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sanitizedCORSRequest(t *testing.T, remoteAddr string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	s, err := NewOriginSanitizer([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	c := NewCORSFromOrigins([]string{"https://app.example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	s.Middleware(c.Middleware(testHandler())).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestOriginSanitizerAPMRewrittenOrigin(t *testing.T) {
+	// APM probe replaced browser Origin with loopback address, proxy in front of Centrifugo
+	// still passes original host and scheme.
+	for _, origin := range []string{"http://127.0.0.1", "http://127.0.0.1:8080", "http://localhost:3000", "http://192.168.1.10", "http://[::1]:8000"} {
+		rr := sanitizedCORSRequest(t, "10.1.2.3:5000", map[string]string{
+			"Origin":            origin,
+			"X-Forwarded-Host":  "app.example.com",
+			"X-Forwarded-Proto": "https",
+		})
+		require.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"), origin)
+		require.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"), origin)
+	}
+
+	// Without sanitizer CORS rejects such request.
+	rr := corsRequest(t, NewCORSFromOrigins([]string{"https://app.example.com"}), "http://127.0.0.1")
+	require.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestOriginSanitizerMultipleForwardedValues(t *testing.T) {
+	rr := sanitizedCORSRequest(t, "10.1.2.3:5000", map[string]string{
+		"Origin":            "http://127.0.0.1",
+		"X-Forwarded-Host":  "app.example.com, internal.lb",
+		"X-Forwarded-Proto": "HTTPS, http",
+	})
+	require.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestOriginSanitizerNoop(t *testing.T) {
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		header     map[string]string
+	}{
+		{
+			name:       "untrusted proxy",
+			remoteAddr: "203.0.113.1:5000",
+			header:     map[string]string{"Origin": "http://127.0.0.1", "X-Forwarded-Host": "app.example.com", "X-Forwarded-Proto": "https"},
+		},
+		{
+			name:       "no forwarded host",
+			remoteAddr: "10.1.2.3:5000",
+			header:     map[string]string{"Origin": "http://127.0.0.1", "X-Forwarded-Proto": "https"},
+		},
+		{
+			name:       "no forwarded proto",
+			remoteAddr: "10.1.2.3:5000",
+			header:     map[string]string{"Origin": "http://127.0.0.1", "X-Forwarded-Host": "app.example.com"},
+		},
+		{
+			name:       "unsupported forwarded proto",
+			remoteAddr: "10.1.2.3:5000",
+			header:     map[string]string{"Origin": "http://127.0.0.1", "X-Forwarded-Host": "app.example.com", "X-Forwarded-Proto": "ws"},
+		},
+		{
+			name:       "malformed forwarded host",
+			remoteAddr: "10.1.2.3:5000",
+			header:     map[string]string{"Origin": "http://127.0.0.1", "X-Forwarded-Host": "app.example.com/path", "X-Forwarded-Proto": "https"},
+		},
+		{
+			name:       "public origin",
+			remoteAddr: "10.1.2.3:5000",
+			header:     map[string]string{"Origin": "https://evil.example.com", "X-Forwarded-Host": "app.example.com", "X-Forwarded-Proto": "https"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := sanitizedCORSRequest(t, tc.remoteAddr, tc.header)
+			require.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
+
+func TestOriginSanitizerKeepsOrigin(t *testing.T) {
+	s, err := NewOriginSanitizer([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	var origin string
+	h := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin = r.Header.Get("Origin")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:5000"
+	req.Header.Set("Origin", "http://127.0.0.1")
+	req.Header.Set("X-Forwarded-Host", "app.example.com")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, "http://127.0.0.1", origin)
+}
+
+func TestNewOriginSanitizerInvalidPrefix(t *testing.T) {
+	_, err := NewOriginSanitizer([]string{"not-a-cidr"})
+	require.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type contextClientIPKey struct{}
+
+// ClientIPFromContext returns client IP resolved by RealIP middleware.
+func ClientIPFromContext(ctx context.Context) (netip.Addr, bool) {
+	if val := ctx.Value(contextClientIPKey{}); val != nil {
+		ip, ok := val.(netip.Addr)
+		return ip, ok
+	}
+	return netip.Addr{}, false
+}
+
+func SetClientIPToContext(ctx context.Context, ip netip.Addr) context.Context {
+	return context.WithValue(ctx, contextClientIPKey{}, ip)
+}
+
+// RealIP middleware resolves real client IP address and puts it to request context
+// so downstream middlewares can reuse it with ClientIPFromContext. X-Forwarded-For
+// header is only taken into account when request came from trusted proxy.
+type RealIP struct {
+	trustedProxies []netip.Prefix
+}
+
+func NewRealIP(trustedProxies []string) (*RealIP, error) {
+	prefixes, err := parsePrefixes(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trusted proxies: %w", err)
+	}
+	return &RealIP{trustedProxies: prefixes}, nil
+}
+
+func (m *RealIP) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := resolveClientIP(r, m.trustedProxies); ok {
+			r = r.WithContext(SetClientIPToContext(r.Context(), ip))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// resolveClientIP returns client IP address of request. X-Forwarded-For header is
+// walked from the right only when request came from a trusted proxy, the first
+// address not belonging to trusted proxies is considered a client IP.
+func resolveClientIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	remoteIP, ok := parseIP(r.RemoteAddr)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	if !prefixesContain(trustedProxies, remoteIP) {
+		return remoteIP, true
+	}
+	values := r.Header.Values("X-Forwarded-For")
+	for i := len(values) - 1; i >= 0; i-- {
+		hops := strings.Split(values[i], ",")
+		for j := len(hops) - 1; j >= 0; j-- {
+			ip, ok := parseIP(strings.TrimSpace(hops[j]))
+			if !ok {
+				// Can not trust anything to the left of malformed value.
+				return remoteIP, true
+			}
+			if !prefixesContain(trustedProxies, ip) {
+				return ip, true
+			}
+			remoteIP = ip
+		}
+	}
+	return remoteIP, true
+}
+
+// parseIP parses IP address with optional port.
+func parseIP(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func prefixesContain(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRealIPMalformedCIDR(t *testing.T) {
+	_, err := NewRealIP([]string{"10.0.0.0/40"})
+	require.Error(t, err)
+}
+
+func TestRealIP(t *testing.T) {
+	realIP, err := NewRealIP([]string{"10.0.0.0/8", "fd00::/8"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  []string
+		expectedIP    string
+		expectedFound bool
+	}{
+		{
+			name:          "no forwarded header",
+			remoteAddr:    "203.0.113.1:1234",
+			expectedIP:    "203.0.113.1",
+			expectedFound: true,
+		},
+		{
+			name:          "untrusted remote ignores forwarded header",
+			remoteAddr:    "203.0.113.1:1234",
+			forwardedFor:  []string{"198.51.100.1"},
+			expectedIP:    "203.0.113.1",
+			expectedFound: true,
+		},
+		{
+			name:          "single trusted hop",
+			remoteAddr:    "10.0.0.1:1234",
+			forwardedFor:  []string{"198.51.100.1"},
+			expectedIP:    "198.51.100.1",
+			expectedFound: true,
+		},
+		{
+			name:          "multi-hop stops at first untrusted",
+			remoteAddr:    "10.0.0.1:1234",
+			forwardedFor:  []string{"192.0.2.55, 198.51.100.1, 10.0.0.2, 10.0.0.3"},
+			expectedIP:    "198.51.100.1",
+			expectedFound: true,
+		},
+		{
+			name:          "multiple header values",
+			remoteAddr:    "10.0.0.1:1234",
+			forwardedFor:  []string{"192.0.2.55, 198.51.100.1", "10.0.0.2"},
+			expectedIP:    "198.51.100.1",
+			expectedFound: true,
+		},
+		{
+			name:          "all hops trusted",
+			remoteAddr:    "10.0.0.1:1234",
+			forwardedFor:  []string{"10.0.0.3, 10.0.0.2"},
+			expectedIP:    "10.0.0.3",
+			expectedFound: true,
+		},
+		{
+			name:          "malformed hop",
+			remoteAddr:    "10.0.0.1:1234",
+			forwardedFor:  []string{"198.51.100.1, garbage, 10.0.0.2"},
+			expectedIP:    "10.0.0.2",
+			expectedFound: true,
+		},
+		{
+			name:          "ipv6",
+			remoteAddr:    "[fd00::1]:1234",
+			forwardedFor:  []string{"2001:db8::1, fd00::2"},
+			expectedIP:    "2001:db8::1",
+			expectedFound: true,
+		},
+		{
+			name:          "ipv6 untrusted remote",
+			remoteAddr:    "[2001:db8::2]:1234",
+			forwardedFor:  []string{"2001:db8::1"},
+			expectedIP:    "2001:db8::2",
+			expectedFound: true,
+		},
+		{
+			name:          "malformed remote addr",
+			remoteAddr:    "bad",
+			expectedFound: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, v := range tc.forwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			var called bool
+			handler := realIP.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				ip, ok := ClientIPFromContext(r.Context())
+				require.Equal(t, tc.expectedFound, ok)
+				if tc.expectedFound {
+					require.Equal(t, tc.expectedIP, ip.String())
+				}
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			require.True(t, called)
+		})
+	}
+}
+
+func TestRealIPUsedByIPFilter(t *testing.T) {
+	realIP, err := NewRealIP([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	// IPFilter itself does not trust any proxy, but reuses IP resolved by RealIP.
+	filter, err := NewIPFilter(IPFilterConfig{Allow: []string{"198.51.100.0/24"}})
+	require.NoError(t, err)
+	handler := realIP.Middleware(filter.Middleware(testHandler()))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
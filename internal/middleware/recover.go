@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	httpHandlerPanics prometheus.Counter
+)
+
+func init() {
+	httpHandlerPanics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "centrifugo",
+		Subsystem: "node",
+		Name:      "http_handler_panics_total",
+		Help:      "Number of panics recovered in HTTP handlers.",
+	})
+	_ = prometheus.DefaultRegisterer.Register(httpHandlerPanics)
+}
+
+// Recover middleware recovers panics in HTTP handlers, logs them with stack trace
+// and responds with 500 status code if response was not started yet. Panics with
+// http.ErrAbortHandler are propagated to preserve net/http semantics.
+type Recover struct{}
+
+func NewRecover() *Recover {
+	return &Recover{}
+}
+
+func (m *Recover) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoverResponseWriter{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+			httpHandlerPanics.Inc()
+			log.Error().Str("method", r.Method).Str("path", r.URL.Path).
+				Str("panic", fmt.Sprintf("%v", rec)).Bytes("stack", debug.Stack()).
+				Bool("headers_sent", rw.wroteHeader).Msg("panic in HTTP handler")
+			if !rw.wroteHeader && !rw.hijacked {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		h.ServeHTTP(rw, r)
+	})
+}
+
+type recoverResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *recoverResponseWriter) WriteHeader(status int) {
+	if status >= http.StatusOK || status == http.StatusSwitchingProtocols {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoverResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Hijack as we need it for Websocket.
+func (w *recoverResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("ResponseWriter doesn't support Hijacker interface")
+	}
+	w.hijacked = true
+	return hijacker.Hijack()
+}
+
+// Flush implements http.Flusher.
+func (w *recoverResponseWriter) Flush() {
+	w.wroteHeader = true
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap is required for http.ResponseController.
+func (w *recoverResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
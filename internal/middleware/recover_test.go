@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	handler := NewRecover().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	before := testutil.ToFloat64(httpHandlerPanics)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := httptest.NewRecorder()
+	require.NotPanics(t, func() {
+		handler.ServeHTTP(rr, req)
+	})
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Equal(t, before+1, testutil.ToFloat64(httpHandlerPanics))
+}
+
+func TestRecoverPanicAfterHeadersSent(t *testing.T) {
+	handler := NewRecover().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		panic("boom")
+	}))
+	before := testutil.ToFloat64(httpHandlerPanics)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	require.Equal(t, "partial", rr.Body.String())
+	require.Equal(t, before+1, testutil.ToFloat64(httpHandlerPanics))
+}
+
+func TestRecoverErrAbortHandlerPropagates(t *testing.T) {
+	handler := NewRecover().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	before := testutil.ToFloat64(httpHandlerPanics)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := httptest.NewRecorder()
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(rr, req)
+	})
+	require.Equal(t, before, testutil.ToFloat64(httpHandlerPanics))
+}
+
+func TestRecoverNoPanic(t *testing.T) {
+	handler := NewRecover().Middleware(testHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
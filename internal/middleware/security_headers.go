@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SecurityHeadersConfig configures SecurityHeaders middleware. Zero value of
+// string option means using default value, "-" disables header.
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge is a max-age of Strict-Transport-Security header. Zero value means
+	// default of one year, negative value disables header.
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains adds includeSubDomains directive.
+	HSTSIncludeSubdomains bool
+	// HSTSForce emits Strict-Transport-Security header also for plaintext connections.
+	// This may be useful when TLS is terminated by a load balancer in front of Centrifugo.
+	HSTSForce bool
+	// DisableContentTypeOptions disables X-Content-Type-Options: nosniff header.
+	DisableContentTypeOptions bool
+	// FrameOptions is a value of X-Frame-Options header. Default is DENY.
+	FrameOptions string
+	// ReferrerPolicy is a value of Referrer-Policy header. Default is no-referrer.
+	ReferrerPolicy string
+	// ContentSecurityPolicy is a value of Content-Security-Policy header. Not set by default.
+	ContentSecurityPolicy string
+}
+
+const disabledSecurityHeader = "-"
+
+const defaultHSTSMaxAge = 365 * 24 * time.Hour
+
+// SecurityHeaders middleware sets common security related response headers. Headers
+// are set right before response is written, headers set by handler (with Set or Add) or by
+// outer middleware take precedence and are not overwritten.
+type SecurityHeaders struct {
+	hsts      string
+	hstsForce bool
+	headers   [][2]string
+}
+
+func NewSecurityHeaders(cfg SecurityHeadersConfig) *SecurityHeaders {
+	s := &SecurityHeaders{hstsForce: cfg.HSTSForce}
+	maxAge := cfg.HSTSMaxAge
+	if maxAge == 0 {
+		maxAge = defaultHSTSMaxAge
+	}
+	if maxAge > 0 {
+		s.hsts = "max-age=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+		if cfg.HSTSIncludeSubdomains {
+			s.hsts += "; includeSubDomains"
+		}
+	}
+	if !cfg.DisableContentTypeOptions {
+		s.headers = append(s.headers, [2]string{"X-Content-Type-Options", "nosniff"})
+	}
+	if value := securityHeaderValue(cfg.FrameOptions, "DENY"); value != "" {
+		s.headers = append(s.headers, [2]string{"X-Frame-Options", value})
+	}
+	if value := securityHeaderValue(cfg.ReferrerPolicy, "no-referrer"); value != "" {
+		s.headers = append(s.headers, [2]string{"Referrer-Policy", value})
+	}
+	if value := securityHeaderValue(cfg.ContentSecurityPolicy, ""); value != "" {
+		s.headers = append(s.headers, [2]string{"Content-Security-Policy", value})
+	}
+	return s
+}
+
+func securityHeaderValue(value string, defaultValue string) string {
+	if value == disabledSecurityHeader {
+		return ""
+	}
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func (s *SecurityHeaders) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &securityHeadersResponseWriter{
+			ResponseWriter: w,
+			security:       s,
+			hsts:           s.hsts != "" && (r.TLS != nil || s.hstsForce),
+		}
+		h.ServeHTTP(sw, r)
+		// Response without WriteHeader and Write calls is sent after handler returns.
+		if !sw.hijacked {
+			sw.apply()
+		}
+	})
+}
+
+// securityHeadersResponseWriter sets security headers right before response headers are
+// sent – so that headers set by handler with Set or Add take precedence and are not
+// duplicated.
+type securityHeadersResponseWriter struct {
+	http.ResponseWriter
+	security *SecurityHeaders
+	hsts     bool
+	applied  bool
+	hijacked bool
+}
+
+func (w *securityHeadersResponseWriter) apply() {
+	if w.applied {
+		return
+	}
+	w.applied = true
+	header := w.ResponseWriter.Header()
+	if w.hsts {
+		setHeaderIfMissing(header, "Strict-Transport-Security", w.security.hsts)
+	}
+	for _, kv := range w.security.headers {
+		setHeaderIfMissing(header, kv[0], kv[1])
+	}
+}
+
+func (w *securityHeadersResponseWriter) WriteHeader(status int) {
+	w.apply()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *securityHeadersResponseWriter) Write(p []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(p)
+}
+
+// Hijack as we need it for Websocket.
+func (w *securityHeadersResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("ResponseWriter doesn't support Hijacker interface")
+	}
+	w.hijacked = true
+	return hijacker.Hijack()
+}
+
+// Flush implements http.Flusher.
+func (w *securityHeadersResponseWriter) Flush() {
+	w.apply()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap is required for http.ResponseController.
+func (w *securityHeadersResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func setHeaderIfMissing(header http.Header, key string, value string) {
+	if _, ok := header[http.CanonicalHeaderKey(key)]; ok {
+		return
+	}
+	header.Set(key, value)
+}
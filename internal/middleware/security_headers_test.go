@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeadersDefaults(t *testing.T) {
+	handler := NewSecurityHeaders(SecurityHeadersConfig{}).Middleware(testHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, "max-age=31536000", rr.Header().Get("Strict-Transport-Security"))
+	require.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", rr.Header().Get("X-Frame-Options"))
+	require.Equal(t, "no-referrer", rr.Header().Get("Referrer-Policy"))
+	require.Empty(t, rr.Header().Get("Content-Security-Policy"))
+}
+
+func TestSecurityHeadersHSTSPlaintext(t *testing.T) {
+	handler := NewSecurityHeaders(SecurityHeadersConfig{}).Middleware(testHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Empty(t, rr.Header().Get("Strict-Transport-Security"))
+	require.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+
+	handler = NewSecurityHeaders(SecurityHeadersConfig{
+		HSTSForce:             true,
+		HSTSMaxAge:            time.Hour,
+		HSTSIncludeSubdomains: true,
+	}).Middleware(testHandler())
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, "max-age=3600; includeSubDomains", rr.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeadersCustom(t *testing.T) {
+	handler := NewSecurityHeaders(SecurityHeadersConfig{
+		HSTSMaxAge:                -1,
+		DisableContentTypeOptions: true,
+		FrameOptions:              "-",
+		ReferrerPolicy:            "same-origin",
+		ContentSecurityPolicy:     "default-src 'self'",
+	}).Middleware(testHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Empty(t, rr.Header().Get("Strict-Transport-Security"))
+	require.Empty(t, rr.Header().Get("X-Content-Type-Options"))
+	require.Empty(t, rr.Header().Get("X-Frame-Options"))
+	require.Equal(t, "same-origin", rr.Header().Get("Referrer-Policy"))
+	require.Equal(t, "default-src 'self'", rr.Header().Get("Content-Security-Policy"))
+}
+
+func TestSecurityHeadersHandlerSetHeaderRespected(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	})
+	outer := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Referrer-Policy", "origin")
+			h.ServeHTTP(w, r)
+		})
+	}
+	handler := outer(NewSecurityHeaders(SecurityHeadersConfig{}).Middleware(inner))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, []string{"SAMEORIGIN"}, rr.Header().Values("X-Frame-Options"))
+	require.Equal(t, []string{"origin"}, rr.Header().Values("Referrer-Policy"))
+}
+
+func TestSecurityHeadersHandlerAddHeaderNotDuplicated(t *testing.T) {
+	for name, inner := range map[string]http.HandlerFunc{
+		"write header": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Frame-Options", "SAMEORIGIN")
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"write": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Frame-Options", "SAMEORIGIN")
+			_, _ = w.Write([]byte("ok"))
+		},
+		"flush": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Frame-Options", "SAMEORIGIN")
+			require.NoError(t, http.NewResponseController(w).Flush())
+		},
+		"implicit": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Frame-Options", "SAMEORIGIN")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := NewSecurityHeaders(SecurityHeadersConfig{}).Middleware(inner)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, []string{"SAMEORIGIN"}, rr.Header().Values("X-Frame-Options"))
+			require.Equal(t, []string{"nosniff"}, rr.Header().Values("X-Content-Type-Options"))
+			// Headers are sent with response, not added after it.
+			require.Equal(t, []string{"nosniff"}, rr.Result().Header.Values("X-Content-Type-Options"))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Timeout middleware limits the time of request processing. Request context is
+// cancelled upon timeout and client gets 503 response code. Long-lived connections
+// (WebSocket upgrades, SSE requests) and requests to exempt paths never time out.
+type Timeout struct {
+	timeout      time.Duration
+	exemptPrefix []string
+}
+
+// NewTimeout creates Timeout middleware. Requests which URL path starts with one of
+// exemptPathPrefixes are passed without timeout.
+func NewTimeout(d time.Duration, exemptPathPrefixes ...string) *Timeout {
+	return &Timeout{timeout: d, exemptPrefix: exemptPathPrefixes}
+}
+
+func (m *Timeout) Middleware(h http.Handler) http.Handler {
+	timeoutHandler := http.TimeoutHandler(h, m.timeout, http.StatusText(http.StatusServiceUnavailable))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.timeout <= 0 || isUpgradeRequest(r) || isEventStreamRequest(r) || hasPathPrefix(r.URL.Path, m.exemptPrefix) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		timeoutHandler.ServeHTTP(w, r)
+	})
+}
+
+func isEventStreamRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// hasPathPrefix checks whether path starts with one of prefixes.
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func slowHandler(d time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(d):
+			_, _ = w.Write([]byte("done"))
+		case <-r.Context().Done():
+		}
+	})
+}
+
+func TestTimeoutFastHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api", nil)
+	rr := httptest.NewRecorder()
+	NewTimeout(time.Second).Middleware(slowHandler(0)).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "done", rr.Body.String())
+}
+
+func TestTimeoutSlowHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api", nil)
+	rr := httptest.NewRecorder()
+	NewTimeout(50*time.Millisecond).Middleware(slowHandler(5*time.Second)).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+func TestTimeoutSlowHandlerContextCancelled(t *testing.T) {
+	cancelled := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(cancelled)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api", nil)
+	rr := httptest.NewRecorder()
+	NewTimeout(50*time.Millisecond).Middleware(handler).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		require.Fail(t, "handler context not cancelled")
+	}
+}
+
+func TestTimeoutExempt(t *testing.T) {
+	testCases := []struct {
+		name    string
+		path    string
+		headers map[string]string
+	}{
+		{"websocket upgrade", "/connection/websocket", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"}},
+		{"sse", "/connection/uni_sse", map[string]string{"Accept": "text/event-stream"}},
+		{"exempt path", "/connection/uni_http_stream", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			NewTimeout(20*time.Millisecond, "/connection/uni_http_stream").Middleware(slowHandler(100*time.Millisecond)).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, "done", rr.Body.String())
+		})
+	}
+}
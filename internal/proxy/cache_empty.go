@@ -2,6 +2,8 @@ package proxy
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
 )
@@ -16,3 +18,45 @@ type CacheEmptyProxy interface {
 	// IncludeMeta ...
 	IncludeMeta() bool
 }
+
+// CacheEmptyInterceptor allows attaching custom behaviour around ProxyCacheEmpty calls of
+// HTTP and GRPC cache empty proxies. Both hooks are optional.
+type CacheEmptyInterceptor struct {
+	// BeforeRequest is called before request is sent to backend. Returned context is used
+	// for the call. Returning error aborts the call, AfterResponse is not called in this case.
+	BeforeRequest func(context.Context, *proxyproto.NotifyCacheEmptyRequest) (context.Context, error)
+	// AfterResponse is called with backend response or call error.
+	AfterResponse func(context.Context, *proxyproto.NotifyCacheEmptyResponse, error)
+}
+
+func (i CacheEmptyInterceptor) intercept(
+	ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest,
+	call func(context.Context, *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error),
+) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	if i.BeforeRequest != nil {
+		var err error
+		ctx, err = i.BeforeRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp, err := call(ctx, req)
+	if i.AfterResponse != nil {
+		i.AfterResponse(ctx, resp, err)
+	}
+	return resp, err
+}
+
+// ErrCacheEmptyBatchNotSupported is returned by CacheEmptyBatchProxy when backend does not
+// support batch requests.
+var ErrCacheEmptyBatchNotSupported = errors.New("cache empty batch not supported by backend")
+
+// CacheEmptyBatchProxy may be additionally implemented by CacheEmptyProxy to send
+// NotifyCacheEmptyBatch requests.
+type CacheEmptyBatchProxy interface {
+	ProxyCacheEmptyBatch(context.Context, *proxyproto.NotifyCacheEmptyBatchRequest) (*proxyproto.NotifyCacheEmptyBatchResponse, error)
+	// BatchWindow to collect channels into one batch, zero disables batching.
+	BatchWindow() time.Duration
+	// BatchMaxSize is a maximum number of channels in one batch.
+	BatchMaxSize() int
+}
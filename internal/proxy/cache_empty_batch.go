@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+)
+
+const defaultCacheEmptyBatchMaxSize = 100
+
+// cacheEmptyBatch is a set of channels sent to backend in one NotifyCacheEmptyBatch call.
+type cacheEmptyBatch struct {
+	channels []string
+	seen     map[string]struct{}
+	// populated and err are set before done is closed.
+	populated map[string]bool
+	err       error
+	done      chan struct{}
+}
+
+// cacheEmptyBatcher coalesces channels observed within a window into one batch call to proxy.
+type cacheEmptyBatcher struct {
+	proxy    CacheEmptyBatchProxy
+	counters *cacheEmptyProxyCounters
+	window   time.Duration
+	maxSize  int
+	// unsupported is set once backend reported it does not support batching.
+	unsupported atomic.Bool
+
+	mu      sync.Mutex
+	current *cacheEmptyBatch
+}
+
+func newCacheEmptyBatcher(p CacheEmptyBatchProxy, counters *cacheEmptyProxyCounters) *cacheEmptyBatcher {
+	maxSize := p.BatchMaxSize()
+	if maxSize <= 0 {
+		maxSize = defaultCacheEmptyBatchMaxSize
+	}
+	return &cacheEmptyBatcher{
+		proxy:    p,
+		counters: counters,
+		window:   p.BatchWindow(),
+		maxSize:  maxSize,
+	}
+}
+
+// notify adds channel to the current batch and waits for batch result.
+func (b *cacheEmptyBatcher) notify(ctx context.Context, channel string) (bool, error) {
+	batch := b.add(channel)
+	select {
+	case <-batch.done:
+		if batch.err != nil {
+			return false, batch.err
+		}
+		return batch.populated[channel], nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (b *cacheEmptyBatcher) add(channel string) *cacheEmptyBatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batch := b.current
+	if batch == nil {
+		batch = &cacheEmptyBatch{
+			seen: map[string]struct{}{},
+			done: make(chan struct{}),
+		}
+		b.current = batch
+		time.AfterFunc(b.window, func() { b.flush(batch) })
+	}
+	if _, ok := batch.seen[channel]; !ok {
+		batch.seen[channel] = struct{}{}
+		batch.channels = append(batch.channels, channel)
+	}
+	if len(batch.channels) >= b.maxSize {
+		b.current = nil
+		go b.send(batch)
+	}
+	return batch
+}
+
+// flush sends batch upon window expiration if it was not sent due to reaching max size.
+func (b *cacheEmptyBatcher) flush(batch *cacheEmptyBatch) {
+	b.mu.Lock()
+	if b.current != batch {
+		b.mu.Unlock()
+		return
+	}
+	b.current = nil
+	b.mu.Unlock()
+	b.send(batch)
+}
+
+func (b *cacheEmptyBatcher) send(batch *cacheEmptyBatch) {
+	defer close(batch.done)
+	// Batch is shared by many callers, so it is not bound to context of any of them – proxy
+	// applies its own timeout.
+	resp, err := b.proxy.ProxyCacheEmptyBatch(context.Background(), &proxyproto.NotifyCacheEmptyBatchRequest{
+		Channels: batch.channels,
+	})
+	if errors.Is(err, ErrCacheEmptyBatchNotSupported) {
+		b.unsupported.Store(true)
+	} else {
+		b.counters.record(err)
+	}
+	if err != nil {
+		batch.err = err
+		return
+	}
+	batch.populated = make(map[string]bool, len(resp.GetResults()))
+	for _, r := range resp.GetResults() {
+		if _, ok := batch.seen[r.Channel]; ok {
+			batch.populated[r.Channel] = r.Populated
+		}
+	}
+}
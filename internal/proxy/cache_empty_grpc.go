@@ -2,46 +2,169 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
 
-	"google.golang.org/grpc"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // GRPCCacheEmptyProxy ...
 type GRPCCacheEmptyProxy struct {
-	config Config
-	client proxyproto.CentrifugoProxyClient
+	name    string
+	config  Config
+	conns   *weightedGRPCConns
+	log     *zerolog.Logger
+	limiter callLimiter
+
+	interceptor *CacheEmptyInterceptor
 }
 
 var _ CacheEmptyProxy = (*GRPCCacheEmptyProxy)(nil)
+var _ CacheEmptyBatchProxy = (*GRPCCacheEmptyProxy)(nil)
+var _ WarmupProxy = (*GRPCCacheEmptyProxy)(nil)
 
-// NewGRPCCacheEmptyProxy ...
+// NewGRPCCacheEmptyProxy ... If GRPC.Endpoints configured then calls are balanced between
+// them using weighted round-robin. With GRPC.EagerConnect option returns ErrGRPCConnNotReady
+// if connections are not ready within GRPC.EagerConnectTimeout.
 func NewGRPCCacheEmptyProxy(name string, p Config) (*GRPCCacheEmptyProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	conns, err := newWeightedGRPCConns(name, p)
 	if err != nil {
-		return nil, fmt.Errorf("error getting grpc host: %v", err)
+		return nil, err
 	}
-	dialOpts, err := getDialOpts(name, p)
-	if err != nil {
-		return nil, fmt.Errorf("error creating GRPC dial options: %v", err)
-	}
-	conn, err := grpc.NewClient(host, dialOpts...)
-	if err != nil {
-		return nil, fmt.Errorf("error connecting to GRPC proxy server: %v", err)
+	if p.GRPC.EagerConnect {
+		timeout := p.GRPC.EagerConnectTimeout.ToDuration()
+		if timeout <= 0 {
+			timeout = p.Timeout.ToDuration()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := conns.waitReady(ctx)
+		cancel()
+		if err != nil {
+			_ = conns.Close()
+			return nil, err
+		}
 	}
 	return &GRPCCacheEmptyProxy{
-		config: p,
-		client: proxyproto.NewCentrifugoProxyClient(conn),
+		name:    name,
+		config:  p,
+		conns:   conns,
+		log:     newProxyLogger(p),
+		limiter: newCallLimiter(p.MaxConcurrency),
 	}, nil
 }
 
-// ProxyCacheEmpty proxies NotifyCacheEmpty to application backend.
+// Close closes underlying GRPC connections.
+func (p *GRPCCacheEmptyProxy) Close() error {
+	return p.conns.Close()
+}
+
+// Warmup connects to all endpoints and waits until connections are READY.
+func (p *GRPCCacheEmptyProxy) Warmup(ctx context.Context) error {
+	return p.conns.waitReady(ctx)
+}
+
+// SetInterceptor sets hooks called around ProxyCacheEmpty. Must be called before proxy is used.
+func (p *GRPCCacheEmptyProxy) SetInterceptor(i CacheEmptyInterceptor) {
+	p.interceptor = &i
+}
+
+// ProxyCacheEmpty proxies NotifyCacheEmpty to application backend. With GRPC.Streaming option
+// NotifyCacheEmptyStream is called instead.
 func (p *GRPCCacheEmptyProxy) ProxyCacheEmpty(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
-	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout.ToDuration())
+	if p.interceptor == nil {
+		return p.proxyCacheEmpty(ctx, req)
+	}
+	return p.interceptor.intercept(ctx, req, p.proxyCacheEmpty)
+}
+
+func (p *GRPCCacheEmptyProxy) proxyCacheEmpty(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (_ *proxyproto.NotifyCacheEmptyResponse, err error) {
+	ctx, span := startProxySpan(ctx, p.config, p.name, p.Protocol(), "cache_empty", req.Channel)
+	defer func() { endProxySpan(span, err) }()
+	defer func() { err = newProxyError(p.name, p.Protocol(), err, nil) }()
+
+	if err := p.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer p.limiter.release()
+	return retryCall(ctx, p.config, isRetryableError(nil), func(ctx context.Context) (resp *proxyproto.NotifyCacheEmptyResponse, err error) {
+		ctx, cancel := proxyCallContext(ctx, p.config)
+		defer cancel()
+		started := time.Now()
+		if p.config.GRPC.Streaming {
+			resp, err = p.proxyCacheEmptyStream(ctx, req)
+		} else {
+			resp, err = p.conns.pick().NotifyCacheEmpty(injectGRPCTraceContext(grpcRequestContext(ctx, p.config)), req)
+		}
+		observeCallLatency(ctx, p.Protocol(), started, err)
+		logSlowCall(p, p.config, p.name, req.Channel, time.Since(started))
+		// Transformed statuses are application errors, so not retried.
+		err = transformCacheEmptyGRPCError(err, p.config.GRPC.StatusToCodeTransforms)
+		observeCallError(p.Protocol(), err, nil)
+		return resp, err
+	})
+}
+
+// proxyCacheEmptyStream consumes NotifyCacheEmptyStream progress messages. Returns as soon as
+// backend reports populated result (stream is closed then) or the last received message upon
+// stream end.
+func (p *GRPCCacheEmptyProxy) proxyCacheEmptyStream(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	stream, err := p.conns.pick().NotifyCacheEmptyStream(injectGRPCTraceContext(grpcRequestContext(ctx, p.config)), req)
+	if err != nil {
+		return nil, err
+	}
+	last := &proxyproto.NotifyCacheEmptyResponse{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return last, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.GetResult().GetPopulated() {
+			return resp, nil
+		}
+		last = resp
+	}
+}
+
+// ProxyCacheEmptyBatch proxies NotifyCacheEmptyBatch to application backend. Returns
+// ErrCacheEmptyBatchNotSupported if backend does not implement CentrifugoProxyBatch service.
+func (p *GRPCCacheEmptyProxy) ProxyCacheEmptyBatch(ctx context.Context, req *proxyproto.NotifyCacheEmptyBatchRequest) (_ *proxyproto.NotifyCacheEmptyBatchResponse, err error) {
+	ctx, span := startProxySpan(ctx, p.config, p.name, p.Protocol(), "cache_empty_batch", "")
+	defer func() { endProxySpan(span, err) }()
+	defer func() { err = newProxyError(p.name, p.Protocol(), err, nil) }()
+
+	if err := p.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer p.limiter.release()
+	ctx, cancel := proxyCallContext(ctx, p.config)
 	defer cancel()
-	return p.client.NotifyCacheEmpty(grpcRequestContext(ctx, p.config), req)
+	started := time.Now()
+	resp, err := p.conns.pickConn().batchClient.NotifyCacheEmptyBatch(injectGRPCTraceContext(grpcRequestContext(ctx, p.config)), req)
+	observeCallLatency(ctx, p.Protocol(), started, err)
+	observeCallError(p.Protocol(), err, nil)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, fmt.Errorf("%w: %v", ErrCacheEmptyBatchNotSupported, err)
+	}
+	return resp, err
+}
+
+// BatchWindow ...
+func (p *GRPCCacheEmptyProxy) BatchWindow() time.Duration {
+	return p.config.GRPC.BatchWindow.ToDuration()
+}
+
+// BatchMaxSize ...
+func (p *GRPCCacheEmptyProxy) BatchMaxSize() int {
+	return p.config.GRPC.BatchMaxSize
 }
 
 // Protocol ...
@@ -58,3 +181,11 @@ func (p *GRPCCacheEmptyProxy) UseBase64() bool {
 func (p *GRPCCacheEmptyProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *GRPCCacheEmptyProxy) logger() *zerolog.Logger {
+	return p.log
+}
+
+func (p *GRPCCacheEmptyProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
@@ -0,0 +1,656 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/middleware"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type cacheEmptyBatchServer struct {
+	proxyproto.UnimplementedCentrifugoProxyBatchServer
+}
+
+func (s *cacheEmptyBatchServer) NotifyCacheEmptyBatch(_ context.Context, req *proxyproto.NotifyCacheEmptyBatchRequest) (*proxyproto.NotifyCacheEmptyBatchResponse, error) {
+	resp := &proxyproto.NotifyCacheEmptyBatchResponse{}
+	for i, ch := range req.Channels {
+		resp.Results = append(resp.Results, &proxyproto.NotifyCacheEmptyBatchResult{Channel: ch, Populated: i%2 == 0})
+	}
+	return resp, nil
+}
+
+func TestGRPCCacheEmptyProxyBatch(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	counter := &cacheEmptyCountingServer{}
+	proxyproto.RegisterCentrifugoProxyServer(server, counter)
+	proxyproto.RegisterCentrifugoProxyBatchServer(server, &cacheEmptyBatchServer{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	cfg := Config{
+		Endpoint: configtypes.String(listener.Addr().String()),
+		Timeout:  configtypes.Duration(5 * time.Second),
+	}
+	cfg.GRPC.BatchWindow = configtypes.Duration(50 * time.Millisecond)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	})
+	populated := handleConcurrently(t, h.Handle(), []string{"test:1", "test:2", "test:3"})
+	require.Len(t, populated, 3)
+	numPopulated := 0
+	for _, ok := range populated {
+		if ok {
+			numPopulated++
+		}
+	}
+	require.Equal(t, 2, numPopulated)
+	require.Zero(t, counter.calls.Load())
+	require.Equal(t, uint64(1), h.Stats()["test"].TotalCalls)
+}
+
+func TestGRPCCacheEmptyProxyBatchNotSupported(t *testing.T) {
+	counter, _, addr := startCacheEmptyCountingServer(t)
+
+	cfg := Config{
+		Endpoint: configtypes.String(addr),
+		Timeout:  configtypes.Duration(5 * time.Second),
+	}
+	cfg.GRPC.BatchWindow = configtypes.Duration(10 * time.Millisecond)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	_, err = p.ProxyCacheEmptyBatch(context.Background(), &proxyproto.NotifyCacheEmptyBatchRequest{Channels: []string{"test"}})
+	require.ErrorIs(t, err, ErrCacheEmptyBatchNotSupported)
+
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	})
+	handleConcurrently(t, h.Handle(), []string{"test:1", "test:2"})
+	require.Equal(t, int64(2), counter.calls.Load())
+}
+
+type cacheEmptyDeadlineServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	remaining chan time.Duration
+}
+
+func (s *cacheEmptyDeadlineServer) NotifyCacheEmpty(ctx context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		s.remaining <- 0
+	} else {
+		s.remaining <- time.Until(deadline)
+	}
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+}
+
+func TestGRPCCacheEmptyProxyDeadline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	deadlineServer := &cacheEmptyDeadlineServer{remaining: make(chan time.Duration, 1)}
+	proxyproto.RegisterCentrifugoProxyServer(server, deadlineServer)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	p, err := NewGRPCCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(listener.Addr().String()),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	for name, tc := range map[string]struct {
+		ctxTimeout time.Duration
+		expected   time.Duration
+	}{
+		"incoming deadline is earlier": {ctxTimeout: 200 * time.Millisecond, expected: 200 * time.Millisecond},
+		"proxy timeout is earlier":     {ctxTimeout: time.Minute, expected: time.Second},
+		"no incoming deadline":         {expected: time.Second},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.ctxTimeout)
+				defer cancel()
+			}
+			_, err := p.ProxyCacheEmpty(ctx, &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+			require.NoError(t, err)
+			// Deadline is passed to the backend in grpc-timeout header.
+			remaining := <-deadlineServer.remaining
+			require.LessOrEqual(t, remaining, tc.expected)
+			require.Greater(t, remaining, tc.expected-100*time.Millisecond)
+		})
+	}
+}
+
+type cacheEmptyRequestServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	requests chan *proxyproto.NotifyCacheEmptyRequest
+}
+
+func (s *cacheEmptyRequestServer) NotifyCacheEmpty(_ context.Context, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	s.requests <- req
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: true}}, nil
+}
+
+func TestGRPCCacheEmptyProxyClientInfo(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	requestServer := &cacheEmptyRequestServer{requests: make(chan *proxyproto.NotifyCacheEmptyRequest, 1)}
+	proxyproto.RegisterCentrifugoProxyServer(server, requestServer)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	cfg := Config{
+		Endpoint: configtypes.String(listener.Addr().String()),
+		Timeout:  configtypes.Duration(5 * time.Second),
+	}
+	cfg.IncludeConnectionMeta = true
+	cfg.BinaryEncoding = true
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	}).Handle()
+
+	ctx := WithCacheEmptyClient(context.Background(), CacheEmptyClient{
+		ClientID: "client-id",
+		UserID:   "42",
+		Meta:     []byte(`{"plan":"pro"}`),
+	})
+	_, err = handler(ctx, "test:channel")
+	require.NoError(t, err)
+	req := <-requestServer.requests
+	require.Equal(t, "test:channel", req.Channel)
+	require.Equal(t, "client-id", req.Client)
+	require.Equal(t, "42", req.User)
+	require.Equal(t, "binary", req.Encoding)
+	require.Equal(t, `{"plan":"pro"}`, string(req.Meta))
+
+	_, err = handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	req = <-requestServer.requests
+	require.Equal(t, "test:channel", req.Channel)
+	require.Empty(t, req.Client)
+	require.Empty(t, req.User)
+	require.Empty(t, req.Meta)
+}
+
+func TestGRPCCacheEmptyProxyInsecureSkipVerify(t *testing.T) {
+	// Borrow self-signed certificate of httptest TLS server.
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	certificates := certServer.TLS.Certificates
+	certServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: certificates})))
+	proxyproto.RegisterCentrifugoProxyServer(server, &cacheEmptyCountingServer{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	for _, insecureSkipVerify := range []bool{false, true} {
+		cfg := Config{
+			Endpoint: configtypes.String(listener.Addr().String()),
+			Timeout:  configtypes.Duration(time.Second),
+		}
+		cfg.GRPC.TLS.Enabled = true
+		cfg.GRPC.TLS.InsecureSkipVerify = insecureSkipVerify
+		buf := captureLogs(t)
+		p, err := NewGRPCCacheEmptyProxy("test", cfg)
+		require.NoError(t, err)
+
+		_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		_ = p.Close()
+		if !insecureSkipVerify {
+			require.Error(t, err)
+			require.NotContains(t, buf.String(), "INSECURE")
+			continue
+		}
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), `"level":"warn"`)
+		require.Contains(t, buf.String(), "INSECURE")
+	}
+}
+
+func newBufconnCacheEmptyServer(t *testing.T) (*cacheEmptyCountingServer, Config) {
+	t.Helper()
+	counter := &cacheEmptyCountingServer{}
+	return counter, newBufconnProxyConfig(t, counter)
+}
+
+func newBufconnProxyConfig(t *testing.T, srv proxyproto.CentrifugoProxyServer) Config {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	proxyproto.RegisterCentrifugoProxyServer(server, srv)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return Config{
+		// Using passthrough is required for in-memory bufconn since grpc-go v1.63.0.
+		Endpoint: "passthrough:///bufnet",
+		Timeout:  configtypes.Duration(time.Second),
+		TestGrpcDialer: func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		},
+	}
+}
+
+func TestGRPCCacheEmptyProxyEagerConnect(t *testing.T) {
+	counter, cfg := newBufconnCacheEmptyServer(t)
+	cfg.GRPC.EagerConnect = true
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	require.Equal(t, connectivity.Ready, p.conns.conns[0].conn.GetState())
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), counter.calls.Load())
+}
+
+func TestGRPCCacheEmptyProxyEagerConnectNotReady(t *testing.T) {
+	cfg := Config{
+		Endpoint: "passthrough:///unreachable",
+		Timeout:  configtypes.Duration(time.Second),
+		TestGrpcDialer: func(context.Context, string) (net.Conn, error) {
+			return nil, errors.New("backend unavailable")
+		},
+	}
+	cfg.GRPC.EagerConnect = true
+	cfg.GRPC.EagerConnectTimeout = configtypes.Duration(100 * time.Millisecond)
+	started := time.Now()
+	_, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.ErrorIs(t, err, ErrGRPCConnNotReady)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(started), time.Second)
+}
+
+type cacheEmptyStreamServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	populate bool
+	// closed is closed when stream handler returns.
+	closed chan struct{}
+}
+
+func (s *cacheEmptyStreamServer) NotifyCacheEmptyStream(_ *proxyproto.NotifyCacheEmptyRequest, stream proxyproto.CentrifugoProxy_NotifyCacheEmptyStreamServer) error {
+	defer close(s.closed)
+	for i := 0; i < 2; i++ {
+		if err := stream.Send(&proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}); err != nil {
+			return err
+		}
+	}
+	if !s.populate {
+		return nil
+	}
+	if err := stream.Send(&proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: true}}); err != nil {
+		return err
+	}
+	// Backend may keep stream open while it continues population.
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestGRPCCacheEmptyProxyStreaming(t *testing.T) {
+	for _, populate := range []bool{true, false} {
+		srv := &cacheEmptyStreamServer{populate: populate, closed: make(chan struct{})}
+		cfg := newBufconnProxyConfig(t, srv)
+		cfg.GRPC.Streaming = true
+		p, err := NewGRPCCacheEmptyProxy("test", cfg)
+		require.NoError(t, err)
+
+		resp, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		require.NoError(t, err)
+		require.Equal(t, populate, resp.GetResult().GetPopulated())
+		select {
+		case <-srv.closed:
+		case <-time.After(time.Second):
+			require.Fail(t, "stream not closed")
+		}
+		_ = p.Close()
+	}
+}
+
+func TestGRPCCacheEmptyProxyStreamingUnimplemented(t *testing.T) {
+	_, cfg := newBufconnCacheEmptyServer(t)
+	cfg.GRPC.Streaming = true
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGRPCCacheEmptyProxyConnectTimeout(t *testing.T) {
+	dialDeadlines := make(chan time.Duration, 10)
+	cfg := Config{
+		Endpoint:       "passthrough:///stalled",
+		Timeout:        configtypes.Duration(5 * time.Second),
+		ConnectTimeout: configtypes.Duration(100 * time.Millisecond),
+		TestGrpcDialer: func(ctx context.Context, _ string) (net.Conn, error) {
+			if deadline, ok := ctx.Deadline(); ok {
+				dialDeadlines <- time.Until(deadline)
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	started := time.Now()
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Equal(t, codes.Unavailable, status.Code(err), err)
+	require.Less(t, time.Since(started), time.Second)
+	select {
+	case d := <-dialDeadlines:
+		require.LessOrEqual(t, d, 100*time.Millisecond)
+	default:
+		t.Fatal("dial without deadline")
+	}
+}
+
+type cacheEmptyStatusServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	code  codes.Code
+	calls atomic.Int64
+}
+
+func (s *cacheEmptyStatusServer) NotifyCacheEmpty(_ context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(s.code, "rejected")
+}
+
+func TestGRPCCacheEmptyProxyStatusToCodeTransforms(t *testing.T) {
+	srv := &cacheEmptyStatusServer{code: codes.PermissionDenied}
+	cfg := newBufconnProxyConfig(t, srv)
+	cfg.MaxRetries = 2
+	cfg.RetryBackoff = configtypes.Duration(time.Millisecond)
+	cfg.GRPC.StatusToCodeTransforms = configtypes.GrpcStatusToCodeTransforms{
+		{StatusCode: uint32(codes.PermissionDenied), ToError: configtypes.TransformError{Code: 403, Message: "permission denied"}},
+		{StatusCode: uint32(codes.Unauthenticated), ToDisconnect: configtypes.TransformDisconnect{Code: 4501, Reason: "unauthorized"}},
+	}
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	var transformedErr *TransformedError
+	require.ErrorAs(t, err, &transformedErr)
+	require.Equal(t, uint32(403), transformedErr.ProtocolError.Code)
+	require.Equal(t, "permission denied", transformedErr.ProtocolError.Message)
+	require.Nil(t, transformedErr.ProtocolDisconnect)
+	// Original status kept for logging.
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "rejected")
+	require.Equal(t, ErrorClassApplication, ClassifyError(err, nil))
+	require.Equal(t, int64(1), srv.calls.Load())
+
+	srv.code = codes.Unauthenticated
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.ErrorAs(t, err, &transformedErr)
+	require.Nil(t, transformedErr.ProtocolError)
+	require.Equal(t, uint32(4501), transformedErr.ProtocolDisconnect.Code)
+
+	// Statuses without transform are returned as is.
+	srv.code = codes.NotFound
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.False(t, errors.As(err, &transformedErr))
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCacheEmptyProxyStatusToCodeTransformsConsistent(t *testing.T) {
+	toError := configtypes.TransformError{Code: 403, Message: "permission denied"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	httpCfg := Config{Endpoint: configtypes.String(server.URL), Timeout: configtypes.Duration(time.Second)}
+	httpCfg.HTTP.StatusToCodeTransforms = configtypes.HttpStatusToCodeTransforms{{StatusCode: http.StatusForbidden, ToError: toError}}
+	httpProxy, err := NewHTTPCacheEmptyProxy("test", httpCfg)
+	require.NoError(t, err)
+
+	grpcCfg := newBufconnProxyConfig(t, &cacheEmptyStatusServer{code: codes.PermissionDenied})
+	grpcCfg.GRPC.StatusToCodeTransforms = configtypes.GrpcStatusToCodeTransforms{{StatusCode: uint32(codes.PermissionDenied), ToError: toError}}
+	grpcProxy, err := NewGRPCCacheEmptyProxy("test", grpcCfg)
+	require.NoError(t, err)
+	defer func() { _ = grpcProxy.Close() }()
+
+	for _, p := range []CacheEmptyProxy{httpProxy, grpcProxy} {
+		_, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		var transformedErr *TransformedError
+		require.ErrorAs(t, err, &transformedErr, p.Protocol())
+		require.Equal(t, uint32(403), transformedErr.ProtocolError.Code, p.Protocol())
+		require.Equal(t, ErrorClassApplication, ClassifyError(err, nil), p.Protocol())
+	}
+}
+
+type metadataCacheEmptyServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	md chan metadata.MD
+}
+
+func (s *metadataCacheEmptyServer) NotifyCacheEmpty(ctx context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.md <- md
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: true}}, nil
+}
+
+func TestGRPCCacheEmptyProxyNonASCIIMetadata(t *testing.T) {
+	srv := &metadataCacheEmptyServer{md: make(chan metadata.MD, 1)}
+	cfg := newBufconnProxyConfig(t, srv)
+	cfg.HttpHeaders = []string{"x-channel", "x-plain"}
+	cfg.GRPC.StaticMetadata = map[string]string{"x-static": "grüße"}
+	cfg.BinaryEncoding = true
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	ctx := middleware.SetHeadersToContext(context.Background(), http.Header{
+		"X-Channel": []string{"новости:спорт"},
+		"X-Plain":   []string{"plain"},
+	})
+	_, err = p.ProxyCacheEmpty(ctx, &proxyproto.NotifyCacheEmptyRequest{Channel: "новости:спорт"})
+	require.NoError(t, err)
+
+	md := <-srv.md
+	require.Equal(t, []string{"новости:спорт"}, md.Get("x-channel-bin"))
+	require.Equal(t, []string{"grüße"}, md.Get("x-static-bin"))
+	require.Empty(t, md.Get("x-channel"))
+	require.Empty(t, md.Get("x-static"))
+	require.Equal(t, []string{"plain"}, md.Get("x-plain"))
+}
+
+// tokenCacheEmptyServer accepts tokens issued by tokenIssuer which are not expired yet.
+type tokenCacheEmptyServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	issuer *tokenIssuer
+}
+
+func (s *tokenCacheEmptyServer) NotifyCacheEmpty(ctx context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) != 1 || !s.issuer.valid(values[0]) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: true}}, nil
+}
+
+type tokenIssuer struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	issued map[string]time.Time
+}
+
+// token issues new token, tokens of issuer with zero ttl do not expire.
+func (i *tokenIssuer) token(context.Context) (string, time.Time, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	token := "token-" + strconv.Itoa(len(i.issued))
+	var expiresAt time.Time
+	if i.ttl > 0 {
+		expiresAt = time.Now().Add(i.ttl)
+	}
+	i.issued[token] = expiresAt
+	return token, expiresAt, nil
+}
+
+func (i *tokenIssuer) valid(token string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	expiresAt, ok := i.issued[token]
+	return ok && (expiresAt.IsZero() || time.Now().Before(expiresAt))
+}
+
+func (i *tokenIssuer) count() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return len(i.issued)
+}
+
+func TestGRPCCacheEmptyProxyCredentialsProvider(t *testing.T) {
+	issuer := &tokenIssuer{ttl: 100 * time.Millisecond, issued: map[string]time.Time{}}
+	cfg := newBufconnProxyConfig(t, &tokenCacheEmptyServer{issuer: issuer})
+	cfg.GRPCCredentialsProvider = issuer.token
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	// Calls span several token lifetimes.
+	for i := 0; i < 30; i++ {
+		_, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		require.NoError(t, err, i)
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.GreaterOrEqual(t, issuer.count(), 3)
+	require.Less(t, issuer.count(), 30)
+}
+
+func TestGRPCCacheEmptyProxyCredentialsProviderNotExpiring(t *testing.T) {
+	issuer := &tokenIssuer{issued: map[string]time.Time{}}
+	cfg := newBufconnProxyConfig(t, &tokenCacheEmptyServer{issuer: issuer})
+	cfg.GRPCCredentialsProvider = issuer.token
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	for i := 0; i < 3; i++ {
+		_, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, issuer.count())
+}
+
+func TestGRPCCacheEmptyProxyCredentialsProviderError(t *testing.T) {
+	srv, cfg := newBufconnCacheEmptyServer(t)
+	cfg.GRPCCredentialsProvider = func(context.Context) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("token endpoint unavailable")
+	}
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.ErrorContains(t, err, "token endpoint unavailable")
+	require.Equal(t, int64(0), srv.calls.Load())
+}
+
+func TestRefreshingRPCCredentialsConcurrentRefresh(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	creds := &refreshingRPCCredentials{key: "authorization", provider: func(context.Context) (string, time.Time, error) {
+		calls.Add(1)
+		<-release
+		return "token", time.Now().Add(time.Minute), nil
+	}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			md, err := creds.GetRequestMetadata(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, "token", md["authorization"])
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int64(1), calls.Load())
+}
+
+func TestRefreshingRPCCredentialsRefreshError(t *testing.T) {
+	var fail atomic.Bool
+	creds := &refreshingRPCCredentials{key: "authorization", provider: func(context.Context) (string, time.Time, error) {
+		if fail.Load() {
+			return "", time.Time{}, errors.New("token endpoint unavailable")
+		}
+		return "token", time.Now().Add(time.Minute), nil
+	}}
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token", md["authorization"])
+
+	// Refresh is due but fails – value is used until it expires.
+	fail.Store(true)
+	creds.mu.Lock()
+	creds.refreshAt = time.Now().Add(-time.Second)
+	creds.mu.Unlock()
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token", md["authorization"])
+
+	creds.mu.Lock()
+	creds.expiresAt = time.Now().Add(-time.Millisecond)
+	creds.mu.Unlock()
+	_, err = creds.GetRequestMetadata(context.Background())
+	require.ErrorContains(t, err, "token endpoint unavailable")
+}
+
+func TestGRPCRequestContextNonASCIIMetadataNoBinaryEncoding(t *testing.T) {
+	cfg := Config{}
+	cfg.HttpHeaders = []string{"x-channel"}
+	ctx := middleware.SetHeadersToContext(context.Background(), http.Header{
+		"X-Channel": []string{"новости:спорт"},
+	})
+
+	// Without BinaryEncoding metadata keys are kept as is.
+	md, _ := metadata.FromOutgoingContext(grpcRequestContext(ctx, cfg))
+	require.Equal(t, []string{"новости:спорт"}, md.Get("x-channel"))
+	require.Empty(t, md.Get("x-channel-bin"))
+}
@@ -2,12 +2,18 @@ package proxy
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -17,16 +23,115 @@ type CacheEmptyHandlerFunc func(ctx context.Context, channel string) (*proxyprot
 // CacheEmptyHandlerConfig configures CacheEmptyHandler.
 type CacheEmptyHandlerConfig struct {
 	Proxies map[string]CacheEmptyProxy
+	// ProxyName resolves name of proxy in Proxies to use for a channel – usually from options of
+	// channel namespace. Returns false if channel has no cache empty proxy enabled. If not set then
+	// the single configured proxy is used for all channels – if there are several of them,
+	// DefaultProxy or the first one in order of names is used.
+	ProxyName func(channel string) (string, bool)
+	// ProxySelector when set resolves proxy for a channel instead of ProxyName – e.g. to shard
+	// channels between backends by hash of channel. Returns name of proxy in Proxies, FallbackMode
+	// applies if there is no such proxy. Returning false means no proxy applies to channel,
+	// handler returns result with Populated: false then.
+	ProxySelector func(channel string) (string, bool)
+	// DefaultProxy is a name of proxy in Proxies used with CacheEmptyFallbackDefault mode. Handler
+	// logs an error and falls back to CacheEmptyFallbackError mode if there is no such proxy.
+	DefaultProxy string
+	// FallbackMode defines what to do when ProxyName does not resolve a proxy for a channel –
+	// returns false or name missing in Proxies – or ProxySelector returns name missing in
+	// Proxies. Default is CacheEmptyFallbackError.
+	FallbackMode CacheEmptyFallbackMode
 	// LockTimeout is the maximum time to wait for a lock on a channel.
-	// If not set, defaults to 5 seconds. This prevents deadlocks and indefinite blocking.
+	// If not set, Defaults.LockTimeout is used (5 seconds unless changed with SetDefaults).
+	// This prevents deadlocks and indefinite blocking.
 	LockTimeout time.Duration
+	// LockTimeoutJitter randomizes lock timeout of each waiter within the given fraction (0–1)
+	// of LockTimeout – so that waiters timing out on the same lock do not make independent
+	// calls to backend at the same moment. Zero means no jitter.
+	LockTimeoutJitter float64
+	// CallTimeout limits proxy call shared by concurrent callers for the same channel. Shared
+	// call is not bound to context of the caller which started it – so that cancellation of one
+	// caller does not fail others. Each caller still returns upon its own context cancellation.
+	// If not set, Defaults.CallTimeout is used.
+	CallTimeout time.Duration
+	// NeverDuplicate makes waiters never call proxy independently upon LockTimeout – for backends
+	// which are not idempotent. Waiters keep waiting for the shared call result up to CallTimeout
+	// and then get ErrLockTimeout.
+	NeverDuplicate bool
+	// StaleOnError makes handler return the last successful result for a channel instead of
+	// proxy call error – if that result is not older than StaleWindow.
+	StaleOnError bool
+	// StaleWindow is how long the last successful result may be returned upon errors. If not
+	// set, Defaults.StaleWindow is used.
+	StaleWindow time.Duration
+	// NoCacheTTL is how long handler returns result with Populated: false for a channel without
+	// calling proxy after backend returned result with NoCache. If not set, Defaults.NoCacheTTL
+	// is used. NoCache is not available in batch mode.
+	NoCacheTTL time.Duration
+	// ChannelAllow is a list of glob patterns, like `data:*` or `chat:{room,lobby}`, limiting
+	// channels for which proxy is called. Empty list allows all channels.
+	ChannelAllow []string
+	// ChannelDeny is a list of glob patterns of channels for which proxy is not called, takes
+	// precedence over ChannelAllow. For channels not passing ChannelAllow and ChannelDeny handler
+	// returns result with Populated: false right away without taking channel lock.
+	ChannelDeny []string
+	// ObserveOnly makes handler call proxy but always return result with Populated: false – so
+	// that new backend may be shadow-tested without affecting reads. Proxy call errors are not
+	// returned too. Would-be results are logged on debug level and counted in metrics.
+	ObserveOnly bool
+	// HealthWindow is a period over which proxy call errors are counted for Health and
+	// HealthHandler. If not set, Defaults.HealthWindow is used.
+	HealthWindow time.Duration
+	// HealthMaxErrorRate is a share of failed calls (0–1) within HealthWindow above which proxy is
+	// considered unhealthy. If not set, Defaults.HealthMaxErrorRate is used.
+	HealthMaxErrorRate float64
+	// HealthMinCalls is a minimal number of calls within HealthWindow required to consider proxy
+	// unhealthy – so that a single failure after idle period does not fail health check.
+	// Zero means 1.
+	HealthMinCalls int
 }
 
+// CacheEmptyFallbackMode defines behaviour of CacheEmptyHandler for channels without proxy.
+type CacheEmptyFallbackMode int
+
+const (
+	// CacheEmptyFallbackError makes handler return ErrNoCacheEmptyProxy.
+	CacheEmptyFallbackError CacheEmptyFallbackMode = iota
+	// CacheEmptyFallbackDefault makes handler call DefaultProxy.
+	CacheEmptyFallbackDefault
+	// CacheEmptyFallbackSkip makes handler return result with Populated: false without calling
+	// any proxy.
+	CacheEmptyFallbackSkip
+)
+
 var (
 	// ErrLockTimeout is returned when unable to acquire lock within timeout.
 	ErrLockTimeout = errors.New("timeout waiting for cache empty lock")
+	// ErrCacheEmptyHandlerClosed is returned for new calls after CacheEmptyHandler was closed.
+	ErrCacheEmptyHandlerClosed = errors.New("cache empty handler closed")
+	// ErrNoCacheEmptyProxy is returned when there is no proxy to call – so nobody tried to
+	// populate cache. This differs from a successful response with Populated: false where
+	// backend was called but could not populate cache.
+	ErrNoCacheEmptyProxy = errors.New("no cache empty proxy configured")
 )
 
+// CacheEmptyClient describes subscriber which triggered cache empty event.
+type CacheEmptyClient struct {
+	ClientID string
+	UserID   string
+	// Meta of connection, only sent to proxies with IncludeMeta.
+	Meta json.RawMessage
+}
+
+type cacheEmptyClientContextKey struct{}
+
+// WithCacheEmptyClient returns context with subscriber info which CacheEmptyHandler sends to
+// backend in NotifyCacheEmptyRequest. Since concurrent events for the same channel result into
+// one proxy call, backend receives info of the subscriber which triggered the call. Subscriber
+// info is not sent in batch mode.
+func WithCacheEmptyClient(ctx context.Context, c CacheEmptyClient) context.Context {
+	return context.WithValue(ctx, cacheEmptyClientContextKey{}, c)
+}
+
 // channelLock represents a lock for a specific channel's cache empty operation.
 type channelLock struct {
 	result *proxyproto.NotifyCacheEmptyResponse
@@ -34,70 +139,360 @@ type channelLock struct {
 	done   chan struct{}
 }
 
+// CacheEmptyProxyStats is a snapshot of cache empty proxy call statistics.
+type CacheEmptyProxyStats struct {
+	// TotalCalls is a number of calls made to proxy.
+	TotalCalls uint64
+	// ErrorCount is a number of calls which resulted into error.
+	ErrorCount uint64
+	// LastSuccessTime is a time of last successful call, zero if there were no such calls.
+	LastSuccessTime time.Time
+	// LastError is a text of last error, empty if there were no errors.
+	LastError string
+	// LastErrorTime is a time of last error, zero if there were no errors.
+	LastErrorTime time.Time
+	// StaleResults is a number of errors for which stale result was returned instead.
+	StaleResults uint64
+}
+
+type cacheEmptyProxyError struct {
+	message string
+	time    time.Time
+}
+
+// cacheEmptyProxyCounters are updated on every proxy call, successful calls do not allocate.
+type cacheEmptyProxyCounters struct {
+	calls       atomic.Uint64
+	errors      atomic.Uint64
+	lastSuccess atomic.Int64 // Unix nanoseconds.
+	lastError   atomic.Pointer[cacheEmptyProxyError]
+	stale       atomic.Uint64
+	window      *cacheEmptyCallWindow
+}
+
+func newCacheEmptyProxyCounters(healthWindow time.Duration) *cacheEmptyProxyCounters {
+	return &cacheEmptyProxyCounters{window: newCacheEmptyCallWindow(healthWindow)}
+}
+
+func (c *cacheEmptyProxyCounters) record(err error) {
+	c.window.record(time.Now(), err)
+	c.calls.Add(1)
+	if err != nil {
+		c.errors.Add(1)
+		c.lastError.Store(&cacheEmptyProxyError{message: err.Error(), time: time.Now()})
+		return
+	}
+	c.lastSuccess.Store(time.Now().UnixNano())
+}
+
+func (c *cacheEmptyProxyCounters) snapshot() CacheEmptyProxyStats {
+	stats := CacheEmptyProxyStats{
+		TotalCalls:   c.calls.Load(),
+		ErrorCount:   c.errors.Load(),
+		StaleResults: c.stale.Load(),
+	}
+	if lastSuccess := c.lastSuccess.Load(); lastSuccess > 0 {
+		stats.LastSuccessTime = time.Unix(0, lastSuccess)
+	}
+	if lastError := c.lastError.Load(); lastError != nil {
+		stats.LastError = lastError.message
+		stats.LastErrorTime = lastError.time
+	}
+	return stats
+}
+
 // CacheEmptyHandler manages cache empty proxy calls with concurrency control.
 // This provides single-instance deduplication. For multi-instance setups with Redis,
 // the backend should implement idempotency to handle concurrent calls from different instances.
 type CacheEmptyHandler struct {
-	proxies      map[string]CacheEmptyProxy
-	channelLocks sync.Map // map[string]*channelLock
-	lockTimeout  time.Duration
+	proxies        map[string]CacheEmptyProxy
+	proxyName      func(channel string) (string, bool)
+	proxySelector  func(channel string) (string, bool)
+	defaultProxy   string // Used when proxyName is not set or with fallback, may be empty.
+	fallbackMode   CacheEmptyFallbackMode
+	counters       map[string]*cacheEmptyProxyCounters // Not modified after creation.
+	batchers       map[string]*cacheEmptyBatcher       // Not modified after creation.
+	loggers        map[string]*zerolog.Logger          // Not modified after creation.
+	channelLocks   sync.Map                            // map[string]*channelLock
+	lockTimeout    time.Duration
+	lockJitter     float64
+	callTimeout    time.Duration
+	neverDuplicate bool
+	staleOnError   bool
+	staleWindow    time.Duration
+	staleResults   sync.Map     // map[string]*staleCacheEmptyResult
+	staleSweep     atomic.Int64 // Unix nanoseconds of last removal of expired stale results.
+	noCacheTTL     time.Duration
+	noCache        sync.Map       // map[string]*noCacheChannel
+	noCacheSweep   atomic.Int64   // Unix nanoseconds of last removal of expired no cache channels.
+	filter         *channelFilter // Nil if ChannelAllow and ChannelDeny are empty.
+	observeOnly    bool
+
+	healthMaxErrorRate float64
+	healthMinCalls     int
+
+	mu       sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
 }
 
-// NewCacheEmptyHandler creates new CacheEmptyHandler.
-func NewCacheEmptyHandler(config CacheEmptyHandlerConfig) CacheEmptyHandlerFunc {
+// NewCacheEmptyHandler creates new CacheEmptyHandler. Returns error if any of ChannelAllow or
+// ChannelDeny patterns is malformed or any of Proxies is nil.
+func NewCacheEmptyHandler(config CacheEmptyHandlerConfig) (*CacheEmptyHandler, error) {
+	var filter *channelFilter
+	if len(config.ChannelAllow) > 0 || len(config.ChannelDeny) > 0 {
+		var err error
+		filter, err = newChannelFilter(config.ChannelAllow, config.ChannelDeny)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defaults := CurrentDefaults()
 	lockTimeout := config.LockTimeout
 	if lockTimeout == 0 {
-		lockTimeout = 5 * time.Second // default timeout
+		lockTimeout = defaults.LockTimeout
+	}
+	callTimeout := config.CallTimeout
+	if callTimeout <= 0 {
+		callTimeout = defaults.CallTimeout
+	}
+	staleWindow := config.StaleWindow
+	if staleWindow == 0 {
+		staleWindow = defaults.StaleWindow
+	}
+	noCacheTTL := config.NoCacheTTL
+	if noCacheTTL <= 0 {
+		noCacheTTL = defaults.NoCacheTTL
+	}
+	healthWindow := config.HealthWindow
+	if healthWindow <= 0 {
+		healthWindow = defaults.HealthWindow
+	}
+	healthMaxErrorRate := config.HealthMaxErrorRate
+	if healthMaxErrorRate <= 0 {
+		healthMaxErrorRate = defaults.HealthMaxErrorRate
 	}
-	handler := &CacheEmptyHandler{
-		proxies:     config.Proxies,
-		lockTimeout: lockTimeout,
+	counters := make(map[string]*cacheEmptyProxyCounters, len(config.Proxies))
+	batchers := map[string]*cacheEmptyBatcher{}
+	loggers := map[string]*zerolog.Logger{}
+	var names []string
+	for name, p := range config.Proxies {
+		if p == nil {
+			return nil, fmt.Errorf("cache empty proxy %s is nil", name)
+		}
+		names = append(names, name)
+		counters[name] = newCacheEmptyProxyCounters(healthWindow)
+		if lp, ok := p.(loggingProxy); ok {
+			loggers[name] = lp.logger()
+		}
+		if bp, ok := p.(CacheEmptyBatchProxy); ok && bp.BatchWindow() > 0 {
+			batchers[name] = newCacheEmptyBatcher(bp, counters[name])
+		}
+	}
+	var defaultProxy string
+	fallbackMode := config.FallbackMode
+	if config.DefaultProxy != "" {
+		if slices.Contains(names, config.DefaultProxy) {
+			defaultProxy = config.DefaultProxy
+		} else {
+			log.Error().Str("proxy_name", config.DefaultProxy).Msg("default cache empty proxy not found")
+		}
+	}
+	if fallbackMode == CacheEmptyFallbackDefault && defaultProxy == "" {
+		fallbackMode = CacheEmptyFallbackError
+	}
+	if config.ProxyName == nil && config.ProxySelector == nil && defaultProxy == "" && len(names) > 0 {
+		slices.Sort(names)
+		defaultProxy = names[0]
+		if len(names) > 1 {
+			log.Warn().Strs("proxy_names", names).Str("proxy_name", defaultProxy).Msg("several cache empty proxies configured without routing, using the first one")
+		}
+	}
+	return &CacheEmptyHandler{
+		proxies:        config.Proxies,
+		proxyName:      config.ProxyName,
+		proxySelector:  config.ProxySelector,
+		defaultProxy:   defaultProxy,
+		fallbackMode:   fallbackMode,
+		counters:       counters,
+		batchers:       batchers,
+		loggers:        loggers,
+		lockTimeout:    lockTimeout,
+		lockJitter:     min(max(config.LockTimeoutJitter, 0), 1),
+		callTimeout:    callTimeout,
+		neverDuplicate: config.NeverDuplicate,
+		staleOnError:   config.StaleOnError,
+		staleWindow:    staleWindow,
+		noCacheTTL:     noCacheTTL,
+		filter:         filter,
+		observeOnly:    config.ObserveOnly,
+
+		healthMaxErrorRate: healthMaxErrorRate,
+		healthMinCalls:     max(config.HealthMinCalls, 1),
+	}, nil
+}
+
+// Stats returns call statistics for each configured proxy by proxy name.
+func (h *CacheEmptyHandler) Stats() map[string]CacheEmptyProxyStats {
+	stats := make(map[string]CacheEmptyProxyStats, len(h.counters))
+	for name, c := range h.counters {
+		stats[name] = c.snapshot()
 	}
-	return handler.handle
+	return stats
+}
+
+// Handle returns function to handle cache empty events.
+func (h *CacheEmptyHandler) Handle() CacheEmptyHandlerFunc {
+	return h.handle
+}
+
+// Close stops accepting new proxy calls, waits for in-flight calls to finish (until ctx
+// is done) and closes proxies which hold resources (like GRPC connections).
+func (h *CacheEmptyHandler) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.inflight.Wait()
+		close(done)
+	}()
+
+	var waitErr error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		waitErr = ctx.Err()
+	}
+
+	var closeErrs []error
+	for name, p := range h.proxies {
+		if closer, ok := p.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				closeErrs = append(closeErrs, fmt.Errorf("error closing cache empty proxy %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(append([]error{waitErr}, closeErrs...)...)
+}
+
+// acquireCall registers in-flight call. Returns false if handler is closed.
+func (h *CacheEmptyHandler) acquireCall() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.inflight.Add(1)
+	return true
 }
 
 func (h *CacheEmptyHandler) handle(ctx context.Context, channel string) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	if h.filter != nil && !h.filter.Allowed(channel) {
+		return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+	}
+	if name, ok := h.noCacheProxy(channel); ok {
+		proxyCacheEmptyResultCount.WithLabelValues(name, "no_cache").Inc()
+		return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+	}
+
 	// Try to acquire or wait for the lock for this channel
 	lock, isFirstCall := h.getOrCreateLock(channel)
 
 	if isFirstCall {
-		// This is the first call for this channel, we should make the proxy call
-		defer func() {
-			// Clean up the lock after we're done
+		if !h.acquireCall() {
+			lock.err = ErrCacheEmptyHandlerClosed
 			h.channelLocks.Delete(channel)
 			close(lock.done)
-		}()
-
-		req := &proxyproto.NotifyCacheEmptyRequest{
-			Channel: channel,
+			return nil, lock.err
+		}
+		// This is the first call for this channel, we should make the proxy call.
+		if ctx.Done() == nil {
+			// Context can't be cancelled, so no need in separate goroutine.
+			h.sharedCall(ctx, channel, lock)
+			return lock.result, lock.err
+		}
+		go h.sharedCall(ctx, channel, lock)
+		select {
+		case <-lock.done:
+			return lock.result, lock.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
-		lock.result, lock.err = handleCacheEmpty(ctx, req, h.proxies)
-		return lock.result, lock.err
 	}
 
 	// Wait for the first call to complete with timeout to prevent deadlock
-	timer := time.NewTimer(h.lockTimeout)
+	lockTimeout := h.waitLockTimeout()
+	if h.neverDuplicate {
+		// Shared call started earlier is limited by the same timeout, so should be done by then.
+		lockTimeout = h.callTimeout
+	}
+	timer := time.NewTimer(lockTimeout)
 	defer timer.Stop()
 
 	select {
 	case <-lock.done:
 		return lock.result, lock.err
 	case <-timer.C:
-		log.Warn().
-			Str("channel", channel).
-			Dur("timeout", h.lockTimeout).
+		if h.neverDuplicate {
+			logChannel(log.Error(), nil, channel).
+				Dur("timeout", lockTimeout).
+				Msg("timeout waiting for cache empty lock")
+			return nil, ErrLockTimeout
+		}
+		logChannel(log.Warn(), nil, channel).
+			Dur("timeout", lockTimeout).
 			Msg("timeout waiting for cache empty lock, making independent call")
 		// Timeout occurred - make an independent call to avoid blocking indefinitely.
 		// This can happen if the first call hangs or takes too long.
+		if !h.acquireCall() {
+			return nil, ErrCacheEmptyHandlerClosed
+		}
+		defer h.inflight.Done()
 		req := &proxyproto.NotifyCacheEmptyRequest{
 			Channel: channel,
 		}
-		return handleCacheEmpty(ctx, req, h.proxies)
+		return h.handleCacheEmpty(ctx, req)
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
+// sharedCall makes proxy call which result is shared by all callers waiting on lock. Call
+// context keeps values of ctx but not its cancellation.
+func (h *CacheEmptyHandler) sharedCall(ctx context.Context, channel string, lock *channelLock) {
+	defer func() {
+		// Clean up the lock after we're done
+		h.channelLocks.Delete(channel)
+		close(lock.done)
+		h.inflight.Done()
+	}()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.callTimeout)
+	defer cancel()
+	req := &proxyproto.NotifyCacheEmptyRequest{
+		Channel: channel,
+	}
+	lock.result, lock.err = h.handleCacheEmpty(ctx, req)
+}
+
+// waitLockTimeout returns lock timeout for a waiter, randomized within
+// [lockTimeout*(1-jitter), lockTimeout*(1+jitter)] when jitter is configured.
+func (h *CacheEmptyHandler) waitLockTimeout() time.Duration {
+	if h.lockJitter == 0 {
+		return h.lockTimeout
+	}
+	return time.Duration(float64(h.lockTimeout) * (1 + h.lockJitter*(2*rand.Float64()-1)))
+}
+
+// log returns logger for messages related to proxy with the given name.
+func (h *CacheEmptyHandler) log(name string) *zerolog.Logger {
+	if l, ok := h.loggers[name]; ok {
+		return l
+	}
+	return &log.Logger
+}
+
 // getOrCreateLock attempts to get or create a lock for the given channel.
 // Returns the lock and a boolean indicating if this is the first call (true) or a subsequent call (false).
 func (h *CacheEmptyHandler) getOrCreateLock(channel string) (*channelLock, bool) {
@@ -112,20 +507,229 @@ func (h *CacheEmptyHandler) getOrCreateLock(channel string) (*channelLock, bool)
 	return lock, !loaded
 }
 
-func handleCacheEmpty(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest, proxies map[string]CacheEmptyProxy) (*proxyproto.NotifyCacheEmptyResponse, error) {
-	for name, cacheEmptyProxy := range proxies {
-		if cacheEmptyProxy == nil {
-			log.Error().Str("proxy_name", name).Msg("cache empty proxy is nil")
-			continue
+// channelProxy returns proxy to use for channel, applying CacheEmptyFallbackDefault mode.
+func (h *CacheEmptyHandler) channelProxy(channel string) (string, CacheEmptyProxy, bool) {
+	if h.proxyName == nil {
+		if h.defaultProxy == "" {
+			return "", nil, false
 		}
-		resp, err := cacheEmptyProxy.ProxyCacheEmpty(ctx, req)
+		return h.defaultProxy, h.proxies[h.defaultProxy], true
+	}
+	name, ok := h.proxyName(channel)
+	if ok {
+		if cacheEmptyProxy := h.proxies[name]; cacheEmptyProxy != nil {
+			return name, cacheEmptyProxy, true
+		}
+		logChannel(h.log(name).Error().Str("proxy_name", name), nil, channel).Msg("cache empty proxy not found")
+	}
+	if h.fallbackMode == CacheEmptyFallbackDefault {
+		return h.defaultProxy, h.proxies[h.defaultProxy], true
+	}
+	return "", nil, false
+}
+
+// staleCacheEmptyResult is the last successful result for a channel.
+type staleCacheEmptyResult struct {
+	resp *proxyproto.NotifyCacheEmptyResponse
+	time time.Time
+}
+
+// selectProxy returns proxy chosen by ProxySelector for channel, applying FallbackMode if
+// selected proxy not found. Returns false if no proxy applies to channel.
+func (h *CacheEmptyHandler) selectProxy(channel string) (string, CacheEmptyProxy, bool, error) {
+	name, ok := h.proxySelector(channel)
+	if !ok {
+		return "", nil, false, nil
+	}
+	if cacheEmptyProxy := h.proxies[name]; cacheEmptyProxy != nil {
+		return name, cacheEmptyProxy, true, nil
+	}
+	logChannel(log.Error(), nil, channel).Str("proxy_name", name).Msg("cache empty proxy selected for channel not found")
+	switch h.fallbackMode {
+	case CacheEmptyFallbackDefault:
+		return h.defaultProxy, h.proxies[h.defaultProxy], true, nil
+	case CacheEmptyFallbackSkip:
+		return "", nil, false, nil
+	default:
+		return "", nil, false, ErrNoCacheEmptyProxy
+	}
+}
+
+func (h *CacheEmptyHandler) handleCacheEmpty(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	var (
+		name            string
+		cacheEmptyProxy CacheEmptyProxy
+		ok              bool
+	)
+	if h.proxySelector != nil {
+		var err error
+		name, cacheEmptyProxy, ok, err = h.selectProxy(req.Channel)
 		if err != nil {
-			log.Error().Err(err).Str("proxy_name", name).Str("channel", req.Channel).Msg("error calling cache empty proxy")
 			return nil, err
 		}
-		return resp, nil
+		if !ok {
+			return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+		}
+	} else if name, cacheEmptyProxy, ok = h.channelProxy(req.Channel); !ok {
+		if h.proxyName != nil && h.fallbackMode == CacheEmptyFallbackSkip {
+			return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+		}
+		return nil, ErrNoCacheEmptyProxy
 	}
-	return &proxyproto.NotifyCacheEmptyResponse{
-		Result: &proxyproto.NotifyCacheEmptyResult{},
-	}, nil
+	resp, err := h.callCacheEmpty(ctx, name, cacheEmptyProxy, req)
+	if h.observeOnly {
+		h.observeResult(name, cacheEmptyProxy, req.Channel, resp, err)
+		return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+	}
+	if err != nil {
+		if stale, ok := h.staleResult(req.Channel); ok {
+			h.counters[name].stale.Add(1)
+			proxyCacheEmptyResultCount.WithLabelValues(name, "stale").Inc()
+			logChannel(h.log(name).Warn().Err(err).Str("proxy_name", name), cacheEmptyProxy, req.Channel).
+				Time("result_time", stale.time).Msg("returning stale cache empty result upon proxy error")
+			return stale.resp, nil
+		}
+		return nil, err
+	}
+	proxyCacheEmptyResultCount.WithLabelValues(name, "proxy").Inc()
+	if h.staleOnError {
+		h.storeStaleResult(req.Channel, resp)
+	}
+	if resp.Result.NoCache {
+		logChannel(h.log(name).Debug().Str("proxy_name", name), cacheEmptyProxy, req.Channel).
+			Dur("ttl", h.noCacheTTL).Msg("cache empty proxy asked to not call it for channel")
+		h.storeNoCache(name, req.Channel)
+	}
+	return resp, nil
+}
+
+// noCacheChannel remembers that backend asked to not call proxy for a channel.
+type noCacheChannel struct {
+	name  string
+	until time.Time
+}
+
+// noCacheProxy returns name of proxy if backend asked to not call it for channel within
+// NoCacheTTL.
+func (h *CacheEmptyHandler) noCacheProxy(channel string) (string, bool) {
+	v, ok := h.noCache.Load(channel)
+	if !ok {
+		return "", false
+	}
+	nc := v.(*noCacheChannel)
+	if time.Now().After(nc.until) {
+		h.noCache.CompareAndDelete(channel, v)
+		return "", false
+	}
+	return nc.name, true
+}
+
+// storeNoCache remembers that proxy must not be called for channel. Expired entries of other
+// channels are removed at most once per NoCacheTTL, so entries of inactive channels do not
+// pile up.
+func (h *CacheEmptyHandler) storeNoCache(name string, channel string) {
+	now := time.Now()
+	h.noCache.Store(channel, &noCacheChannel{name: name, until: now.Add(h.noCacheTTL)})
+	lastSweep := h.noCacheSweep.Load()
+	if now.UnixNano()-lastSweep < int64(h.noCacheTTL) || !h.noCacheSweep.CompareAndSwap(lastSweep, now.UnixNano()) {
+		return
+	}
+	h.noCache.Range(func(key, value any) bool {
+		if now.After(value.(*noCacheChannel).until) {
+			h.noCache.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// observeResult records result of proxy call made in observe-only mode.
+func (h *CacheEmptyHandler) observeResult(name string, cacheEmptyProxy CacheEmptyProxy, channel string, resp *proxyproto.NotifyCacheEmptyResponse, err error) {
+	result := "error"
+	if err == nil {
+		result = "not_populated"
+		if resp.Result.Populated {
+			result = "populated"
+		}
+	}
+	proxyCacheEmptyObservedResultCount.WithLabelValues(name, result).Inc()
+	logChannel(h.log(name).Debug().Err(err).Str("proxy_name", name), cacheEmptyProxy, channel).
+		Str("result", result).Msg("cache empty proxy result ignored in observe-only mode")
+}
+
+// staleResult returns the last successful result for channel if it's within stale window.
+func (h *CacheEmptyHandler) staleResult(channel string) (*staleCacheEmptyResult, bool) {
+	if !h.staleOnError {
+		return nil, false
+	}
+	v, ok := h.staleResults.Load(channel)
+	if !ok {
+		return nil, false
+	}
+	stale := v.(*staleCacheEmptyResult)
+	if time.Since(stale.time) > h.staleWindow {
+		h.staleResults.CompareAndDelete(channel, v)
+		return nil, false
+	}
+	return stale, true
+}
+
+// storeStaleResult remembers successful result for channel. Expired results of other channels
+// are removed at most once per stale window, so results of inactive channels do not pile up.
+func (h *CacheEmptyHandler) storeStaleResult(channel string, resp *proxyproto.NotifyCacheEmptyResponse) {
+	now := time.Now()
+	h.staleResults.Store(channel, &staleCacheEmptyResult{resp: resp, time: now})
+	lastSweep := h.staleSweep.Load()
+	if now.UnixNano()-lastSweep < int64(h.staleWindow) || !h.staleSweep.CompareAndSwap(lastSweep, now.UnixNano()) {
+		return
+	}
+	h.staleResults.Range(func(key, value any) bool {
+		if now.Sub(value.(*staleCacheEmptyResult).time) > h.staleWindow {
+			h.staleResults.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+func (h *CacheEmptyHandler) callCacheEmpty(ctx context.Context, name string, cacheEmptyProxy CacheEmptyProxy, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	if batcher, ok := h.batchers[name]; ok && !batcher.unsupported.Load() {
+		populated, err := batcher.notify(ctx, req.Channel)
+		if err == nil {
+			if !populated {
+				logChannel(h.log(name).Debug().Str("proxy_name", name), cacheEmptyProxy, req.Channel).Msg("cache empty proxy did not populate cache")
+			}
+			return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: populated}}, nil
+		}
+		if !errors.Is(err, ErrCacheEmptyBatchNotSupported) {
+			logChannel(h.log(name).Error().Err(err).Str("proxy_name", name), cacheEmptyProxy, req.Channel).Msg("error calling cache empty proxy in batch")
+			return nil, err
+		}
+		h.log(name).Debug().Err(err).Str("proxy_name", name).Msg("falling back to per-channel cache empty proxy calls")
+	}
+	req.Encoding = getEncoding(cacheEmptyProxy.UseBase64())
+	if c, ok := ctx.Value(cacheEmptyClientContextKey{}).(CacheEmptyClient); ok {
+		req.Client = c.ClientID
+		req.User = c.UserID
+		if cacheEmptyProxy.IncludeMeta() && c.Meta != nil {
+			req.Meta = proxyproto.Raw(c.Meta)
+		}
+	}
+	resp, err := cacheEmptyProxy.ProxyCacheEmpty(ctx, req)
+	h.counters[name].record(err)
+	if err != nil {
+		logChannel(h.log(name).Error().Err(err).Str("proxy_name", name), cacheEmptyProxy, req.Channel).Msg("error calling cache empty proxy")
+		return nil, err
+	}
+	if resp == nil {
+		// Misbehaving proxy implementation, callers must still get a result to read.
+		logChannel(h.log(name).Warn().Str("proxy_name", name).Str("protocol", cacheEmptyProxy.Protocol()), cacheEmptyProxy, req.Channel).
+			Msg("cache empty proxy returned nil response without error")
+	}
+	if resp.GetResult() == nil {
+		// Missing result means backend did not populate cache, make it explicit for callers.
+		resp = &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}
+	}
+	if !resp.Result.Populated {
+		logChannel(h.log(name).Debug().Str("proxy_name", name), cacheEmptyProxy, req.Channel).Msg("cache empty proxy did not populate cache")
+	}
+	return resp, nil
 }
@@ -1,20 +1,36 @@
 package proxy
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/centrifugal/centrifugo/v6/internal/config"
 	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
 	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
+// newTestCacheEmptyHandler creates CacheEmptyHandler failing test on error.
+func newTestCacheEmptyHandler(t *testing.T, config CacheEmptyHandlerConfig) *CacheEmptyHandler {
+	t.Helper()
+	h, err := NewCacheEmptyHandler(config)
+	require.NoError(t, err)
+	return h
+}
+
 func TestCacheEmptyHandlerHTTP(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req proxyproto.NotifyCacheEmptyRequest
@@ -34,17 +50,17 @@ func TestCacheEmptyHandlerHTTP(t *testing.T) {
 	}))
 	defer server.Close()
 
-	proxy, err := NewHTTPCacheEmptyProxy(Config{
-		Endpoint: server.URL,
+	proxy, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
 		Timeout:  configtypes.Duration(time.Second),
 	})
 	require.NoError(t, err)
 
-	handler := NewCacheEmptyHandler(CacheEmptyHandlerConfig{
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
 		Proxies: map[string]CacheEmptyProxy{
 			"test": proxy,
 		},
-	})
+	}).Handle()
 
 	resp, err := handler(context.Background(), "test:channel")
 	require.NoError(t, err)
@@ -53,23 +69,193 @@ func TestCacheEmptyHandlerHTTP(t *testing.T) {
 	require.True(t, resp.Result.Populated)
 }
 
+func TestCacheEmptyHandlerHTTPProtobuf(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-protobuf" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req proxyproto.NotifyCacheEmptyRequest
+		if err := proto.Unmarshal(body, &req); err != nil || req.Channel != "test:channel" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := proto.Marshal(&proxyproto.NotifyCacheEmptyResponse{
+			Result: &proxyproto.NotifyCacheEmptyResult{
+				Populated: true,
+			},
+		})
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+		ProxyCommon: configtypes.ProxyCommon{
+			HTTP: configtypes.ProxyCommonHTTP{
+				Encoding: configtypes.ProxyEncodingProtobuf,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			"test": p,
+		},
+	}).Handle()
+
+	resp, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.NotNil(t, resp.Result)
+	require.True(t, resp.Result.Populated)
+}
+
+func TestCacheEmptyHandlerHTTPMsgpack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/msgpack" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req proxyproto.NotifyCacheEmptyRequest
+		if err := proxyproto.UnmarshalMsgpack(body, &req); err != nil || req.Channel != "test:channel" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := proxyproto.MarshalMsgpack(&proxyproto.NotifyCacheEmptyResponse{
+			Result: &proxyproto.NotifyCacheEmptyResult{
+				Populated: true,
+			},
+		})
+		w.Header().Set("Content-Type", "application/msgpack")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+		ProxyCommon: configtypes.ProxyCommon{
+			BinaryEncoding: true,
+			HTTP: configtypes.ProxyCommonHTTP{
+				Encoding: configtypes.ProxyEncodingMsgpack,
+			},
+		},
+	})
+	require.NoError(t, err)
+	// Binary-capable encoding does not need base64.
+	require.False(t, p.UseBase64())
+
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			"test": p,
+		},
+	}).Handle()
+
+	resp, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.NotNil(t, resp.Result)
+	require.True(t, resp.Result.Populated)
+}
+
+func TestCacheEmptyHandlerHTTPCompressRequest(t *testing.T) {
+	longChannel := "test:" + strings.Repeat("x", 2*compressRequestMinSize)
+
+	var contentEncodings []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		contentEncodings = append(contentEncodings, r.Header.Get("Content-Encoding"))
+		mu.Unlock()
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+		var req proxyproto.NotifyCacheEmptyRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&proxyproto.NotifyCacheEmptyResponse{
+			Result: &proxyproto.NotifyCacheEmptyResult{
+				Populated: req.Channel == longChannel || req.Channel == "short",
+			},
+		})
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+		ProxyCommon: configtypes.ProxyCommon{
+			HTTP: configtypes.ProxyCommonHTTP{
+				CompressRequest: true,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: longChannel})
+	require.NoError(t, err)
+	require.True(t, resp.Result.Populated)
+
+	// Small payloads are sent uncompressed.
+	resp, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "short"})
+	require.NoError(t, err)
+	require.True(t, resp.Result.Populated)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"gzip", ""}, contentEncodings)
+}
+
+func TestNewHTTPCacheEmptyProxyUnknownEncoding(t *testing.T) {
+	_, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: "http://localhost",
+		Timeout:  configtypes.Duration(time.Second),
+		ProxyCommon: configtypes.ProxyCommon{
+			HTTP: configtypes.ProxyCommonHTTP{
+				Encoding: "xml",
+			},
+		},
+	})
+	require.Error(t, err)
+}
+
 func TestCacheEmptyHandlerHTTPError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}))
 	defer server.Close()
 
-	proxy, err := NewHTTPCacheEmptyProxy(Config{
-		Endpoint: server.URL,
+	proxy, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
 		Timeout:  configtypes.Duration(time.Second),
 	})
 	require.NoError(t, err)
 
-	handler := NewCacheEmptyHandler(CacheEmptyHandlerConfig{
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
 		Proxies: map[string]CacheEmptyProxy{
 			"test": proxy,
 		},
-	})
+	}).Handle()
 
 	_, err = handler(context.Background(), "test:channel")
 	require.Error(t, err)
@@ -99,18 +285,18 @@ func TestCacheEmptyHandlerConcurrency(t *testing.T) {
 	}))
 	defer server.Close()
 
-	proxy, err := NewHTTPCacheEmptyProxy(Config{
-		Endpoint: server.URL,
+	proxy, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
 		Timeout:  configtypes.Duration(5 * time.Second),
 	})
 	require.NoError(t, err)
 
-	handler := NewCacheEmptyHandler(CacheEmptyHandlerConfig{
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
 		Proxies: map[string]CacheEmptyProxy{
 			"test": proxy,
 		},
 		LockTimeout: 10 * time.Second, // Long enough for test
-	})
+	}).Handle()
 
 	// Launch 10 concurrent requests for the same channel
 	const numGoroutines = 10
@@ -160,18 +346,18 @@ func TestCacheEmptyHandlerLockTimeout(t *testing.T) {
 	}))
 	defer server.Close()
 
-	proxy, err := NewHTTPCacheEmptyProxy(Config{
-		Endpoint: server.URL,
+	proxy, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
 		Timeout:  configtypes.Duration(5 * time.Second),
 	})
 	require.NoError(t, err)
 
-	handler := NewCacheEmptyHandler(CacheEmptyHandlerConfig{
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
 		Proxies: map[string]CacheEmptyProxy{
 			"test": proxy,
 		},
 		LockTimeout: 500 * time.Millisecond, // Short timeout to trigger timeout behavior
-	})
+	}).Handle()
 
 	// Start first call that will hang
 	var wg sync.WaitGroup
@@ -228,17 +414,17 @@ func TestCacheEmptyHandlerDifferentChannels(t *testing.T) {
 	}))
 	defer server.Close()
 
-	proxy, err := NewHTTPCacheEmptyProxy(Config{
-		Endpoint: server.URL,
+	proxy, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
 		Timeout:  configtypes.Duration(5 * time.Second),
 	})
 	require.NoError(t, err)
 
-	handler := NewCacheEmptyHandler(CacheEmptyHandlerConfig{
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
 		Proxies: map[string]CacheEmptyProxy{
 			"test": proxy,
 		},
-	})
+	}).Handle()
 
 	// Launch concurrent requests for different channels
 	channels := []string{"channel1", "channel2", "channel3"}
@@ -268,7 +454,1039 @@ func TestCacheEmptyHandlerDifferentChannels(t *testing.T) {
 	}
 }
 
+type nilCacheEmptyProxy struct{}
+
+func (p *nilCacheEmptyProxy) ProxyCacheEmpty(context.Context, *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	return nil, nil
+}
+
+func (p *nilCacheEmptyProxy) Protocol() string  { return "test" }
+func (p *nilCacheEmptyProxy) UseBase64() bool   { return false }
+func (p *nilCacheEmptyProxy) IncludeMeta() bool { return false }
+
+func TestCacheEmptyHandlerNilResponse(t *testing.T) {
+	logs := captureLogs(t)
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"nil_test": &nilCacheEmptyProxy{}},
+	})
+	resp, err := h.Handle()(context.Background(), "test")
+	require.NoError(t, err)
+	require.NotNil(t, resp.Result)
+	require.False(t, resp.Result.Populated)
+	require.Contains(t, logs.String(), "cache empty proxy returned nil response without error")
+	require.Contains(t, logs.String(), `"proxy_name":"nil_test"`)
+	require.Equal(t, uint64(0), h.Stats()["nil_test"].ErrorCount)
+}
+
+type blockingCacheEmptyProxy struct {
+	started chan struct{}
+	release chan struct{}
+	closed  atomic.Bool
+}
+
+func newBlockingCacheEmptyProxy() *blockingCacheEmptyProxy {
+	return &blockingCacheEmptyProxy{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+}
+
+func (p *blockingCacheEmptyProxy) ProxyCacheEmpty(_ context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return &proxyproto.NotifyCacheEmptyResponse{
+		Result: &proxyproto.NotifyCacheEmptyResult{Populated: true},
+	}, nil
+}
+
+func (p *blockingCacheEmptyProxy) Protocol() string  { return "test" }
+func (p *blockingCacheEmptyProxy) UseBase64() bool   { return false }
+func (p *blockingCacheEmptyProxy) IncludeMeta() bool { return false }
+
+func (p *blockingCacheEmptyProxy) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+// ctxCacheEmptyProxy blocks until released or until call context is done.
+type ctxCacheEmptyProxy struct {
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int64
+}
+
+func (p *ctxCacheEmptyProxy) ProxyCacheEmpty(ctx context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	p.calls.Add(1)
+	close(p.started)
+	select {
+	case <-p.release:
+		return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: true}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *ctxCacheEmptyProxy) Protocol() string  { return "test" }
+func (p *ctxCacheEmptyProxy) UseBase64() bool   { return false }
+func (p *ctxCacheEmptyProxy) IncludeMeta() bool { return false }
+
+func TestCacheEmptyHandlerFirstCallerCancel(t *testing.T) {
+	p := &ctxCacheEmptyProxy{started: make(chan struct{}), release: make(chan struct{})}
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	}).Handle()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := handler(ctx, "test")
+		firstErr <- err
+	}()
+	<-p.started
+
+	type result struct {
+		resp *proxyproto.NotifyCacheEmptyResponse
+		err  error
+	}
+	waiterResult := make(chan result, 1)
+	go func() {
+		resp, err := handler(context.Background(), "test")
+		waiterResult <- result{resp, err}
+	}()
+
+	// First caller returns upon its own cancellation.
+	cancel()
+	require.ErrorIs(t, <-firstErr, context.Canceled)
+
+	// Let waiter join shared call before releasing it.
+	time.Sleep(20 * time.Millisecond)
+	close(p.release)
+	r := <-waiterResult
+	require.NoError(t, r.err)
+	require.True(t, r.resp.Result.Populated)
+	require.Equal(t, int64(1), p.calls.Load())
+}
+
+func TestCacheEmptyHandlerCallTimeout(t *testing.T) {
+	p := &ctxCacheEmptyProxy{started: make(chan struct{}), release: make(chan struct{})}
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:     map[string]CacheEmptyProxy{"test": p},
+		CallTimeout: 50 * time.Millisecond,
+	}).Handle()
+	_, err := handler(context.Background(), "test")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCacheEmptyHandlerNeverDuplicate(t *testing.T) {
+	p := &ctxCacheEmptyProxy{started: make(chan struct{}), release: make(chan struct{})}
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:        map[string]CacheEmptyProxy{"test": p},
+		LockTimeout:    20 * time.Millisecond,
+		NeverDuplicate: true,
+	}).Handle()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := handler(context.Background(), "test")
+			if err == nil && !resp.Result.Populated {
+				err = errors.New("not populated")
+			}
+			errs <- err
+		}()
+	}
+	<-p.started
+	// First call is much slower than LockTimeout.
+	time.Sleep(100 * time.Millisecond)
+	close(p.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(1), p.calls.Load())
+}
+
+func TestCacheEmptyHandlerNeverDuplicateCap(t *testing.T) {
+	// Proxy ignores context, so shared call outlives CallTimeout.
+	p := newBlockingCacheEmptyProxy()
+	defer close(p.release)
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:        map[string]CacheEmptyProxy{"test": p},
+		LockTimeout:    10 * time.Millisecond,
+		CallTimeout:    50 * time.Millisecond,
+		NeverDuplicate: true,
+	}).Handle()
+
+	go func() { _, _ = handler(context.Background(), "test") }()
+	<-p.started
+	started := time.Now()
+	_, err := handler(context.Background(), "test")
+	require.ErrorIs(t, err, ErrLockTimeout)
+	require.GreaterOrEqual(t, time.Since(started), 40*time.Millisecond)
+	select {
+	case <-p.started:
+		require.Fail(t, "unexpected second proxy call")
+	default:
+	}
+}
+
+// herdCacheEmptyProxy blocks the first call until released and records start time of others.
+type herdCacheEmptyProxy struct {
+	recordingCacheEmptyProxy
+	first   atomic.Bool
+	release chan struct{}
+
+	timesMu sync.Mutex
+	times   []time.Time
+}
+
+func (p *herdCacheEmptyProxy) ProxyCacheEmpty(_ context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	if p.first.CompareAndSwap(false, true) {
+		<-p.release
+	} else {
+		p.timesMu.Lock()
+		p.times = append(p.times, time.Now())
+		p.timesMu.Unlock()
+	}
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: true}}, nil
+}
+
+func TestCacheEmptyHandlerLockTimeoutJitter(t *testing.T) {
+	p := &herdCacheEmptyProxy{release: make(chan struct{})}
+	defer close(p.release)
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:           map[string]CacheEmptyProxy{"test": p},
+		LockTimeout:       200 * time.Millisecond,
+		LockTimeoutJitter: 0.5,
+	}).Handle()
+
+	go func() { _, _ = handler(context.Background(), "test:channel") }()
+	require.Eventually(t, p.first.Load, time.Second, time.Millisecond)
+
+	const numWaiters = 50
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < numWaiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := handler(context.Background(), "test:channel")
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	p.timesMu.Lock()
+	defer p.timesMu.Unlock()
+	require.Len(t, p.times, numWaiters)
+	earliest, latest := p.times[0], p.times[0]
+	for _, tm := range p.times {
+		if tm.Before(earliest) {
+			earliest = tm
+		}
+		if tm.After(latest) {
+			latest = tm
+		}
+	}
+	// Timeouts are spread over [100ms, 300ms], so independent calls are not simultaneous.
+	require.Greater(t, latest.Sub(earliest), 50*time.Millisecond)
+	require.GreaterOrEqual(t, earliest.Sub(started), 100*time.Millisecond)
+}
+
+func TestCacheEmptyHandlerWaitLockTimeout(t *testing.T) {
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{LockTimeout: time.Second})
+	require.Equal(t, time.Second, h.waitLockTimeout())
+
+	h = newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{LockTimeout: time.Second, LockTimeoutJitter: 0.2})
+	for i := 0; i < 100; i++ {
+		timeout := h.waitLockTimeout()
+		require.GreaterOrEqual(t, timeout, 800*time.Millisecond)
+		require.LessOrEqual(t, timeout, 1200*time.Millisecond)
+	}
+
+	// Jitter is clamped to [0, 1].
+	h = newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{LockTimeout: time.Second, LockTimeoutJitter: 5})
+	require.Equal(t, 1.0, h.lockJitter)
+}
+
+func TestCacheEmptyHandlerCloseWaitsInflight(t *testing.T) {
+	proxy := newBlockingCacheEmptyProxy()
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			"test": proxy,
+		},
+	})
+	handler := h.Handle()
+
+	callDone := make(chan error, 1)
+	go func() {
+		_, err := handler(context.Background(), "test:channel")
+		callDone <- err
+	}()
+	<-proxy.started
+
+	closeDone := make(chan error, 1)
+	go func() {
+		closeDone <- h.Close(context.Background())
+	}()
+
+	select {
+	case <-closeDone:
+		t.Fatal("close returned before in-flight call finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// New calls are rejected while draining.
+	_, err := handler(context.Background(), "other:channel")
+	require.ErrorIs(t, err, ErrCacheEmptyHandlerClosed)
+
+	close(proxy.release)
+	require.NoError(t, <-callDone)
+	require.NoError(t, <-closeDone)
+	require.True(t, proxy.closed.Load())
+
+	_, err = handler(context.Background(), "test:channel")
+	require.ErrorIs(t, err, ErrCacheEmptyHandlerClosed)
+}
+
+func TestCacheEmptyHandlerCloseTimeout(t *testing.T) {
+	proxy := newBlockingCacheEmptyProxy()
+	defer close(proxy.release)
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			"test": proxy,
+		},
+	})
+
+	go func() {
+		_, _ = h.Handle()(context.Background(), "test:channel")
+	}()
+	<-proxy.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := h.Close(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, proxy.closed.Load())
+}
+
+type scriptedCacheEmptyProxy struct {
+	errs []error
+	call int
+}
+
+func (p *scriptedCacheEmptyProxy) ProxyCacheEmpty(_ context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	err := p.errs[p.call%len(p.errs)]
+	p.call++
+	if err != nil {
+		return nil, err
+	}
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+}
+
+func (p *scriptedCacheEmptyProxy) Protocol() string  { return "test" }
+func (p *scriptedCacheEmptyProxy) UseBase64() bool   { return false }
+func (p *scriptedCacheEmptyProxy) IncludeMeta() bool { return false }
+
+func TestCacheEmptyHandlerStats(t *testing.T) {
+	p := &scriptedCacheEmptyProxy{errs: []error{nil, errors.New("boom 1"), nil, errors.New("boom 2"), nil}}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			"test": p,
+		},
+	})
+	stats := h.Stats()
+	require.Equal(t, CacheEmptyProxyStats{}, stats["test"])
+
+	handler := h.Handle()
+	started := time.Now()
+	for i := 0; i < 4; i++ {
+		_, _ = handler(context.Background(), "test:channel")
+	}
+	stats = h.Stats()
+	require.Len(t, stats, 1)
+	s := stats["test"]
+	require.Equal(t, uint64(4), s.TotalCalls)
+	require.Equal(t, uint64(2), s.ErrorCount)
+	require.Equal(t, "boom 2", s.LastError)
+	require.False(t, s.LastErrorTime.Before(started))
+	require.False(t, s.LastSuccessTime.Before(started))
+	lastSuccess := s.LastSuccessTime
+
+	_, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	s = h.Stats()["test"]
+	require.Equal(t, uint64(5), s.TotalCalls)
+	require.Equal(t, uint64(2), s.ErrorCount)
+	require.Equal(t, "boom 2", s.LastError)
+	require.False(t, s.LastSuccessTime.Before(lastSuccess))
+}
+
+func TestCacheEmptyHandlerStaleOnError(t *testing.T) {
+	p := &scriptedCacheEmptyProxy{errs: []error{nil, errors.New("boom"), errors.New("boom")}}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:      map[string]CacheEmptyProxy{"stale_test": p},
+		StaleOnError: true,
+		StaleWindow:  200 * time.Millisecond,
+	})
+	handler := h.Handle()
+	proxyResults := testutil.ToFloat64(proxyCacheEmptyResultCount.WithLabelValues("stale_test", "proxy"))
+	staleResults := testutil.ToFloat64(proxyCacheEmptyResultCount.WithLabelValues("stale_test", "stale"))
+
+	resp, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+
+	// Backend fails, last good result is returned while stale window is valid.
+	staleResp, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.Same(t, resp, staleResp)
+
+	// No stale result for other channel.
+	_, err = handler(context.Background(), "test:other")
+	require.Error(t, err)
+
+	s := h.Stats()["stale_test"]
+	require.Equal(t, uint64(3), s.TotalCalls)
+	require.Equal(t, uint64(2), s.ErrorCount)
+	require.Equal(t, uint64(1), s.StaleResults)
+	require.Equal(t, proxyResults+1, testutil.ToFloat64(proxyCacheEmptyResultCount.WithLabelValues("stale_test", "proxy")))
+	require.Equal(t, staleResults+1, testutil.ToFloat64(proxyCacheEmptyResultCount.WithLabelValues("stale_test", "stale")))
+
+	// Stale window passed.
+	p.errs = []error{errors.New("boom")}
+	time.Sleep(250 * time.Millisecond)
+	_, err = handler(context.Background(), "test:channel")
+	require.Error(t, err)
+	require.Equal(t, uint64(1), h.Stats()["stale_test"].StaleResults)
+}
+
+func TestCacheEmptyHandlerStaleOnErrorDisabled(t *testing.T) {
+	p := &scriptedCacheEmptyProxy{errs: []error{nil, errors.New("boom")}}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	})
+	handler := h.Handle()
+	_, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	_, err = handler(context.Background(), "test:channel")
+	require.Error(t, err)
+	require.Zero(t, h.Stats()["test"].StaleResults)
+}
+
+func TestCacheEmptyHandlerStaleResultsSweep(t *testing.T) {
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:      map[string]CacheEmptyProxy{"test": &scriptedCacheEmptyProxy{errs: []error{nil}}},
+		StaleOnError: true,
+		StaleWindow:  50 * time.Millisecond,
+	})
+	resp := &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}
+	h.storeStaleResult("inactive", resp)
+	time.Sleep(60 * time.Millisecond)
+	h.storeStaleResult("active", resp)
+	_, ok := h.staleResults.Load("inactive")
+	require.False(t, ok)
+	_, ok = h.staleResults.Load("active")
+	require.True(t, ok)
+}
+
+func TestCacheEmptyHandlerObserveOnly(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	defer server.Close()
+	p, err := NewHTTPCacheEmptyProxy("observe_test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:     map[string]CacheEmptyProxy{"observe_test": p},
+		ObserveOnly: true,
+	})
+	handler := h.Handle()
+	buf := captureLogs(t)
+
+	// Backend populated cache, but caller gets the default result.
+	resp, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.False(t, resp.GetResult().GetPopulated())
+	require.Equal(t, int64(1), calls.Load())
+	require.Equal(t, float64(1), testutil.ToFloat64(proxyCacheEmptyObservedResultCount.WithLabelValues("observe_test", "populated")))
+	require.Contains(t, buf.String(), `"result":"populated"`)
+
+	// Proxy error is not returned too.
+	resp, err = handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.False(t, resp.GetResult().GetPopulated())
+	require.Equal(t, int64(2), calls.Load())
+	require.Equal(t, float64(1), testutil.ToFloat64(proxyCacheEmptyObservedResultCount.WithLabelValues("observe_test", "error")))
+
+	s := h.Stats()["observe_test"]
+	require.Equal(t, uint64(2), s.TotalCalls)
+	require.Equal(t, uint64(1), s.ErrorCount)
+}
+
+func TestCacheEmptyProxyCountersRecordSuccessNoAllocs(t *testing.T) {
+	c := newCacheEmptyProxyCounters(time.Minute)
+	allocs := testing.AllocsPerRun(100, func() {
+		c.record(nil)
+	})
+	require.Zero(t, allocs)
+}
+
+func TestCacheEmptyHandlerNoProxies(t *testing.T) {
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{Proxies: map[string]CacheEmptyProxy{}}).Handle()
+	resp, err := handler(context.Background(), "test:channel")
+	require.ErrorIs(t, err, ErrNoCacheEmptyProxy)
+	require.Nil(t, resp)
+}
+
+func TestNewCacheEmptyHandlerNilProxy(t *testing.T) {
+	_, err := NewCacheEmptyHandler(CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"a": &recordingCacheEmptyProxy{}, "test": nil},
+	})
+	require.ErrorContains(t, err, "cache empty proxy test is nil")
+}
+
+func TestCacheEmptyHandlerNotPopulated(t *testing.T) {
+	for name, body := range map[string]string{
+		"explicit populated false": `{"result":{"populated":false}}`,
+		"missing result":           `{}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			p, err := NewHTTPCacheEmptyProxy("test", Config{
+				Endpoint: configtypes.String(server.URL),
+				Timeout:  configtypes.Duration(time.Second),
+			})
+			require.NoError(t, err)
+
+			handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+				Proxies: map[string]CacheEmptyProxy{
+					"test": p,
+				},
+			}).Handle()
+			resp, err := handler(context.Background(), "test:channel")
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			require.NotNil(t, resp.Result)
+			require.False(t, resp.Result.Populated)
+		})
+	}
+}
+
+type recordingCacheEmptyProxy struct {
+	mu       sync.Mutex
+	channels []string
+}
+
+func (p *recordingCacheEmptyProxy) ProxyCacheEmpty(_ context.Context, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	p.mu.Lock()
+	p.channels = append(p.channels, req.Channel)
+	p.mu.Unlock()
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{Populated: true}}, nil
+}
+
+func (p *recordingCacheEmptyProxy) Protocol() string  { return "test" }
+func (p *recordingCacheEmptyProxy) UseBase64() bool   { return false }
+func (p *recordingCacheEmptyProxy) IncludeMeta() bool { return false }
+
+func (p *recordingCacheEmptyProxy) seen() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.channels...)
+}
+
+func TestCacheEmptyHandlerNamespaceRouting(t *testing.T) {
+	cfg := config.DefaultConfig()
+	backend := configtypes.Proxy{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(time.Second)}
+	cfg.Channel.Proxy.CacheEmpty = backend
+	cfg.Proxies = configtypes.NamedProxies{{Name: "p1", Proxy: backend}, {Name: "p2", Proxy: backend}}
+	cfg.Channel.WithoutNamespace.CacheEmptyProxyEnabled = true
+	cfg.Channel.WithoutNamespace.CacheEmptyProxyName = config.DefaultProxyName
+	cfg.Channel.Namespaces = []configtypes.ChannelNamespace{
+		{
+			Name: "ns1",
+			ChannelOptions: configtypes.ChannelOptions{
+				CacheEmptyProxyEnabled: true,
+				CacheEmptyProxyName:    "p1",
+			},
+		},
+		{
+			Name: "ns2",
+			ChannelOptions: configtypes.ChannelOptions{
+				CacheEmptyProxyEnabled: true,
+				CacheEmptyProxyName:    "p2",
+			},
+		},
+		{
+			// Falls back to default proxy.
+			Name: "ns3",
+			ChannelOptions: configtypes.ChannelOptions{
+				CacheEmptyProxyEnabled: true,
+				CacheEmptyProxyName:    config.DefaultProxyName,
+			},
+		},
+		{
+			Name:           "disabled",
+			ChannelOptions: configtypes.ChannelOptions{},
+		},
+	}
+	cfgContainer, err := config.NewContainer(cfg)
+	require.NoError(t, err)
+
+	defaultProxy, p1, p2 := &recordingCacheEmptyProxy{}, &recordingCacheEmptyProxy{}, &recordingCacheEmptyProxy{}
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			config.DefaultProxyName: defaultProxy,
+			"p1":                    p1,
+			"p2":                    p2,
+		},
+		ProxyName: func(channel string) (string, bool) {
+			_, _, chOpts, found, err := cfgContainer.ChannelOptions(channel)
+			if err != nil || !found || !chOpts.CacheEmptyProxyEnabled {
+				return "", false
+			}
+			return chOpts.CacheEmptyProxyName, true
+		},
+	}).Handle()
+
+	for _, ch := range []string{"ns1:foo", "ns2:bar", "ns1:baz", "ns3:qux", "plain"} {
+		resp, err := handler(context.Background(), ch)
+		require.NoError(t, err, ch)
+		require.True(t, resp.Result.Populated, ch)
+	}
+	for _, ch := range []string{"disabled:foo", "unknown:foo"} {
+		_, err := handler(context.Background(), ch)
+		require.ErrorIs(t, err, ErrNoCacheEmptyProxy, ch)
+	}
+
+	require.Equal(t, []string{"ns1:foo", "ns1:baz"}, p1.seen())
+	require.Equal(t, []string{"ns2:bar"}, p2.seen())
+	require.Equal(t, []string{"ns3:qux", "plain"}, defaultProxy.seen())
+}
+
+func TestCacheEmptyHandlerDeterministicDefaultProxy(t *testing.T) {
+	p1, p2, p3 := &recordingCacheEmptyProxy{}, &recordingCacheEmptyProxy{}, &recordingCacheEmptyProxy{}
+	for i := 0; i < 20; i++ {
+		// Construct handler every time so that proxies map is iterated in different order.
+		handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+			Proxies: map[string]CacheEmptyProxy{"b": p2, "a": p1, "c": p3},
+		}).Handle()
+		_, err := handler(context.Background(), "test")
+		require.NoError(t, err)
+	}
+	require.Len(t, p1.seen(), 20)
+	require.Empty(t, p2.seen())
+	require.Empty(t, p3.seen())
+}
+
+func TestCacheEmptyHandlerFallbackMode(t *testing.T) {
+	proxyName := func(channel string) (string, bool) {
+		if strings.HasPrefix(channel, "ns1:") {
+			return "p1", true
+		}
+		if strings.HasPrefix(channel, "missing:") {
+			return "missing", true
+		}
+		return "", false
+	}
+	newHandler := func(defaultProxy string, mode CacheEmptyFallbackMode) (CacheEmptyHandlerFunc, *recordingCacheEmptyProxy, *recordingCacheEmptyProxy) {
+		p1, fallback := &recordingCacheEmptyProxy{}, &recordingCacheEmptyProxy{}
+		return newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+			Proxies:      map[string]CacheEmptyProxy{"p1": p1, "fallback": fallback},
+			ProxyName:    proxyName,
+			DefaultProxy: defaultProxy,
+			FallbackMode: mode,
+		}).Handle(), p1, fallback
+	}
+
+	t.Run("default", func(t *testing.T) {
+		handler, p1, fallback := newHandler("fallback", CacheEmptyFallbackDefault)
+		for _, ch := range []string{"ns1:a", "ns2:b", "missing:c"} {
+			resp, err := handler(context.Background(), ch)
+			require.NoError(t, err, ch)
+			require.True(t, resp.Result.Populated, ch)
+		}
+		require.Equal(t, []string{"ns1:a"}, p1.seen())
+		require.Equal(t, []string{"ns2:b", "missing:c"}, fallback.seen())
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		handler, p1, fallback := newHandler("fallback", CacheEmptyFallbackSkip)
+		for _, ch := range []string{"ns2:b", "missing:c"} {
+			resp, err := handler(context.Background(), ch)
+			require.NoError(t, err, ch)
+			require.False(t, resp.Result.Populated, ch)
+		}
+		_, err := handler(context.Background(), "ns1:a")
+		require.NoError(t, err)
+		require.Equal(t, []string{"ns1:a"}, p1.seen())
+		require.Empty(t, fallback.seen())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		handler, _, fallback := newHandler("fallback", CacheEmptyFallbackError)
+		for _, ch := range []string{"ns2:b", "missing:c"} {
+			_, err := handler(context.Background(), ch)
+			require.ErrorIs(t, err, ErrNoCacheEmptyProxy, ch)
+		}
+		require.Empty(t, fallback.seen())
+	})
+
+	t.Run("unknown default proxy", func(t *testing.T) {
+		logs := captureLogs(t)
+		handler, _, fallback := newHandler("unknown", CacheEmptyFallbackDefault)
+		require.Contains(t, logs.String(), "default cache empty proxy not found")
+		_, err := handler(context.Background(), "ns2:b")
+		require.ErrorIs(t, err, ErrNoCacheEmptyProxy)
+		require.Empty(t, fallback.seen())
+	})
+}
+
+func TestCacheEmptyHandlerClientInfoHTTP(t *testing.T) {
+	bodies := make(chan map[string]json.RawMessage, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies <- body
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	defer server.Close()
+
+	clientCtx := WithCacheEmptyClient(context.Background(), CacheEmptyClient{
+		ClientID: "client-id",
+		UserID:   "42",
+		Meta:     json.RawMessage(`{"plan":"pro"}`),
+	})
+
+	for name, tc := range map[string]struct {
+		ctx            context.Context
+		includeMeta    bool
+		binary         bool
+		expected       map[string]string
+		expectedAbsent []string
+	}{
+		"client with meta": {
+			ctx:         clientCtx,
+			includeMeta: true,
+			expected:    map[string]string{"client": `"client-id"`, "user": `"42"`, "meta": `{"plan":"pro"}`, "encoding": `"json"`},
+		},
+		"client without meta": {
+			ctx:            clientCtx,
+			binary:         true,
+			expected:       map[string]string{"client": `"client-id"`, "user": `"42"`, "encoding": `"binary"`},
+			expectedAbsent: []string{"meta"},
+		},
+		"no client": {
+			ctx:            context.Background(),
+			includeMeta:    true,
+			expected:       map[string]string{"encoding": `"json"`},
+			expectedAbsent: []string{"client", "user", "meta"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{
+				Endpoint: configtypes.String(server.URL),
+				Timeout:  configtypes.Duration(time.Second),
+			}
+			cfg.IncludeConnectionMeta = tc.includeMeta
+			cfg.BinaryEncoding = tc.binary
+			p, err := NewHTTPCacheEmptyProxy("test", cfg)
+			require.NoError(t, err)
+			handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+				Proxies: map[string]CacheEmptyProxy{"test": p},
+			}).Handle()
+
+			_, err = handler(tc.ctx, "test:channel")
+			require.NoError(t, err)
+			body := <-bodies
+			require.Equal(t, `"test:channel"`, string(body["channel"]))
+			for k, v := range tc.expected {
+				require.JSONEq(t, v, string(body[k]), k)
+			}
+			for _, k := range tc.expectedAbsent {
+				require.NotContains(t, body, k)
+			}
+		})
+	}
+}
+
+type batchCacheEmptyProxy struct {
+	recordingCacheEmptyProxy
+	window      time.Duration
+	maxSize     int
+	unsupported bool
+
+	batchMu sync.Mutex
+	batches [][]string
+}
+
+func (p *batchCacheEmptyProxy) ProxyCacheEmptyBatch(_ context.Context, req *proxyproto.NotifyCacheEmptyBatchRequest) (*proxyproto.NotifyCacheEmptyBatchResponse, error) {
+	p.batchMu.Lock()
+	p.batches = append(p.batches, req.Channels)
+	p.batchMu.Unlock()
+	if p.unsupported {
+		return nil, ErrCacheEmptyBatchNotSupported
+	}
+	resp := &proxyproto.NotifyCacheEmptyBatchResponse{}
+	for _, ch := range req.Channels {
+		if strings.HasSuffix(ch, ":missing") {
+			continue
+		}
+		resp.Results = append(resp.Results, &proxyproto.NotifyCacheEmptyBatchResult{
+			Channel:   ch,
+			Populated: strings.HasSuffix(ch, ":populated"),
+		})
+	}
+	return resp, nil
+}
+
+func (p *batchCacheEmptyProxy) BatchWindow() time.Duration { return p.window }
+func (p *batchCacheEmptyProxy) BatchMaxSize() int          { return p.maxSize }
+
+func (p *batchCacheEmptyProxy) seenBatches() [][]string {
+	p.batchMu.Lock()
+	defer p.batchMu.Unlock()
+	return append([][]string(nil), p.batches...)
+}
+
+// handleConcurrently calls handler for all channels at once and returns results by channel.
+func handleConcurrently(t *testing.T, handler CacheEmptyHandlerFunc, channels []string) map[string]bool {
+	t.Helper()
+	var mu sync.Mutex
+	populated := map[string]bool{}
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := handler(context.Background(), ch)
+			require.NoError(t, err, ch)
+			mu.Lock()
+			populated[ch] = resp.Result.Populated
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return populated
+}
+
+func TestCacheEmptyHandlerBatch(t *testing.T) {
+	p := &batchCacheEmptyProxy{window: 100 * time.Millisecond}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	})
+
+	var channels []string
+	expected := map[string]bool{}
+	for i := 0; i < 30; i++ {
+		suffix := []string{"populated", "not_populated", "missing"}[i%3]
+		ch := fmt.Sprintf("test:%d:%s", i, suffix)
+		channels = append(channels, ch)
+		expected[ch] = suffix == "populated"
+	}
+	require.Equal(t, expected, handleConcurrently(t, h.Handle(), channels))
+
+	batches := p.seenBatches()
+	require.Len(t, batches, 1)
+	require.ElementsMatch(t, channels, batches[0])
+	require.Empty(t, p.seen())
+	require.Equal(t, uint64(1), h.Stats()["test"].TotalCalls)
+}
+
+func TestCacheEmptyHandlerBatchMaxSize(t *testing.T) {
+	p := &batchCacheEmptyProxy{window: 100 * time.Millisecond, maxSize: 5}
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	}).Handle()
+
+	var channels []string
+	for i := 0; i < 12; i++ {
+		channels = append(channels, fmt.Sprintf("test:%d:populated", i))
+	}
+	for ch, populated := range handleConcurrently(t, handler, channels) {
+		require.True(t, populated, ch)
+	}
+
+	var sizes []int
+	var batched []string
+	for _, batch := range p.seenBatches() {
+		sizes = append(sizes, len(batch))
+		batched = append(batched, batch...)
+	}
+	require.ElementsMatch(t, []int{5, 5, 2}, sizes)
+	require.ElementsMatch(t, channels, batched)
+}
+
+func TestCacheEmptyHandlerBatchNotSupported(t *testing.T) {
+	p := &batchCacheEmptyProxy{window: 50 * time.Millisecond, unsupported: true}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	})
+
+	channels := []string{"test:1", "test:2", "test:3"}
+	for ch, populated := range handleConcurrently(t, h.Handle(), channels) {
+		require.True(t, populated, ch)
+	}
+	require.Len(t, p.seenBatches(), 1)
+	require.ElementsMatch(t, channels, p.seen())
+
+	// Batching is not attempted anymore.
+	_, err := h.Handle()(context.Background(), "test:4")
+	require.NoError(t, err)
+	require.Len(t, p.seenBatches(), 1)
+	require.ElementsMatch(t, append(channels, "test:4"), p.seen())
+	// Only per-channel calls are counted since batch was not processed by backend.
+	require.Equal(t, uint64(4), h.Stats()["test"].TotalCalls)
+}
+
 func TestCacheEmptyHandlerGRPC(t *testing.T) {
-	// Skip gRPC test for now - would require more complex setup
-	t.Skip("gRPC test not implemented yet")
+	counter, cfg := newBufconnCacheEmptyServer(t)
+	cfg.GRPC.EagerConnect = true
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{"test": p},
+	}).Handle()
+	resp, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.NotNil(t, resp.Result)
+	require.False(t, resp.Result.Populated)
+	require.Equal(t, int64(1), counter.calls.Load())
+}
+
+func TestCacheEmptyHandlerNoCache(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"result":{"no_cache":true}}`))
+	}))
+	defer server.Close()
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	proxyCacheEmptyResultCount.Reset()
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:    map[string]CacheEmptyProxy{"test": p},
+		NoCacheTTL: 50 * time.Millisecond,
+	}).Handle()
+	resp, err := handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.True(t, resp.Result.NoCache)
+	require.Equal(t, int64(1), calls.Load())
+
+	// Proxy is not called for the channel within NoCacheTTL.
+	for i := 0; i < 3; i++ {
+		resp, err = handler(context.Background(), "test:channel")
+		require.NoError(t, err)
+		require.False(t, resp.Result.Populated)
+		require.False(t, resp.Result.NoCache)
+	}
+	require.Equal(t, int64(1), calls.Load())
+	require.Equal(t, float64(3), testutil.ToFloat64(proxyCacheEmptyResultCount.WithLabelValues("test", "no_cache")))
+
+	// Other channels are not affected.
+	_, err = handler(context.Background(), "test:other")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = handler(context.Background(), "test:channel")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), calls.Load())
+}
+
+func TestCacheEmptyHandlerProxySelector(t *testing.T) {
+	a, b := &recordingCacheEmptyProxy{}, &recordingCacheEmptyProxy{}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		// The same proxy may be registered under several names.
+		Proxies: map[string]CacheEmptyProxy{"a": a, "b": b, "b2": b},
+		// Selector takes precedence over ProxyName.
+		ProxyName: func(string) (string, bool) { return "a", true },
+		ProxySelector: func(channel string) (string, bool) {
+			switch {
+			case strings.HasPrefix(channel, "a:"):
+				return "a", true
+			case strings.HasPrefix(channel, "b:"):
+				return "b", true
+			case strings.HasPrefix(channel, "b2:"):
+				return "b2", true
+			case strings.HasPrefix(channel, "unknown:"):
+				return "unknown", true
+			}
+			return "", false
+		},
+	})
+	handler := h.Handle()
+	for _, ch := range []string{"a:1", "b:1", "a:2", "b2:2"} {
+		resp, err := handler(context.Background(), ch)
+		require.NoError(t, err, ch)
+		require.True(t, resp.Result.Populated, ch)
+	}
+	require.Equal(t, []string{"a:1", "a:2"}, a.seen())
+	require.Equal(t, []string{"b:1", "b2:2"}, b.seen())
+	require.Equal(t, uint64(1), h.Stats()["b"].TotalCalls)
+	require.Equal(t, uint64(1), h.Stats()["b2"].TotalCalls)
+
+	// No proxy applies.
+	resp, err := handler(context.Background(), "c:1")
+	require.NoError(t, err)
+	require.False(t, resp.Result.Populated)
+
+	// Proxy missing in Proxies.
+	_, err = handler(context.Background(), "unknown:1")
+	require.ErrorIs(t, err, ErrNoCacheEmptyProxy)
+	require.Len(t, a.seen(), 2)
+	require.Len(t, b.seen(), 2)
+}
+
+func TestCacheEmptyHandlerProxySelectorFallback(t *testing.T) {
+	selector := func(channel string) (string, bool) {
+		name, _, _ := strings.Cut(channel, ":")
+		return name, true
+	}
+	newHandler := func(mode CacheEmptyFallbackMode) (CacheEmptyHandlerFunc, *recordingCacheEmptyProxy) {
+		a := &recordingCacheEmptyProxy{}
+		return newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+			Proxies:       map[string]CacheEmptyProxy{"a": a},
+			ProxySelector: selector,
+			DefaultProxy:  "a",
+			FallbackMode:  mode,
+		}).Handle(), a
+	}
+
+	handler, a := newHandler(CacheEmptyFallbackDefault)
+	resp, err := handler(context.Background(), "missing:1")
+	require.NoError(t, err)
+	require.True(t, resp.Result.Populated)
+	require.Equal(t, []string{"missing:1"}, a.seen())
+
+	handler, a = newHandler(CacheEmptyFallbackSkip)
+	resp, err = handler(context.Background(), "missing:1")
+	require.NoError(t, err)
+	require.False(t, resp.Result.Populated)
+	require.Empty(t, a.seen())
+
+	handler, _ = newHandler(CacheEmptyFallbackError)
+	_, err = handler(context.Background(), "missing:1")
+	require.ErrorIs(t, err, ErrNoCacheEmptyProxy)
 }
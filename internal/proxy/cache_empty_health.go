@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHealthWindow       = time.Minute
+	defaultHealthMaxErrorRate = 0.5
+	healthWindowBuckets       = 10
+)
+
+// cacheEmptyCallWindow counts proxy calls and failures over a sliding window split into
+// healthWindowBuckets buckets.
+type cacheEmptyCallWindow struct {
+	bucketSize time.Duration
+
+	mu      sync.Mutex
+	buckets [healthWindowBuckets]cacheEmptyCallBucket
+}
+
+type cacheEmptyCallBucket struct {
+	index  int64 // Number of bucket since Unix epoch.
+	calls  uint64
+	errors uint64
+}
+
+func newCacheEmptyCallWindow(window time.Duration) *cacheEmptyCallWindow {
+	return &cacheEmptyCallWindow{bucketSize: max(window/healthWindowBuckets, time.Millisecond)}
+}
+
+// record counts call result. Calls canceled by caller are not counted, and only errors which
+// mean that backend is unavailable (see ErrorClass.Retryable) are counted as failures – backend
+// which deliberately rejects calls is still healthy.
+func (w *cacheEmptyCallWindow) record(now time.Time, err error) {
+	class := ClassifyError(err, nil)
+	if class == ErrorClassCanceled {
+		return
+	}
+	index := now.UnixNano() / int64(w.bucketSize)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := &w.buckets[index%healthWindowBuckets]
+	if b.index != index {
+		*b = cacheEmptyCallBucket{index: index}
+	}
+	b.calls++
+	if class.Retryable() {
+		b.errors++
+	}
+}
+
+// totals returns number of calls and failures within window ending at now.
+func (w *cacheEmptyCallWindow) totals(now time.Time) (calls uint64, failures uint64) {
+	index := now.UnixNano() / int64(w.bucketSize)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range w.buckets {
+		if b.index > index-healthWindowBuckets && b.index <= index {
+			calls += b.calls
+			failures += b.errors
+		}
+	}
+	return calls, failures
+}
+
+// CacheEmptyProxyHealth describes health of cache empty proxy over HealthWindow.
+type CacheEmptyProxyHealth struct {
+	Healthy   bool    `json:"healthy"`
+	Calls     uint64  `json:"calls"`
+	Errors    uint64  `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// Health returns health of each configured proxy by proxy name and whether all of them are
+// healthy. Proxy is unhealthy when at least HealthMinCalls calls were made within HealthWindow
+// and share of failed ones exceeds HealthMaxErrorRate.
+func (h *CacheEmptyHandler) Health() (map[string]CacheEmptyProxyHealth, bool) {
+	now := time.Now()
+	result := make(map[string]CacheEmptyProxyHealth, len(h.counters))
+	healthy := true
+	for name, c := range h.counters {
+		calls, failures := c.window.totals(now)
+		ph := CacheEmptyProxyHealth{Healthy: true, Calls: calls, Errors: failures}
+		if calls > 0 {
+			ph.ErrorRate = float64(failures) / float64(calls)
+		}
+		if calls >= uint64(h.healthMinCalls) && ph.ErrorRate > h.healthMaxErrorRate {
+			ph.Healthy = false
+			healthy = false
+		}
+		result[name] = ph
+	}
+	return result, healthy
+}
+
+// HealthHandler returns http.Handler which responds with 200 if all proxies are healthy and
+// with 503 otherwise – may be used as readiness probe. Response body contains JSON with health
+// of each proxy.
+func (h *CacheEmptyHandler) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		proxies, healthy := h.Health()
+		status := "ok"
+		code := http.StatusOK
+		if !healthy {
+			status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+		data, _ := json.Marshal(struct {
+			Status  string                           `json:"status"`
+			Proxies map[string]CacheEmptyProxyHealth `json:"proxies"`
+		}{Status: status, Proxies: proxies})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = w.Write(data)
+	})
+}
@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func healthStatus(t *testing.T, h *CacheEmptyHandler) (int, map[string]CacheEmptyProxyHealth) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	h.HealthHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var body struct {
+		Status  string                           `json:"status"`
+		Proxies map[string]CacheEmptyProxyHealth `json:"proxies"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	if rr.Code == http.StatusOK {
+		require.Equal(t, "ok", body.Status)
+	} else {
+		require.Equal(t, "unavailable", body.Status)
+	}
+	return rr.Code, body.Proxies
+}
+
+func TestCacheEmptyHandlerHealthHandler(t *testing.T) {
+	errBackend := errors.New("connection refused")
+	p := &scriptedCacheEmptyProxy{errs: []error{nil, nil, nil, errBackend, errBackend, errBackend, errBackend}}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:        map[string]CacheEmptyProxy{"test": p},
+		HealthWindow:   200 * time.Millisecond,
+		HealthMinCalls: 2,
+	})
+	handler := h.Handle()
+
+	// No calls yet.
+	code, proxies := healthStatus(t, h)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, CacheEmptyProxyHealth{Healthy: true}, proxies["test"])
+
+	for i := 0; i < 3; i++ {
+		_, err := handler(context.Background(), "test")
+		require.NoError(t, err)
+	}
+	code, _ = healthStatus(t, h)
+	require.Equal(t, http.StatusOK, code)
+
+	for i := 0; i < 4; i++ {
+		_, err := handler(context.Background(), "test")
+		require.ErrorIs(t, err, errBackend)
+	}
+	code, proxies = healthStatus(t, h)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, CacheEmptyProxyHealth{Calls: 7, Errors: 4, ErrorRate: 4.0 / 7}, proxies["test"])
+
+	// Failures leave window.
+	require.Eventually(t, func() bool {
+		code, _ := healthStatus(t, h)
+		return code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCacheEmptyHandlerHealthMinCalls(t *testing.T) {
+	p := &scriptedCacheEmptyProxy{errs: []error{errors.New("boom")}}
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:        map[string]CacheEmptyProxy{"test": p},
+		HealthMinCalls: 3,
+	})
+	handler := h.Handle()
+	for i := 0; i < 3; i++ {
+		_, healthy := h.Health()
+		require.True(t, healthy, i)
+		_, _ = handler(context.Background(), "test")
+	}
+	_, healthy := h.Health()
+	require.False(t, healthy)
+}
+
+func TestCacheEmptyCallWindowIgnoresNonBackendErrors(t *testing.T) {
+	w := newCacheEmptyCallWindow(time.Minute)
+	now := time.Now()
+	w.record(now, nil)
+	w.record(now, context.Canceled)
+	w.record(now, newTransformedError(errors.New("status 403"), &proxyproto.Error{Code: 403}, nil))
+	w.record(now, &statusCodeError{Code: http.StatusBadRequest})
+	w.record(now, &statusCodeError{Code: http.StatusBadGateway})
+	calls, failures := w.totals(now)
+	require.Equal(t, uint64(4), calls)
+	require.Equal(t, uint64(1), failures)
+
+	calls, failures = w.totals(now.Add(time.Minute))
+	require.Zero(t, calls)
+	require.Zero(t, failures)
+}
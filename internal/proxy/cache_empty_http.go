@@ -3,8 +3,12 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/rs/zerolog"
 )
 
 // CacheEmptyRequestHTTP ...
@@ -14,35 +18,119 @@ type CacheEmptyRequestHTTP struct {
 
 // HTTPCacheEmptyProxy ...
 type HTTPCacheEmptyProxy struct {
+	name       string
 	config     Config
+	endpoint   channelEndpoint
+	httpClient *http.Client
 	httpCaller HTTPCaller
+	encoder    ProxyEncoder
+	decoder    ProxyDecoder
+	log        *zerolog.Logger
+	limiter    callLimiter
+
+	interceptor *CacheEmptyInterceptor
 }
 
 var _ CacheEmptyProxy = (*HTTPCacheEmptyProxy)(nil)
+var _ WarmupProxy = (*HTTPCacheEmptyProxy)(nil)
 
-// NewHTTPCacheEmptyProxy ...
-func NewHTTPCacheEmptyProxy(p Config) (*HTTPCacheEmptyProxy, error) {
+// NewHTTPCacheEmptyProxy ... Endpoint may contain {channel} placeholder which is substituted
+// with URL-escaped channel, like https://backend/channels/{channel}/populate.
+func NewHTTPCacheEmptyProxy(name string, p Config) (*HTTPCacheEmptyProxy, error) {
 	httpClient, err := proxyHTTPClient(p, "cache_empty_proxy")
 	if err != nil {
 		return nil, fmt.Errorf("error creating HTTP client: %w", err)
 	}
+	encoder, decoder, err := getProxyCodec(p.HTTP.Encoding, p.HTTP.JSONIndent)
+	if err != nil {
+		return nil, err
+	}
+	if p.HTTP.RequestTemplate != "" {
+		if encoder.Binary() {
+			return nil, fmt.Errorf("request template is not supported with %s encoding", p.HTTP.Encoding)
+		}
+		encoder, err = newTemplateProxyEncoder(p.HTTP.RequestTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var expectedContentType string
+	if p.HTTP.ValidateContentType {
+		expectedContentType = encoder.ContentType()
+	}
 	return &HTTPCacheEmptyProxy{
-		httpCaller: NewHTTPCaller(httpClient),
+		name:       name,
+		endpoint:   newChannelEndpoint(string(p.Endpoint)),
+		httpClient: httpClient,
+		httpCaller: newHTTPCaller(httpClient, p.HTTP.MaxResponseSize.Bytes(), expectedContentType),
 		config:     p,
+		encoder:    encoder,
+		decoder:    decoder,
+		log:        newProxyLogger(p),
+		limiter:    newCallLimiter(p.MaxConcurrency),
 	}, nil
 }
 
+// SetInterceptor sets hooks called around ProxyCacheEmpty. Must be called before proxy is used.
+func (p *HTTPCacheEmptyProxy) SetInterceptor(i CacheEmptyInterceptor) {
+	p.interceptor = &i
+}
+
+// Warmup establishes connection to backend host with HEAD request.
+func (p *HTTPCacheEmptyProxy) Warmup(ctx context.Context) error {
+	return warmupHTTP(ctx, p.httpClient, string(p.config.Endpoint))
+}
+
 // ProxyCacheEmpty proxies NotifyCacheEmpty to application backend.
 func (p *HTTPCacheEmptyProxy) ProxyCacheEmpty(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
-	data, err := httpEncoder.EncodeNotifyCacheEmptyRequest(req)
+	if p.interceptor == nil {
+		return p.proxyCacheEmpty(ctx, req)
+	}
+	return p.interceptor.intercept(ctx, req, p.proxyCacheEmpty)
+}
+
+func (p *HTTPCacheEmptyProxy) proxyCacheEmpty(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (_ *proxyproto.NotifyCacheEmptyResponse, err error) {
+	ctx, span := startProxySpan(ctx, p.config, p.name, p.Protocol(), "cache_empty", req.Channel)
+	defer func() { endProxySpan(span, err) }()
+	defer func() { err = newProxyError(p.name, p.Protocol(), err, p.config.HTTP.StatusToCodeTransforms) }()
+
+	endpoint, err := p.endpoint.URL(req.Channel)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.encoder.EncodeNotifyCacheEmptyRequest(req)
 	if err != nil {
 		return nil, err
 	}
-	respData, err := p.httpCaller.CallHTTP(ctx, p.config.Endpoint, httpRequestHeaders(ctx, p.config), data)
+	headers := httpRequestHeaders(ctx, p.config)
+	headers.Set("Content-Type", p.encoder.ContentType())
+	injectHTTPTraceContext(ctx, headers)
+	logHTTPPayload(p.log, p.name, p.config, "request", headers, data, p.encoder.Binary())
+	data, err = maybeCompressRequest(p.config, headers, data)
+	if err != nil {
+		return nil, err
+	}
+	maybeSignRequest(p.config, headers, data)
+	if err := p.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer p.limiter.release()
+	respData, err := retryCall(ctx, p.config, isRetryableError(p.config.HTTP.StatusToCodeTransforms), func(ctx context.Context) ([]byte, error) {
+		ctx, cancel := proxyCallContext(ctx, p.config)
+		defer cancel()
+		setTimeoutHeader(ctx, p.config, headers)
+		started := time.Now()
+		respData, err := p.httpCaller.CallHTTP(ctx, endpoint, headers, data)
+		observeCallLatency(ctx, p.Protocol(), started, err)
+		logSlowCall(p, p.config, p.name, req.Channel, time.Since(started))
+		observeCallError(p.Protocol(), err, p.config.HTTP.StatusToCodeTransforms)
+		return respData, err
+	})
 	if err != nil {
 		return transformCacheEmptyResponse(err, p.config.HTTP.StatusToCodeTransforms)
 	}
-	return httpDecoder.DecodeNotifyCacheEmptyResponse(respData)
+	logHTTPPayload(p.log, p.name, p.config, "response", nil, respData, p.encoder.Binary())
+	return p.decoder.DecodeNotifyCacheEmptyResponse(respData)
 }
 
 // Protocol ...
@@ -52,10 +140,18 @@ func (p *HTTPCacheEmptyProxy) Protocol() string {
 
 // UseBase64 ...
 func (p *HTTPCacheEmptyProxy) UseBase64() bool {
-	return p.config.BinaryEncoding
+	return p.config.BinaryEncoding && !p.encoder.Binary()
 }
 
 // IncludeMeta ...
 func (p *HTTPCacheEmptyProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *HTTPCacheEmptyProxy) logger() *zerolog.Logger {
+	return p.log
+}
+
+func (p *HTTPCacheEmptyProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
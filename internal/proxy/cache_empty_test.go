@@ -0,0 +1,176 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+)
+
+type interceptorCtxKey struct{}
+
+type interceptorRecorder struct {
+	beforeCalls int
+	afterCalls  int
+	beforeReq   *proxyproto.NotifyCacheEmptyRequest
+	afterCtxVal any
+	afterResp   *proxyproto.NotifyCacheEmptyResponse
+	afterErr    error
+}
+
+func (r *interceptorRecorder) interceptor(beforeErr error) CacheEmptyInterceptor {
+	return CacheEmptyInterceptor{
+		BeforeRequest: func(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (context.Context, error) {
+			r.beforeCalls++
+			r.beforeReq = req
+			if beforeErr != nil {
+				return ctx, beforeErr
+			}
+			return context.WithValue(ctx, interceptorCtxKey{}, "tenant"), nil
+		},
+		AfterResponse: func(ctx context.Context, resp *proxyproto.NotifyCacheEmptyResponse, err error) {
+			r.afterCalls++
+			r.afterCtxVal = ctx.Value(interceptorCtxKey{})
+			r.afterResp = resp
+			r.afterErr = err
+		},
+	}
+}
+
+var errInterceptorAbort = errors.New("abort")
+
+func newInterceptorHTTPServer(t *testing.T, status int) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestHTTPCacheEmptyProxyInterceptor(t *testing.T) {
+	server, calls := newInterceptorHTTPServer(t, http.StatusOK)
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+	rec := &interceptorRecorder{}
+	p.SetInterceptor(rec.interceptor(nil))
+
+	req := &proxyproto.NotifyCacheEmptyRequest{Channel: "test"}
+	resp, err := p.ProxyCacheEmpty(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, resp.GetResult().GetPopulated())
+	require.Equal(t, int64(1), calls.Load())
+	require.Equal(t, 1, rec.beforeCalls)
+	require.Same(t, req, rec.beforeReq)
+	require.Equal(t, 1, rec.afterCalls)
+	require.Equal(t, "tenant", rec.afterCtxVal)
+	require.Same(t, resp, rec.afterResp)
+	require.NoError(t, rec.afterErr)
+}
+
+func TestHTTPCacheEmptyProxyInterceptorCallError(t *testing.T) {
+	server, _ := newInterceptorHTTPServer(t, http.StatusInternalServerError)
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+	rec := &interceptorRecorder{}
+	p.SetInterceptor(rec.interceptor(nil))
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Error(t, err)
+	require.Equal(t, 1, rec.afterCalls)
+	require.Nil(t, rec.afterResp)
+	require.Equal(t, err, rec.afterErr)
+}
+
+func TestHTTPCacheEmptyProxyInterceptorBeforeRequestError(t *testing.T) {
+	server, calls := newInterceptorHTTPServer(t, http.StatusOK)
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+	rec := &interceptorRecorder{}
+	p.SetInterceptor(rec.interceptor(errInterceptorAbort))
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.ErrorIs(t, err, errInterceptorAbort)
+	require.Equal(t, int64(0), calls.Load())
+	require.Equal(t, 1, rec.beforeCalls)
+	require.Equal(t, 0, rec.afterCalls)
+}
+
+func TestHTTPCacheEmptyProxyInterceptorPartial(t *testing.T) {
+	server, calls := newInterceptorHTTPServer(t, http.StatusOK)
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	var afterCalls int
+	p.SetInterceptor(CacheEmptyInterceptor{
+		AfterResponse: func(context.Context, *proxyproto.NotifyCacheEmptyResponse, error) { afterCalls++ },
+	})
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.Equal(t, 1, afterCalls)
+
+	p.SetInterceptor(CacheEmptyInterceptor{})
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), calls.Load())
+}
+
+func TestGRPCCacheEmptyProxyInterceptor(t *testing.T) {
+	srv, cfg := newBufconnCacheEmptyServer(t)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+	rec := &interceptorRecorder{}
+	p.SetInterceptor(rec.interceptor(nil))
+
+	req := &proxyproto.NotifyCacheEmptyRequest{Channel: "test"}
+	resp, err := p.ProxyCacheEmpty(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), srv.calls.Load())
+	require.Equal(t, 1, rec.beforeCalls)
+	require.Same(t, req, rec.beforeReq)
+	require.Equal(t, 1, rec.afterCalls)
+	require.Equal(t, "tenant", rec.afterCtxVal)
+	require.Same(t, resp, rec.afterResp)
+	require.NoError(t, rec.afterErr)
+}
+
+func TestGRPCCacheEmptyProxyInterceptorBeforeRequestError(t *testing.T) {
+	srv, cfg := newBufconnCacheEmptyServer(t)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+	rec := &interceptorRecorder{}
+	p.SetInterceptor(rec.interceptor(errInterceptorAbort))
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.ErrorIs(t, err, errInterceptorAbort)
+	require.Equal(t, int64(0), srv.calls.Load())
+	require.Equal(t, 0, rec.afterCalls)
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"fmt"
+
+	"github.com/gobwas/glob"
+)
+
+// channelFilter matches channels against allow and deny lists of glob patterns, like
+// `data:*` or `chat:{room,lobby}`.
+type channelFilter struct {
+	allow []glob.Glob
+	deny  []glob.Glob
+}
+
+// newChannelFilter creates channelFilter. Channel is allowed if it matches any of allow patterns
+// (or allow list is empty) and does not match any of deny patterns – deny takes precedence.
+// Returns error if any pattern is malformed.
+func newChannelFilter(allow []string, deny []string) (*channelFilter, error) {
+	allowGlobs, err := compileChannelPatterns(allow)
+	if err != nil {
+		return nil, fmt.Errorf("error in channel allow list: %w", err)
+	}
+	denyGlobs, err := compileChannelPatterns(deny)
+	if err != nil {
+		return nil, fmt.Errorf("error in channel deny list: %w", err)
+	}
+	return &channelFilter{allow: allowGlobs, deny: denyGlobs}, nil
+}
+
+func compileChannelPatterns(patterns []string) ([]glob.Glob, error) {
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("malformed channel pattern %q: %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// Allowed reports whether channel passes filter.
+func (f *channelFilter) Allowed(channel string) bool {
+	for _, g := range f.deny {
+		if g.Match(channel) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, g := range f.allow {
+		if g.Match(channel) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelFilter(t *testing.T) {
+	f, err := newChannelFilter([]string{"data:*", "chat:{room,lobby}"}, []string{"data:private*"})
+	require.NoError(t, err)
+	for channel, allowed := range map[string]bool{
+		"data:1":          true,
+		"data:":           true,
+		"chat:room":       true,
+		"chat:lobby":      true,
+		"chat:other":      false,
+		"news:1":          false,
+		"data:private":    false,
+		"data:private:42": false,
+	} {
+		require.Equal(t, allowed, f.Allowed(channel), channel)
+	}
+
+	// Empty allow list allows everything except denied channels.
+	f, err = newChannelFilter(nil, []string{"secret:*"})
+	require.NoError(t, err)
+	require.True(t, f.Allowed("news:1"))
+	require.False(t, f.Allowed("secret:1"))
+}
+
+func TestNewCacheEmptyHandlerMalformedChannelPattern(t *testing.T) {
+	_, err := NewCacheEmptyHandler(CacheEmptyHandlerConfig{ChannelAllow: []string{"data:[a"}})
+	require.ErrorContains(t, err, "channel allow list")
+	_, err = NewCacheEmptyHandler(CacheEmptyHandlerConfig{ChannelDeny: []string{"data:[]"}})
+	require.ErrorContains(t, err, "channel deny list")
+}
+
+func TestCacheEmptyHandlerChannelFilter(t *testing.T) {
+	p := &recordingCacheEmptyProxy{}
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies:      map[string]CacheEmptyProxy{"test": p},
+		ChannelAllow: []string{"data:*"},
+		ChannelDeny:  []string{"data:internal:*"},
+	}).Handle()
+
+	resp, err := handler(context.Background(), "data:1")
+	require.NoError(t, err)
+	require.True(t, resp.GetResult().GetPopulated())
+
+	for _, channel := range []string{"news:1", "data:internal:1"} {
+		resp, err = handler(context.Background(), channel)
+		require.NoError(t, err, channel)
+		require.NotNil(t, resp.GetResult(), channel)
+		require.False(t, resp.GetResult().GetPopulated(), channel)
+	}
+	require.Equal(t, []string{"data:1"}, p.seen())
+}
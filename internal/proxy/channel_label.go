@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+
+	"github.com/rs/zerolog"
+)
+
+// channelNamespaceBoundary is a default Centrifugo channel namespace boundary.
+const channelNamespaceBoundary = ":"
+
+// channelLabelProxy may be implemented by proxy to provide ChannelLabelMode option.
+type channelLabelProxy interface {
+	channelLabelMode() string
+}
+
+// channelLabel returns representation of channel for logs according to mode, false is
+// returned if channel must be omitted.
+func channelLabel(mode string, channel string) (string, bool) {
+	switch mode {
+	case configtypes.ChannelLabelModeFull:
+		return channel, true
+	case configtypes.ChannelLabelModeHash:
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(channel))
+		return strconv.FormatUint(uint64(h.Sum32()), 16), true
+	case configtypes.ChannelLabelModeNone:
+		return "", false
+	default:
+		namespace, _, _ := strings.Cut(channel, channelNamespaceBoundary)
+		if namespace == channel {
+			// Channel without namespace.
+			return "", true
+		}
+		return namespace, true
+	}
+}
+
+// logChannel adds channel field to event according to ChannelLabelMode of proxy p. Namespace
+// mode is used if p is nil or does not provide ChannelLabelMode.
+func logChannel(event *zerolog.Event, p any, channel string) *zerolog.Event {
+	var mode string
+	if lp, ok := p.(channelLabelProxy); ok {
+		mode = lp.channelLabelMode()
+	}
+	label, ok := channelLabel(mode, channel)
+	if !ok {
+		return event
+	}
+	return event.Str("channel", label)
+}
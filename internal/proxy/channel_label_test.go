@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelLabel(t *testing.T) {
+	testCases := []struct {
+		mode     string
+		channel  string
+		expected string
+		omitted  bool
+	}{
+		{mode: "", channel: "chat:room-12345", expected: "chat"},
+		{mode: configtypes.ChannelLabelModeNamespace, channel: "chat:room-12345", expected: "chat"},
+		{mode: configtypes.ChannelLabelModeNamespace, channel: "room-12345", expected: ""},
+		{mode: configtypes.ChannelLabelModeFull, channel: "chat:room-12345", expected: "chat:room-12345"},
+		{mode: configtypes.ChannelLabelModeNone, channel: "chat:room-12345", omitted: true},
+	}
+	for _, tc := range testCases {
+		label, ok := channelLabel(tc.mode, tc.channel)
+		require.Equal(t, !tc.omitted, ok, tc.mode)
+		require.Equal(t, tc.expected, label, tc.mode)
+	}
+
+	hash1, ok := channelLabel(configtypes.ChannelLabelModeHash, "chat:room-12345")
+	require.True(t, ok)
+	hash2, _ := channelLabel(configtypes.ChannelLabelModeHash, "chat:room-12345")
+	hash3, _ := channelLabel(configtypes.ChannelLabelModeHash, "chat:room-12346")
+	require.Equal(t, hash1, hash2)
+	require.NotEqual(t, hash1, hash3)
+	require.NotContains(t, hash1, "room")
+}
+
+type labeledCacheEmptyProxy struct {
+	scriptedCacheEmptyProxy
+	mode string
+}
+
+func (p *labeledCacheEmptyProxy) channelLabelMode() string { return p.mode }
+
+func TestCacheEmptyHandlerLogChannelLabel(t *testing.T) {
+	buf := captureLogs(t)
+	for _, mode := range []string{"", configtypes.ChannelLabelModeFull, configtypes.ChannelLabelModeNone} {
+		buf.Reset()
+		p := &labeledCacheEmptyProxy{mode: mode}
+		p.errs = []error{errors.New("boom")}
+		handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+			Proxies: map[string]CacheEmptyProxy{"test": p},
+		}).Handle()
+		_, err := handler(context.Background(), "chat:room-12345")
+		require.Error(t, err)
+		require.Contains(t, buf.String(), "error calling cache empty proxy")
+		switch mode {
+		case "":
+			require.Contains(t, buf.String(), `"channel":"chat"`)
+		case configtypes.ChannelLabelModeFull:
+			require.Contains(t, buf.String(), `"channel":"chat:room-12345"`)
+		case configtypes.ChannelLabelModeNone:
+			require.NotContains(t, buf.String(), `"channel"`)
+		}
+		if mode != configtypes.ChannelLabelModeFull {
+			require.NotContains(t, buf.String(), "room-12345")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package proxy
+
+import (
+	"context"
+)
+
+// callLimiter is a semaphore limiting the number of concurrent proxy calls. Nil limiter
+// does not limit calls.
+type callLimiter chan struct{}
+
+// newCallLimiter returns limiter allowing n concurrent calls, nil if n is not positive.
+func newCallLimiter(n int) callLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return make(callLimiter, n)
+}
+
+// acquire waits for free slot. Returns context error if ctx is done first.
+func (l callLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l <- struct{}{}:
+		return nil
+	default:
+	}
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees slot taken with acquire.
+func (l callLimiter) release() {
+	if l != nil {
+		<-l
+	}
+}
@@ -0,0 +1,134 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockingCacheEmptyServer holds requests until release is closed and tracks the maximum
+// number of concurrent requests.
+type blockingCacheEmptyServer struct {
+	release  chan struct{}
+	inflight atomic.Int64
+	max      atomic.Int64
+}
+
+func newBlockingCacheEmptyServer(t *testing.T) (*blockingCacheEmptyServer, *httptest.Server) {
+	t.Helper()
+	s := &blockingCacheEmptyServer{release: make(chan struct{})}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := s.inflight.Add(1)
+		defer s.inflight.Add(-1)
+		for {
+			m := s.max.Load()
+			if n <= m || s.max.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-s.release
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func TestCacheEmptyProxyHTTPMaxConcurrency(t *testing.T) {
+	limits := []int{1, 3}
+	servers := make([]*blockingCacheEmptyServer, len(limits))
+	proxies := make([]*HTTPCacheEmptyProxy, len(limits))
+	for i, limit := range limits {
+		s, server := newBlockingCacheEmptyServer(t)
+		p, err := NewHTTPCacheEmptyProxy("test", Config{
+			Endpoint:       configtypes.String(server.URL),
+			Timeout:        configtypes.Duration(5 * time.Second),
+			MaxConcurrency: limit,
+		})
+		require.NoError(t, err)
+		servers[i], proxies[i] = s, p
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 12)
+	for _, p := range proxies {
+		for j := 0; j < 6; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+				errs <- err
+			}()
+		}
+	}
+	// Each proxy reaches its own limit, exhausted limit of one proxy does not block another.
+	for i, limit := range limits {
+		require.Eventually(t, func() bool { return servers[i].inflight.Load() == int64(limit) }, time.Second, 5*time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	for i, s := range servers {
+		require.Equal(t, int64(limits[i]), s.inflight.Load())
+		close(s.release)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	for i, s := range servers {
+		require.Equal(t, int64(limits[i]), s.max.Load())
+	}
+}
+
+func TestCacheEmptyProxyHTTPMaxConcurrencyContextDone(t *testing.T) {
+	s, server := newBlockingCacheEmptyServer(t)
+	defer close(s.release)
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint:       configtypes.String(server.URL),
+		Timeout:        configtypes.Duration(5 * time.Second),
+		MaxConcurrency: 1,
+	})
+	require.NoError(t, err)
+
+	go func() {
+		_, _ = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	}()
+	require.Eventually(t, func() bool { return s.inflight.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = p.ProxyCacheEmpty(ctx, &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, int64(1), s.inflight.Load())
+}
+
+func TestGRPCCacheEmptyProxyMaxConcurrency(t *testing.T) {
+	srv := &slowCacheEmptyServer{}
+	cfg := newBufconnProxyConfig(t, srv)
+	cfg.MaxConcurrency = 1
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	var wg sync.WaitGroup
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		}()
+	}
+	wg.Wait()
+	// Each call of slow server takes 80ms, so calls went one by one.
+	require.GreaterOrEqual(t, time.Since(started), 240*time.Millisecond)
+	require.Equal(t, int64(3), srv.calls.Load())
+}
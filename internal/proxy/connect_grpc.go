@@ -19,7 +19,7 @@ var _ ConnectProxy = (*GRPCConnectProxy)(nil)
 
 // NewGRPCConnectProxy ...
 func NewGRPCConnectProxy(name string, p Config) (*GRPCConnectProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	host, err := getGrpcHost(string(p.Endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("error getting grpc host: %v", err)
 	}
@@ -57,3 +57,7 @@ func (p *GRPCConnectProxy) ProxyConnect(ctx context.Context, req *proxyproto.Con
 	defer cancel()
 	return p.client.Connect(grpcRequestContext(ctx, p.config), req)
 }
+
+func (p *GRPCConnectProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
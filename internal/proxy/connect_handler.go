@@ -142,7 +142,7 @@ func (h *ConnectHandler) Handle() ConnectingHandlerFunc {
 					return centrifuge.ConnectReply{}, ConnectExtra{}, err
 				}
 				if !found {
-					log.Warn().Str("client", e.ClientID).Str("channel", ch).Msg("unknown channel in connect result channels")
+					logChannel(log.Warn().Str("client", e.ClientID), h.config.Proxy, ch).Msg("unknown channel in connect result channels")
 					return centrifuge.ConnectReply{}, ConnectExtra{}, centrifuge.ErrorUnknownChannel
 				}
 				reply.Subscriptions[ch] = centrifuge.SubscribeOptions{
@@ -169,7 +169,7 @@ func (h *ConnectHandler) Handle() ConnectingHandlerFunc {
 					return centrifuge.ConnectReply{}, ConnectExtra{}, err
 				}
 				if !found {
-					log.Warn().Str("client", e.ClientID).Str("channel", ch).Msg("unknown channel in connect result subs")
+					logChannel(log.Warn().Str("client", e.ClientID), h.config.Proxy, ch).Msg("unknown channel in connect result subs")
 					return centrifuge.ConnectReply{}, ConnectExtra{}, centrifuge.ErrorUnknownChannel
 				}
 				var chInfo []byte
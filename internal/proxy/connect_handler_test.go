@@ -29,7 +29,7 @@ func getTestGrpcProxy(commonProxyTestCase *tools.CommonGRPCProxyTestCase) config
 	return configtypes.Proxy{
 		// Using passthrough is required for in-memory bufconn since grpc-go v1.63.0.
 		// See https://github.com/grpc/grpc-go/issues/7091.
-		Endpoint: "passthrough:///" + commonProxyTestCase.Listener.Addr().String(),
+		Endpoint: configtypes.String("passthrough:///" + commonProxyTestCase.Listener.Addr().String()),
 		Timeout:  configtypes.Duration(5 * time.Second),
 		TestGrpcDialer: func(ctx context.Context, s string) (net.Conn, error) {
 			return commonProxyTestCase.Listener.Dial()
@@ -39,7 +39,7 @@ func getTestGrpcProxy(commonProxyTestCase *tools.CommonGRPCProxyTestCase) config
 
 func getTestHttpProxy(commonProxyTestCase *tools.CommonHTTPProxyTestCase, endpoint string) configtypes.Proxy {
 	return configtypes.Proxy{
-		Endpoint: commonProxyTestCase.Server.URL + endpoint,
+		Endpoint: configtypes.String(commonProxyTestCase.Server.URL + endpoint),
 		Timeout:  configtypes.Duration(5 * time.Second),
 		ProxyCommon: configtypes.ProxyCommon{
 			HTTP: configtypes.ProxyCommonHTTP{
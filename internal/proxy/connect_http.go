@@ -23,7 +23,7 @@ func NewHTTPConnectProxy(p Config) (*HTTPConnectProxy, error) {
 	}
 	return &HTTPConnectProxy{
 		config:     p,
-		httpCaller: NewHTTPCaller(httpClient),
+		httpCaller: NewHTTPCaller(httpClient, p.HTTP.MaxResponseSize.Bytes()),
 	}, nil
 }
 
@@ -47,9 +47,13 @@ func (p *HTTPConnectProxy) ProxyConnect(ctx context.Context, req *proxyproto.Con
 	if err != nil {
 		return nil, err
 	}
-	respData, err := p.httpCaller.CallHTTP(ctx, p.config.Endpoint, httpRequestHeaders(ctx, p.config), data)
+	respData, err := p.httpCaller.CallHTTP(ctx, string(p.config.Endpoint), httpRequestHeaders(ctx, p.config), data)
 	if err != nil {
 		return transformConnectResponse(err, p.config.HTTP.StatusToCodeTransforms)
 	}
 	return httpDecoder.DecodeConnectResponse(respData)
 }
+
+func (p *HTTPConnectProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+)
+
+const (
+	defaultProxyTimeout = time.Second
+	defaultLockTimeout  = 5 * time.Second
+	defaultCallTimeout  = 30 * time.Second
+	defaultStaleWindow  = time.Minute
+	defaultNoCacheTTL   = time.Minute
+)
+
+// Defaults are process-wide values used for options which are not set in configuration of
+// proxies and CacheEmptyHandler. Options set in configuration always take precedence. Zero
+// fields mean built-in values.
+type Defaults struct {
+	// Timeout of proxy calls created with Get*Proxy functions, built-in value is 1 second.
+	Timeout time.Duration
+	// LockTimeout of CacheEmptyHandler, built-in value is 5 seconds.
+	LockTimeout time.Duration
+	// CallTimeout of CacheEmptyHandler, built-in value is 30 seconds.
+	CallTimeout time.Duration
+	// StaleWindow of CacheEmptyHandler, built-in value is 1 minute.
+	StaleWindow time.Duration
+	// NoCacheTTL of CacheEmptyHandler, built-in value is 1 minute.
+	NoCacheTTL time.Duration
+	// HealthWindow of CacheEmptyHandler, built-in value is 1 minute.
+	HealthWindow time.Duration
+	// HealthMaxErrorRate of CacheEmptyHandler, built-in value is 0.5.
+	HealthMaxErrorRate float64
+	// RetryBackoff of proxy calls, built-in value is 100 milliseconds.
+	RetryBackoff time.Duration
+	// RetryMaxDelay of proxy calls, built-in value is 5 seconds.
+	RetryMaxDelay time.Duration
+}
+
+var builtinDefaults = Defaults{
+	Timeout:            defaultProxyTimeout,
+	LockTimeout:        defaultLockTimeout,
+	CallTimeout:        defaultCallTimeout,
+	StaleWindow:        defaultStaleWindow,
+	NoCacheTTL:         defaultNoCacheTTL,
+	HealthWindow:       defaultHealthWindow,
+	HealthMaxErrorRate: defaultHealthMaxErrorRate,
+	RetryBackoff:       defaultRetryBackoff,
+	RetryMaxDelay:      defaultRetryMaxDelay,
+}
+
+var currentDefaults atomic.Pointer[Defaults]
+
+// SetDefaults replaces process-wide defaults. Should be called on start before proxies and
+// handlers are created – CacheEmptyHandler created before keeps previous defaults. Zero or
+// negative fields are set to built-in values.
+func SetDefaults(d Defaults) {
+	d.Timeout = defaultValue(d.Timeout, builtinDefaults.Timeout)
+	d.LockTimeout = defaultValue(d.LockTimeout, builtinDefaults.LockTimeout)
+	d.CallTimeout = defaultValue(d.CallTimeout, builtinDefaults.CallTimeout)
+	d.StaleWindow = defaultValue(d.StaleWindow, builtinDefaults.StaleWindow)
+	d.NoCacheTTL = defaultValue(d.NoCacheTTL, builtinDefaults.NoCacheTTL)
+	d.HealthWindow = defaultValue(d.HealthWindow, builtinDefaults.HealthWindow)
+	d.HealthMaxErrorRate = defaultValue(d.HealthMaxErrorRate, builtinDefaults.HealthMaxErrorRate)
+	d.RetryBackoff = defaultValue(d.RetryBackoff, builtinDefaults.RetryBackoff)
+	d.RetryMaxDelay = defaultValue(d.RetryMaxDelay, builtinDefaults.RetryMaxDelay)
+	currentDefaults.Store(&d)
+}
+
+// CurrentDefaults returns process-wide defaults.
+func CurrentDefaults() Defaults {
+	if d := currentDefaults.Load(); d != nil {
+		return *d
+	}
+	return builtinDefaults
+}
+
+// withDefaultTimeout returns proxy configuration with Defaults.Timeout if timeout is not set.
+// Applied by Get*Proxy functions which create proxies from configuration, proxies created
+// with constructors directly keep zero Timeout meaning no deadline.
+func withDefaultTimeout(p Config) Config {
+	if p.Timeout <= 0 {
+		p.Timeout = configtypes.Duration(CurrentDefaults().Timeout)
+	}
+	return p
+}
+
+func defaultValue[T time.Duration | float64](value T, defaultValue T) T {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
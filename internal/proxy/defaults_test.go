@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultsPrecedence(t *testing.T) {
+	defer SetDefaults(Defaults{})
+
+	// Built-in values.
+	h := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{})
+	require.Equal(t, 5*time.Second, h.lockTimeout)
+	require.Equal(t, 30*time.Second, h.callTimeout)
+	require.Equal(t, time.Minute, h.noCacheTTL)
+
+	// Global defaults.
+	SetDefaults(Defaults{LockTimeout: time.Second, CallTimeout: 2 * time.Second})
+	require.Equal(t, time.Second, CurrentDefaults().LockTimeout)
+	require.Equal(t, builtinDefaults.RetryBackoff, CurrentDefaults().RetryBackoff)
+	h = newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{})
+	require.Equal(t, time.Second, h.lockTimeout)
+	require.Equal(t, 2*time.Second, h.callTimeout)
+	require.Equal(t, time.Minute, h.noCacheTTL)
+
+	// Handler config.
+	h = newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{LockTimeout: 3 * time.Second, CallTimeout: 4 * time.Second})
+	require.Equal(t, 3*time.Second, h.lockTimeout)
+	require.Equal(t, 4*time.Second, h.callTimeout)
+
+	// Reset to built-in values.
+	SetDefaults(Defaults{})
+	require.Equal(t, builtinDefaults, CurrentDefaults())
+	h = newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{})
+	require.Equal(t, 5*time.Second, h.lockTimeout)
+}
+
+func TestDefaultsTimeout(t *testing.T) {
+	defer SetDefaults(Defaults{})
+	timeout := func(p Config) time.Duration {
+		cep, err := GetCacheEmptyProxy("test", p)
+		require.NoError(t, err)
+		return cep.(*HTTPCacheEmptyProxy).config.Timeout.ToDuration()
+	}
+
+	// Built-in value.
+	require.Equal(t, time.Second, timeout(Config{Endpoint: "http://localhost:3000"}))
+
+	// Global default.
+	SetDefaults(Defaults{Timeout: 3 * time.Second})
+	require.Equal(t, 3*time.Second, timeout(Config{Endpoint: "http://localhost:3000"}))
+	cep, err := GetCacheEmptyProxy("test", Config{Endpoint: "localhost:3000"})
+	require.NoError(t, err)
+	defer func() { _ = cep.(*GRPCCacheEmptyProxy).Close() }()
+	require.Equal(t, 3*time.Second, cep.(*GRPCCacheEmptyProxy).config.Timeout.ToDuration())
+
+	// Proxy created with constructor directly keeps zero timeout.
+	p, err := NewHTTPCacheEmptyProxy("test", Config{Endpoint: "http://localhost:3000"})
+	require.NoError(t, err)
+	require.Zero(t, p.config.Timeout)
+
+	// Proxy config takes precedence over global default.
+	require.Equal(t, 2*time.Second, timeout(Config{Endpoint: "http://localhost:3000", Timeout: configtypes.Duration(2 * time.Second)}))
+}
+
+func TestDefaultsRetryBackoff(t *testing.T) {
+	defer SetDefaults(Defaults{})
+	errTest := errors.New("test")
+	retry := func(p Config) (int, time.Duration) {
+		attempts := 0
+		start := time.Now()
+		_, _ = retryCall(context.Background(), p, func(error) bool { return true }, func(context.Context) (struct{}, error) {
+			attempts++
+			return struct{}{}, errTest
+		})
+		return attempts, time.Since(start)
+	}
+
+	// Global default replaces built-in 100ms backoff.
+	SetDefaults(Defaults{RetryBackoff: time.Millisecond})
+	attempts, elapsed := retry(Config{MaxRetries: 3})
+	require.Equal(t, 4, attempts)
+	require.Less(t, elapsed, 100*time.Millisecond)
+
+	// Proxy config takes precedence over global default.
+	attempts, elapsed = retry(Config{MaxRetries: 1, RetryBackoff: configtypes.Duration(50 * time.Millisecond)})
+	require.Equal(t, 2, attempts)
+	require.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+}
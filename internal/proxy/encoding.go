@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"fmt"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+)
+
+// ProxyEncoder encodes requests sent to application backend over HTTP.
+type ProxyEncoder interface {
+	proxyproto.RequestEncoder
+	// ContentType returns a value of Content-Type header for encoded requests.
+	ContentType() string
+	// Binary reports whether encoding can carry raw bytes, so base64 for byte fields
+	// is not needed.
+	Binary() bool
+}
+
+// ProxyDecoder decodes responses from application backend.
+type ProxyDecoder interface {
+	proxyproto.ResponseDecoder
+}
+
+type jsonProxyEncoder struct {
+	proxyproto.JSONEncoder
+}
+
+func (e *jsonProxyEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (e *jsonProxyEncoder) Binary() bool {
+	return false
+}
+
+type protobufProxyEncoder struct {
+	proxyproto.ProtobufEncoder
+}
+
+func (e *protobufProxyEncoder) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (e *protobufProxyEncoder) Binary() bool {
+	return true
+}
+
+type msgpackProxyEncoder struct {
+	proxyproto.MsgpackEncoder
+}
+
+func (e *msgpackProxyEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+func (e *msgpackProxyEncoder) Binary() bool {
+	return true
+}
+
+// getProxyCodec returns encoder and decoder for configured HTTP proxy payload encoding.
+// jsonIndent is only taken into account for JSON encoding.
+func getProxyCodec(encoding string, jsonIndent bool) (ProxyEncoder, ProxyDecoder, error) {
+	switch encoding {
+	case "", configtypes.ProxyEncodingJSON:
+		return &jsonProxyEncoder{proxyproto.JSONEncoder{Indent: jsonIndent}}, &proxyproto.JSONDecoder{}, nil
+	case configtypes.ProxyEncodingProtobuf:
+		return &protobufProxyEncoder{}, &proxyproto.ProtobufDecoder{}, nil
+	case configtypes.ProxyEncodingMsgpack:
+		return &msgpackProxyEncoder{}, &proxyproto.MsgpackDecoder{}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown proxy encoding: %s", encoding)
+	}
+}
@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProxyCodecRoundTrip(t *testing.T) {
+	payload := []byte{0x00, 0x01, 0xfe, 0xff}
+	testCases := []struct {
+		encoding    string
+		contentType string
+		binary      bool
+	}{
+		{encoding: "", contentType: "application/json"},
+		{encoding: configtypes.ProxyEncodingJSON, contentType: "application/json"},
+		{encoding: configtypes.ProxyEncodingProtobuf, contentType: "application/x-protobuf", binary: true},
+		{encoding: configtypes.ProxyEncodingMsgpack, contentType: "application/msgpack", binary: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.encoding, func(t *testing.T) {
+			encoder, decoder, err := getProxyCodec(tc.encoding, false)
+			require.NoError(t, err)
+			require.Equal(t, tc.contentType, encoder.ContentType())
+			require.Equal(t, tc.binary, encoder.Binary())
+
+			if !tc.binary {
+				return
+			}
+			// Byte fields survive encoding without base64.
+			reqData, err := encoder.EncodeRPCRequest(&proxyproto.RPCRequest{Method: "m", Data: payload})
+			require.NoError(t, err)
+			var req proxyproto.RPCRequest
+			resp := &proxyproto.RPCResponse{Result: &proxyproto.RPCResult{Data: payload}}
+			var data []byte
+			switch tc.encoding {
+			case configtypes.ProxyEncodingProtobuf:
+				require.NoError(t, proto.Unmarshal(reqData, &req))
+				data, err = proto.Marshal(resp)
+			default:
+				require.NoError(t, proxyproto.UnmarshalMsgpack(reqData, &req))
+				data, err = proxyproto.MarshalMsgpack(resp)
+			}
+			require.NoError(t, err)
+			require.Equal(t, payload, []byte(req.Data))
+			require.Empty(t, req.B64Data)
+
+			decoded, err := decoder.DecodeRPCResponse(data)
+			require.NoError(t, err)
+			require.Equal(t, payload, []byte(decoded.Result.Data))
+			require.Empty(t, decoded.Result.B64Data)
+		})
+	}
+}
+
+func TestProxyCodecUnknown(t *testing.T) {
+	_, _, err := getProxyCodec("xml", false)
+	require.Error(t, err)
+}
+
+func TestCacheEmptyProxyHTTPJSONIndent(t *testing.T) {
+	req := &proxyproto.NotifyCacheEmptyRequest{Channel: "test", Client: "client", User: "user", Meta: proxyproto.Raw(`{"a":1}`)}
+	testCases := []struct {
+		name     string
+		indent   bool
+		expected string
+	}{
+		{name: "compact", expected: `{"channel":"test","client":"client","user":"user","meta":{"a":1}}`},
+		{name: "indent", indent: true, expected: "{\n  \"channel\": \"test\",\n  \"client\": \"client\",\n  \"user\": \"user\",\n  \"meta\": {\n    \"a\": 1\n  }\n}"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				_, _ = w.Write([]byte(`{"result":{}}`))
+			}))
+			defer server.Close()
+
+			cfg := Config{
+				Endpoint: configtypes.String(server.URL),
+				Timeout:  configtypes.Duration(time.Second),
+			}
+			cfg.HTTP.JSONIndent = tc.indent
+			p, err := NewHTTPCacheEmptyProxy("test", cfg)
+			require.NoError(t, err)
+			_, err = p.ProxyCacheEmpty(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(body))
+		})
+	}
+}
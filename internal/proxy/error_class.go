@@ -0,0 +1,181 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorClass is a category of proxy call error used to label metrics and decide on retries.
+type ErrorClass string
+
+const (
+	// ErrorClassTimeout – call deadline exceeded or network operation timed out.
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassCanceled – call context canceled, usually because client went away.
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassConnection – backend could not be reached: DNS failure, refused or reset
+	// connection, GRPC Unavailable code.
+	ErrorClassConnection ErrorClass = "connection"
+	// ErrorClassTransport – backend reached but call failed on transport level: TLS errors,
+	// truncated responses, HTTP 5xx and 429 statuses, GRPC ResourceExhausted and Aborted codes,
+	// unknown errors.
+	ErrorClassTransport ErrorClass = "transport"
+	// ErrorClassApplication – backend deliberately rejected call: HTTP 4xx statuses, statuses
+	// matching StatusToCodeTransforms (TransformedError), other GRPC codes, too large responses,
+	// responses of unexpected content type and redirects which are not followed.
+	ErrorClassApplication ErrorClass = "application"
+)
+
+// Retryable reports whether call failed with error of this class may succeed if retried.
+func (c ErrorClass) Retryable() bool {
+	switch c {
+	case ErrorClassTimeout, ErrorClassConnection, ErrorClassTransport:
+		return true
+	default:
+		return false
+	}
+}
+
+// ClassifyError returns class of proxy call error. HTTP status codes which have transform
+// in statusToCodeTransforms are considered as application errors since backend uses them
+// to answer on purpose. Returns empty class for nil error and Kind for ProxyError.
+func ClassifyError(err error, statusToCodeTransforms configtypes.HttpStatusToCodeTransforms) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	var proxyErr *ProxyError
+	if errors.As(err, &proxyErr) {
+		return proxyErr.Kind
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, ErrTruncatedResponse) {
+		return ErrorClassTransport
+	}
+	var transformedErr *TransformedError
+	if errors.Is(err, ErrResponseTooLarge) || errors.Is(err, ErrUnexpectedContentType) || errors.Is(err, ErrRedirectNotFollowed) || errors.As(err, &transformedErr) {
+		return ErrorClassApplication
+	}
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		return classifyHTTPStatus(statusErr.Code, statusToCodeTransforms)
+	}
+	if st, ok := status.FromError(err); ok {
+		return classifyGRPCCode(st.Code())
+	}
+	if isConnectionError(err) {
+		return ErrorClassConnection
+	}
+	return ErrorClassTransport
+}
+
+func classifyHTTPStatus(code int, statusToCodeTransforms configtypes.HttpStatusToCodeTransforms) ErrorClass {
+	for _, t := range statusToCodeTransforms {
+		if t.StatusCode == code {
+			return ErrorClassApplication
+		}
+	}
+	if code >= http.StatusInternalServerError || code == http.StatusTooManyRequests {
+		return ErrorClassTransport
+	}
+	return ErrorClassApplication
+}
+
+func classifyGRPCCode(code codes.Code) ErrorClass {
+	switch code {
+	case codes.Canceled:
+		return ErrorClassCanceled
+	case codes.DeadlineExceeded:
+		return ErrorClassTimeout
+	case codes.Unavailable:
+		return ErrorClassConnection
+	case codes.ResourceExhausted, codes.Aborted:
+		return ErrorClassTransport
+	default:
+		return ErrorClassApplication
+	}
+}
+
+// isConnectionError reports whether err means that connection to backend could not be
+// established or was broken. TLS errors are also wrapped into net.OpError, so checked first.
+func isConnectionError(err error) bool {
+	var (
+		certErr      *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return false
+	}
+	var (
+		dnsErr *net.DNSError
+		opErr  *net.OpError
+	)
+	return errors.As(err, &dnsErr) || errors.As(err, &opErr)
+}
+
+// ProxyError is returned by cache empty proxies for failed calls, so that callers may branch
+// on failure kind without matching error text. Error returns message of wrapped error.
+type ProxyError struct {
+	// Proxy is a name of proxy.
+	Proxy string
+	// Protocol of proxy: "http" or "grpc".
+	Protocol string
+	// Kind is a class of error.
+	Kind ErrorClass
+	// StatusCode is HTTP status code of response for HTTP proxies or GRPC status code for GRPC
+	// proxies, zero if call failed without response status.
+	StatusCode int
+	// Err is original error.
+	Err error
+}
+
+func (e *ProxyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProxyError) Unwrap() error {
+	return e.Err
+}
+
+// newProxyError wraps error of proxy call into ProxyError, nil error is returned as is.
+func newProxyError(name string, protocol string, err error, statusToCodeTransforms configtypes.HttpStatusToCodeTransforms) error {
+	if err == nil {
+		return nil
+	}
+	var proxyErr *ProxyError
+	if errors.As(err, &proxyErr) {
+		return err
+	}
+	e := &ProxyError{
+		Proxy:    name,
+		Protocol: protocol,
+		Kind:     ClassifyError(err, statusToCodeTransforms),
+		Err:      err,
+	}
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		e.StatusCode = statusErr.Code
+	} else if st, ok := status.FromError(err); ok && protocol == "grpc" {
+		e.StatusCode = int(st.Code())
+	}
+	return e
+}
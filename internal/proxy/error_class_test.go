@@ -0,0 +1,171 @@
+package proxy
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyError(t *testing.T) {
+	transforms := configtypes.HttpStatusToCodeTransforms{{StatusCode: http.StatusServiceUnavailable}}
+	urlErr := func(err error) error {
+		return fmt.Errorf("HTTP request error: %w", &url.Error{Op: "Post", URL: "http://backend", Err: err})
+	}
+	testCases := []struct {
+		name       string
+		err        error
+		transforms configtypes.HttpStatusToCodeTransforms
+		expected   ErrorClass
+	}{
+		{name: "nil", err: nil, expected: ""},
+		{name: "deadline exceeded", err: urlErr(context.DeadlineExceeded), expected: ErrorClassTimeout},
+		{name: "canceled", err: urlErr(context.Canceled), expected: ErrorClassCanceled},
+		{name: "net timeout", err: urlErr(timeoutError{}), expected: ErrorClassTimeout},
+		{name: "dns", err: urlErr(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "backend", IsNotFound: true}}), expected: ErrorClassConnection},
+		{name: "connection refused", err: urlErr(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}), expected: ErrorClassConnection},
+		{name: "tls", err: urlErr(x509.UnknownAuthorityError{}), expected: ErrorClassTransport},
+		{name: "unknown", err: errors.New("boom"), expected: ErrorClassTransport},
+		{name: "http 5xx", err: &statusCodeError{Code: http.StatusInternalServerError}, expected: ErrorClassTransport},
+		{name: "http 429", err: &statusCodeError{Code: http.StatusTooManyRequests}, expected: ErrorClassTransport},
+		{name: "http 4xx", err: &statusCodeError{Code: http.StatusForbidden}, expected: ErrorClassApplication},
+		{name: "http transformed", err: &statusCodeError{Code: http.StatusServiceUnavailable}, transforms: transforms, expected: ErrorClassApplication},
+		{name: "http not transformed", err: &statusCodeError{Code: http.StatusBadGateway}, transforms: transforms, expected: ErrorClassTransport},
+		{name: "response too large", err: fmt.Errorf("%w: limit 10 bytes", ErrResponseTooLarge), expected: ErrorClassApplication},
+		{name: "grpc canceled", err: status.Error(codes.Canceled, "canceled"), expected: ErrorClassCanceled},
+		{name: "grpc deadline", err: status.Error(codes.DeadlineExceeded, "deadline"), expected: ErrorClassTimeout},
+		{name: "grpc unavailable", err: status.Error(codes.Unavailable, "unavailable"), expected: ErrorClassConnection},
+		{name: "grpc resource exhausted", err: status.Error(codes.ResourceExhausted, "overloaded"), expected: ErrorClassTransport},
+		{name: "grpc internal", err: status.Error(codes.Internal, "internal"), expected: ErrorClassApplication},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ClassifyError(tc.err, tc.transforms))
+		})
+	}
+}
+
+func TestErrorClassRetryable(t *testing.T) {
+	require.True(t, ErrorClassTimeout.Retryable())
+	require.True(t, ErrorClassConnection.Retryable())
+	require.True(t, ErrorClassTransport.Retryable())
+	require.False(t, ErrorClassApplication.Retryable())
+	require.False(t, ErrorClassCanceled.Retryable())
+}
+
+func TestCacheEmptyProxyHTTPNoRetryOnTransformedStatus(t *testing.T) {
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Endpoint:     configtypes.String(server.URL),
+		Timeout:      configtypes.Duration(time.Second),
+		MaxRetries:   3,
+		RetryBackoff: configtypes.Duration(time.Millisecond),
+	}
+	cfg.HTTP.StatusToCodeTransforms = configtypes.HttpStatusToCodeTransforms{{StatusCode: http.StatusServiceUnavailable}}
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+
+	proxyCallErrorClassCount.Reset()
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Error(t, err)
+	require.Equal(t, int64(1), attempts.Load())
+	require.Equal(t, float64(1), testutil.ToFloat64(proxyCallErrorClassCount.WithLabelValues("http", string(ErrorClassApplication))))
+
+	// Without transform 503 is retried.
+	cfg.HTTP.StatusToCodeTransforms = nil
+	p, err = NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Error(t, err)
+	require.Equal(t, int64(5), attempts.Load())
+	require.Equal(t, float64(4), testutil.ToFloat64(proxyCallErrorClassCount.WithLabelValues("http", string(ErrorClassTransport))))
+}
+
+func TestCacheEmptyProxyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	httpProxy, err := NewHTTPCacheEmptyProxy("http_backend", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	srv := &cacheEmptyStatusServer{code: codes.NotFound}
+	grpcProxy, err := NewGRPCCacheEmptyProxy("grpc_backend", newBufconnProxyConfig(t, srv))
+	require.NoError(t, err)
+	defer func() { _ = grpcProxy.Close() }()
+
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			"http_backend": httpProxy,
+			"grpc_backend": grpcProxy,
+		},
+		ProxyName: func(channel string) (string, bool) {
+			return channel, true
+		},
+	}).Handle()
+
+	_, err = handler(context.Background(), "http_backend")
+	var proxyErr *ProxyError
+	require.ErrorAs(t, err, &proxyErr)
+	require.Equal(t, "http_backend", proxyErr.Proxy)
+	require.Equal(t, "http", proxyErr.Protocol)
+	require.Equal(t, ErrorClassTransport, proxyErr.Kind)
+	require.Equal(t, http.StatusBadGateway, proxyErr.StatusCode)
+	var statusErr *statusCodeError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, ErrorClassTransport, ClassifyError(err, nil))
+
+	_, err = handler(context.Background(), "grpc_backend")
+	require.ErrorAs(t, err, &proxyErr)
+	require.Equal(t, "grpc_backend", proxyErr.Proxy)
+	require.Equal(t, "grpc", proxyErr.Protocol)
+	require.Equal(t, ErrorClassApplication, proxyErr.Kind)
+	require.Equal(t, int(codes.NotFound), proxyErr.StatusCode)
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, "rpc error: code = NotFound desc = rejected", err.Error())
+}
+
+func TestCacheEmptyProxyErrorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(20 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	var proxyErr *ProxyError
+	require.ErrorAs(t, err, &proxyErr)
+	require.Equal(t, ErrorClassTimeout, proxyErr.Kind)
+	require.Zero(t, proxyErr.StatusCode)
+}
@@ -3,18 +3,27 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"net/url"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/centrifugal/centrifugo/v6/internal/clientcontext"
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
 	"github.com/centrifugal/centrifugo/v6/internal/middleware"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
 
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type rpcCredentials struct {
@@ -32,6 +41,73 @@ func (t rpcCredentials) RequireTransportSecurity() bool {
 	return false
 }
 
+// refreshingRPCCredentials passes value returned by provider as per-RPC credentials. Value is
+// cached until it's 80-90% through its lifetime (randomly, so that proxies do not refresh at the
+// same moment), values without expiration time are cached forever. Concurrent refreshes are
+// deduplicated, and if refresh fails cached value is used until it expires.
+type refreshingRPCCredentials struct {
+	key      string
+	provider func(context.Context) (string, time.Time, error)
+	group    singleflight.Group
+
+	mu        sync.Mutex
+	value     string
+	ok        bool      // Whether value was received.
+	refreshAt time.Time // Zero if value does not expire.
+	expiresAt time.Time // Zero if value does not expire.
+}
+
+func (t *refreshingRPCCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	value, err := t.getValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		t.key: value,
+	}, nil
+}
+
+func (t *refreshingRPCCredentials) getValue(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	value, ok, refreshAt, expiresAt := t.value, t.ok, t.refreshAt, t.expiresAt
+	t.mu.Unlock()
+	now := time.Now()
+	if ok && (refreshAt.IsZero() || now.Before(refreshAt)) {
+		return value, nil
+	}
+	v, err, _ := t.group.Do("", func() (any, error) {
+		return t.refresh(ctx)
+	})
+	if err != nil {
+		if ok && now.Before(expiresAt) {
+			return value, nil
+		}
+		return "", fmt.Errorf("error getting GRPC credentials: %w", err)
+	}
+	return v.(string), nil
+}
+
+func (t *refreshingRPCCredentials) refresh(ctx context.Context) (string, error) {
+	value, expiresAt, err := t.provider(ctx)
+	if err != nil {
+		return "", err
+	}
+	var refreshAt time.Time
+	if !expiresAt.IsZero() {
+		now := time.Now()
+		lifetime := max(expiresAt.Sub(now), 0)
+		refreshAt = now.Add(lifetime*8/10 + time.Duration(rand.Int64N(int64(lifetime)/10+1)))
+	}
+	t.mu.Lock()
+	t.value, t.ok, t.refreshAt, t.expiresAt = value, true, refreshAt, expiresAt
+	t.mu.Unlock()
+	return value, nil
+}
+
+func (t *refreshingRPCCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
 func getGrpcHost(endpoint string) (string, error) {
 	var host string
 	if strings.HasPrefix(endpoint, "grpc://") {
@@ -48,10 +124,19 @@ func getGrpcHost(endpoint string) (string, error) {
 
 func getDialOpts(name string, p Config) ([]grpc.DialOption, error) {
 	var dialOpts []grpc.DialOption
-	if p.GRPC.CredentialsKey != "" {
+	if p.GRPCCredentialsProvider != nil {
+		key := p.GRPC.CredentialsKey
+		if key == "" {
+			key = "authorization"
+		}
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(&refreshingRPCCredentials{
+			key:      key,
+			provider: p.GRPCCredentialsProvider,
+		}))
+	} else if p.GRPC.CredentialsKey != "" {
 		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(&rpcCredentials{
 			key:   p.GRPC.CredentialsKey,
-			value: p.GRPC.CredentialsValue,
+			value: string(p.GRPC.CredentialsValue),
 		}))
 	}
 	if p.GRPC.TLS.Enabled {
@@ -59,6 +144,9 @@ func getDialOpts(name string, p Config) ([]grpc.DialOption, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create TLS config %v", err)
 		}
+		if tlsConfig.InsecureSkipVerify {
+			warnInsecureSkipVerify("proxy_grpc:" + name)
+		}
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -67,6 +155,23 @@ func getDialOpts(name string, p Config) ([]grpc.DialOption, error) {
 		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
 	}
 
+	dnsRefreshOpts, err := dnsRefreshDialOpts(p)
+	if err != nil {
+		return nil, err
+	}
+	dialOpts = append(dialOpts, dnsRefreshOpts...)
+
+	if connectTimeout := proxyConnectTimeout(p); connectTimeout > 0 {
+		// GRPC gives connection attempt at least the current reconnect backoff delay, so base
+		// delay is lowered to not exceed connect timeout.
+		backoffConfig := backoff.DefaultConfig
+		backoffConfig.BaseDelay = min(backoffConfig.BaseDelay, connectTimeout)
+		dialOpts = append(dialOpts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoffConfig,
+			MinConnectTimeout: connectTimeout,
+		}))
+	}
+
 	if p.TestGrpcDialer != nil {
 		dialOpts = append(dialOpts, grpc.WithContextDialer(p.TestGrpcDialer))
 	}
@@ -76,9 +181,37 @@ func getDialOpts(name string, p Config) ([]grpc.DialOption, error) {
 
 func grpcRequestContext(ctx context.Context, proxy Config) context.Context {
 	md := requestMetadata(ctx, proxy.HttpHeaders, proxy.GrpcMetadata, proxy.GRPC.StaticMetadata)
+	if proxy.BinaryEncoding {
+		binaryMetadata(md)
+	}
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
+// binaryMetadata moves values which are not printable ASCII to keys with -bin suffix. GRPC
+// rejects such values for other keys, while values of -bin keys are base64-encoded on the wire.
+// Only used with BinaryEncoding, so that backend expecting original key names is not affected.
+func binaryMetadata(md metadata.MD) {
+	var keys []string
+	for k, vv := range md {
+		if !strings.HasSuffix(k, "-bin") && slices.ContainsFunc(vv, func(v string) bool { return !isPrintableASCII(v) }) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		md.Append(k+"-bin", md[k]...)
+		delete(md, k)
+	}
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
 func requestMetadata(ctx context.Context, allowedHeaders []string, allowedMetaKeys []string, staticMetadata map[string]string) metadata.MD {
 	requestMD := metadata.MD{}
 
@@ -112,3 +245,31 @@ func requestMetadata(ctx context.Context, allowedHeaders []string, allowedMetaKe
 
 	return requestMD
 }
+
+func transformGRPCStatusError(err error, transforms []configtypes.GrpcStatusToCodeTransform) (*proxyproto.Error, *proxyproto.Disconnect) {
+	if len(transforms) == 0 {
+		return nil, nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, nil
+	}
+	for _, t := range transforms {
+		if codes.Code(t.StatusCode) == st.Code() {
+			if t.ToError.Code > 0 {
+				return &proxyproto.Error{
+					Code:      t.ToError.Code,
+					Message:   t.ToError.Message,
+					Temporary: t.ToError.Temporary,
+				}, nil
+			}
+			if t.ToDisconnect.Code > 0 {
+				return nil, &proxyproto.Disconnect{
+					Code:   t.ToDisconnect.Code,
+					Reason: t.ToDisconnect.Reason,
+				}
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,143 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+type weightedGRPCConn struct {
+	conn        *grpc.ClientConn
+	client      proxyproto.CentrifugoProxyClient
+	batchClient proxyproto.CentrifugoProxyBatchClient
+	weight      int
+	// current is a smooth weighted round-robin state, see pick.
+	current int
+}
+
+// weightedGRPCConns maintains one ClientConn per configured GRPC endpoint and distributes
+// calls between them using smooth weighted round-robin (the one used by Nginx). Connections
+// in TRANSIENT_FAILURE or SHUTDOWN state are skipped while there are other candidates.
+type weightedGRPCConns struct {
+	mu    sync.Mutex
+	conns []*weightedGRPCConn
+}
+
+func newWeightedGRPCConns(name string, p Config) (*weightedGRPCConns, error) {
+	endpoints := p.GRPC.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = []configtypes.GrpcWeightedEndpoint{{Endpoint: p.Endpoint, Weight: 1}}
+	}
+	dialOpts, err := getDialOpts(name, p)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GRPC dial options: %v", err)
+	}
+	b := &weightedGRPCConns{}
+	for _, e := range endpoints {
+		host, err := getGrpcHost(string(e.Endpoint))
+		if err != nil {
+			_ = b.Close()
+			return nil, fmt.Errorf("error getting grpc host: %v", err)
+		}
+		conn, err := grpc.NewClient(host, dialOpts...)
+		if err != nil {
+			_ = b.Close()
+			return nil, fmt.Errorf("error connecting to GRPC proxy server: %v", err)
+		}
+		weight := e.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		b.conns = append(b.conns, &weightedGRPCConn{
+			conn:        conn,
+			client:      proxyproto.NewCentrifugoProxyClient(conn),
+			batchClient: proxyproto.NewCentrifugoProxyBatchClient(conn),
+			weight:      weight,
+		})
+	}
+	return b, nil
+}
+
+// ErrGRPCConnNotReady returned by GRPC proxy constructor when EagerConnect option is on and
+// connection did not become READY within EagerConnectTimeout.
+var ErrGRPCConnNotReady = errors.New("GRPC connection not ready")
+
+// waitReady connects to all endpoints and waits until all connections are READY.
+func (b *weightedGRPCConns) waitReady(ctx context.Context) error {
+	for _, c := range b.conns {
+		c.conn.Connect()
+	}
+	for _, c := range b.conns {
+		for {
+			state := c.conn.GetState()
+			if state == connectivity.Ready {
+				break
+			}
+			if !c.conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("%w: connection to %s is in %s state: %w", ErrGRPCConnNotReady, c.conn.Target(), state, ctx.Err())
+			}
+		}
+	}
+	return nil
+}
+
+func isHealthyGRPCConn(conn *grpc.ClientConn) bool {
+	state := conn.GetState()
+	return state != connectivity.TransientFailure && state != connectivity.Shutdown
+}
+
+// pick returns client to use for the next call.
+func (b *weightedGRPCConns) pick() proxyproto.CentrifugoProxyClient {
+	return b.pickConn().client
+}
+
+// pickConn returns connection to use for the next call. If all connections are unhealthy
+// then picking is done among all of them – so that calls still trigger reconnects.
+func (b *weightedGRPCConns) pickConn() *weightedGRPCConn {
+	if len(b.conns) == 1 {
+		return b.conns[0]
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if best := b.pickLocked(true); best != nil {
+		return best
+	}
+	return b.pickLocked(false)
+}
+
+func (b *weightedGRPCConns) pickLocked(healthyOnly bool) *weightedGRPCConn {
+	var best *weightedGRPCConn
+	total := 0
+	for _, c := range b.conns {
+		if healthyOnly && !isHealthyGRPCConn(c.conn) {
+			continue
+		}
+		c.current += c.weight
+		total += c.weight
+		if best == nil || c.current > best.current {
+			best = c
+		}
+	}
+	if best != nil {
+		best.current -= total
+	}
+	return best
+}
+
+// Close closes all connections.
+func (b *weightedGRPCConns) Close() error {
+	var errs []error
+	for _, c := range b.conns {
+		if err := c.conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+type cacheEmptyCountingServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	calls atomic.Int64
+}
+
+func (s *cacheEmptyCountingServer) NotifyCacheEmpty(_ context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	s.calls.Add(1)
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+}
+
+func startCacheEmptyCountingServer(t *testing.T) (*cacheEmptyCountingServer, *grpc.Server, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	counter := &cacheEmptyCountingServer{}
+	proxyproto.RegisterCentrifugoProxyServer(server, counter)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return counter, server, listener.Addr().String()
+}
+
+func TestGRPCCacheEmptyProxyWeightedRoundRobin(t *testing.T) {
+	weights := []int{1, 2, 5}
+	var counters []*cacheEmptyCountingServer
+	var endpoints configtypes.GrpcWeightedEndpoints
+	for _, w := range weights {
+		counter, _, addr := startCacheEmptyCountingServer(t)
+		counters = append(counters, counter)
+		endpoints = append(endpoints, configtypes.GrpcWeightedEndpoint{Endpoint: configtypes.String(addr), Weight: w})
+	}
+
+	cfg := Config{Timeout: configtypes.Duration(5 * time.Second)}
+	cfg.GRPC.Endpoints = endpoints
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	const numCalls = 800
+	for i := 0; i < numCalls; i++ {
+		_, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		require.NoError(t, err)
+	}
+
+	totalWeight := 0
+	for _, w := range weights {
+		totalWeight += w
+	}
+	for i, w := range weights {
+		expected := float64(numCalls*w) / float64(totalWeight)
+		require.InDelta(t, expected, float64(counters[i].calls.Load()), expected*0.1, "endpoint %d", i)
+	}
+}
+
+func TestGRPCCacheEmptyProxySkipsUnhealthy(t *testing.T) {
+	healthy, _, healthyAddr := startCacheEmptyCountingServer(t)
+	_, unhealthyServer, unhealthyAddr := startCacheEmptyCountingServer(t)
+
+	cfg := Config{Timeout: configtypes.Duration(time.Second)}
+	cfg.GRPC.Endpoints = configtypes.GrpcWeightedEndpoints{
+		{Endpoint: configtypes.String(healthyAddr), Weight: 1},
+		{Endpoint: configtypes.String(unhealthyAddr), Weight: 1},
+	}
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	unhealthyServer.Stop()
+	unhealthyConn := p.conns.conns[1].conn
+	unhealthyConn.Connect()
+	require.Eventually(t, func() bool {
+		return unhealthyConn.GetState() == connectivity.TransientFailure
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		_, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(10), healthy.calls.Load())
+}
+
+func TestWeightedGRPCConnsPickOrder(t *testing.T) {
+	cfg := Config{}
+	cfg.GRPC.Endpoints = configtypes.GrpcWeightedEndpoints{
+		{Endpoint: "127.0.0.1:1", Weight: 5},
+		{Endpoint: "127.0.0.1:2", Weight: 1},
+		{Endpoint: "127.0.0.1:3"},
+	}
+	b, err := newWeightedGRPCConns("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = b.Close() }()
+
+	picks := map[proxyproto.CentrifugoProxyClient]int{}
+	for i := 0; i < 7; i++ {
+		picks[b.pick()]++
+	}
+	// Smooth weighted round-robin is exact over a full cycle of total weight.
+	require.Equal(t, 5, picks[b.conns[0].client])
+	require.Equal(t, 1, picks[b.conns[1].client])
+	require.Equal(t, 1, picks[b.conns[2].client])
+}
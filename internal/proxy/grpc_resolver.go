@@ -0,0 +1,112 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+)
+
+// minDNSRefreshInterval is a minimal interval of re-resolution by GRPC DNS resolver, it ignores
+// ResolveNow calls made earlier.
+const minDNSRefreshInterval = 30 * time.Second
+
+// roundRobinServiceConfig makes GRPC client balance calls over all resolved addresses.
+const roundRobinServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+
+// grpcTargetScheme returns resolver scheme of GRPC target, GRPC uses dns by default.
+func grpcTargetScheme(target string) string {
+	if i := strings.Index(target, ":///"); i > 0 {
+		return target[:i]
+	}
+	return "dns"
+}
+
+// dnsRefreshDialOpts returns dial options to periodically re-resolve targets of GRPC proxy. By
+// default GRPC re-resolves name only upon connection errors – so after rollout of headless
+// service the client may never learn about new addresses. Returns error if interval is less than
+// minDNSRefreshInterval for dns targets – since refreshing would silently happen less often.
+func dnsRefreshDialOpts(p Config) ([]grpc.DialOption, error) {
+	interval := p.GRPC.DNSRefreshInterval.ToDuration()
+	if interval <= 0 {
+		return nil, nil
+	}
+	targets := []string{string(p.Endpoint)}
+	for _, e := range p.GRPC.Endpoints {
+		targets = append(targets, string(e.Endpoint))
+	}
+	var builders []resolver.Builder
+	seen := map[string]struct{}{}
+	for _, target := range targets {
+		if target == "" {
+			continue
+		}
+		host, err := getGrpcHost(target)
+		if err != nil {
+			continue
+		}
+		scheme := grpcTargetScheme(host)
+		if _, ok := seen[scheme]; ok {
+			continue
+		}
+		seen[scheme] = struct{}{}
+		if scheme == "dns" && interval < minDNSRefreshInterval {
+			return nil, fmt.Errorf("grpc.dns_refresh_interval %s is less than minimal re-resolution interval %s of GRPC DNS resolver", interval, minDNSRefreshInterval)
+		}
+		if b := resolver.Get(scheme); b != nil {
+			builders = append(builders, &periodicResolverBuilder{Builder: b, interval: interval})
+		}
+	}
+	return []grpc.DialOption{
+		grpc.WithResolvers(builders...),
+		grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
+	}, nil
+}
+
+// periodicResolverBuilder wraps resolver.Builder to call ResolveNow with interval.
+type periodicResolverBuilder struct {
+	resolver.Builder
+	interval time.Duration
+}
+
+func (b *periodicResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	r, err := b.Builder.Build(target, cc, opts)
+	if err != nil {
+		return nil, err
+	}
+	pr := &periodicResolver{
+		Resolver: r,
+		closeCh:  make(chan struct{}),
+	}
+	go pr.run(b.interval)
+	return pr, nil
+}
+
+type periodicResolver struct {
+	resolver.Resolver
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+func (r *periodicResolver) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Resolver.ResolveNow(resolver.ResolveNowOptions{})
+		case <-r.closeCh:
+			return
+		}
+	}
+}
+
+func (r *periodicResolver) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closeCh)
+		r.Resolver.Close()
+	})
+}
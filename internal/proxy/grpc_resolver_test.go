@@ -0,0 +1,203 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+)
+
+// changingResolverBuilder resolves any target into addresses set by test, new addresses
+// are only pushed to client upon ResolveNow – like DNS resolver does.
+type changingResolverBuilder struct {
+	mu    sync.Mutex
+	addrs []string
+}
+
+func (b *changingResolverBuilder) setAddrs(addrs ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.addrs = addrs
+}
+
+func (b *changingResolverBuilder) state() resolver.State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var state resolver.State
+	for _, addr := range b.addrs {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+	return state
+}
+
+func (b *changingResolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	r := &changingResolver{builder: b, cc: cc}
+	_ = cc.UpdateState(b.state())
+	return r, nil
+}
+
+func (b *changingResolverBuilder) Scheme() string {
+	return "changing"
+}
+
+type changingResolver struct {
+	builder *changingResolverBuilder
+	cc      resolver.ClientConn
+}
+
+func (r *changingResolver) ResolveNow(resolver.ResolveNowOptions) {
+	_ = r.cc.UpdateState(r.builder.state())
+}
+
+func (r *changingResolver) Close() {}
+
+func TestGRPCCacheEmptyProxyDNSRefresh(t *testing.T) {
+	oldServer, _, oldAddr := startCacheEmptyCountingServer(t)
+	newServer, _, newAddr := startCacheEmptyCountingServer(t)
+
+	builder := &changingResolverBuilder{}
+	builder.setAddrs(oldAddr)
+	resolver.Register(builder)
+
+	cfg := Config{
+		Endpoint: "changing:///backend",
+		Timeout:  configtypes.Duration(time.Second),
+	}
+	cfg.GRPC.DNSRefreshInterval = configtypes.Duration(20 * time.Millisecond)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	req := &proxyproto.NotifyCacheEmptyRequest{Channel: "test"}
+	_, err = p.ProxyCacheEmpty(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), oldServer.calls.Load())
+
+	// Old address is still reachable, so only periodic re-resolution may move calls to new one.
+	builder.setAddrs(newAddr)
+	require.Eventually(t, func() bool {
+		_, err := p.ProxyCacheEmpty(context.Background(), req)
+		require.NoError(t, err)
+		return newServer.calls.Load() > 0
+	}, 5*time.Second, 20*time.Millisecond)
+
+	oldCalls := oldServer.calls.Load()
+	for i := 0; i < 10; i++ {
+		_, err = p.ProxyCacheEmpty(context.Background(), req)
+		require.NoError(t, err)
+	}
+	require.Equal(t, oldCalls, oldServer.calls.Load())
+}
+
+// startDNSServer starts UDP DNS server answering A queries for any name with addrs, other
+// queries get empty answer.
+func startDNSServer(t *testing.T, addrs ...[4]byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var parser dnsmessage.Parser
+			header, err := parser.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			question, err := parser.Question()
+			if err != nil {
+				continue
+			}
+			builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, Authoritative: true})
+			_ = builder.StartQuestions()
+			_ = builder.Question(question)
+			_ = builder.StartAnswers()
+			if question.Type == dnsmessage.TypeA {
+				for _, a := range addrs {
+					_ = builder.AResource(dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60}, dnsmessage.AResource{A: a})
+				}
+			}
+			msg, err := builder.Finish()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(msg, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestGRPCCacheEmptyProxyDNSRefreshDNSScheme(t *testing.T) {
+	// Name resolves into two loopback addresses with backend listening on the same port.
+	var servers []*cacheEmptyCountingServer
+	port := "0"
+	for _, host := range []string{"127.0.0.1", "127.0.0.2"} {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+		require.NoError(t, err)
+		port = strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+		server := grpc.NewServer()
+		counter := &cacheEmptyCountingServer{}
+		proxyproto.RegisterCentrifugoProxyServer(server, counter)
+		go func() { _ = server.Serve(listener) }()
+		t.Cleanup(server.Stop)
+		servers = append(servers, counter)
+	}
+	dnsAddr := startDNSServer(t, [4]byte{127, 0, 0, 1}, [4]byte{127, 0, 0, 2})
+
+	cfg := Config{
+		Endpoint: configtypes.String("dns://" + dnsAddr + "/backend.test:" + port),
+		Timeout:  configtypes.Duration(5 * time.Second),
+	}
+	cfg.GRPC.DNSRefreshInterval = configtypes.Duration(10 * time.Second)
+	_, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.ErrorContains(t, err, "less than minimal re-resolution interval")
+
+	cfg.GRPC.DNSRefreshInterval = configtypes.Duration(minDNSRefreshInterval)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	// With default pick_first policy all calls would go to the first address.
+	require.Eventually(t, func() bool {
+		_, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+		require.NoError(t, err)
+		return servers[0].calls.Load() > 0 && servers[1].calls.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDNSRefreshDialOpts(t *testing.T) {
+	opts, err := dnsRefreshDialOpts(Config{Endpoint: "dns:///backend:10000"})
+	require.NoError(t, err)
+	require.Nil(t, opts)
+
+	cfg := Config{Endpoint: "passthrough:///backend:10000"}
+	cfg.GRPC.DNSRefreshInterval = configtypes.Duration(time.Second)
+	_, err = dnsRefreshDialOpts(cfg)
+	require.NoError(t, err)
+
+	for _, endpoint := range []string{"backend:10000", "grpc://backend:10000", "dns:///backend:10000"} {
+		cfg.Endpoint = configtypes.String(endpoint)
+		_, err = dnsRefreshDialOpts(cfg)
+		require.ErrorContains(t, err, "less than minimal re-resolution interval", endpoint)
+	}
+}
+
+func TestGRPCTargetScheme(t *testing.T) {
+	require.Equal(t, "dns", grpcTargetScheme("backend:10000"))
+	require.Equal(t, "dns", grpcTargetScheme("dns:///backend:10000"))
+	require.Equal(t, "passthrough", grpcTargetScheme("passthrough:///127.0.0.1:10000"))
+}
@@ -2,20 +2,33 @@ package proxy
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/centrifugal/centrifugo/v6/internal/build"
 	"github.com/centrifugal/centrifugo/v6/internal/clientcontext"
 	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
 	"github.com/centrifugal/centrifugo/v6/internal/middleware"
 	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
 
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/http/httpproxy"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -32,46 +45,213 @@ var httpDecoder = &proxyproto.JSONDecoder{}
 // DefaultMaxIdleConnsPerHost is a reasonable value for all HTTP clients.
 const DefaultMaxIdleConnsPerHost = 255
 
+// defaultMaxResponseSize is used when MaxResponseSize option is not set.
+const defaultMaxResponseSize = 64 << 20
+
+// ErrResponseTooLarge returned by HTTPCaller when response body exceeds MaxResponseSize.
+var ErrResponseTooLarge = errors.New("HTTP response size exceeds limit")
+
+// ErrRedirectNotFollowed returned by HTTPCaller when endpoint responds with redirect and
+// FollowRedirects option is off, or when MaxRedirects were already followed.
+var ErrRedirectNotFollowed = errors.New("HTTP redirect not followed")
+
+// defaultMaxRedirects is a limit of redirects followed with FollowRedirects option, same as
+// default limit of http.Client.
+const defaultMaxRedirects = 10
+
+// ErrTruncatedResponse returned by HTTPCaller when connection was closed or reset before the
+// whole response body was received. Unlike decoding errors of malformed payloads it's a
+// transport error, so call is retried.
+var ErrTruncatedResponse = errors.New("HTTP response truncated")
+
+// ErrUnexpectedContentType returned by HTTPCaller with content type validation when response
+// Content-Type does not match expected one.
+var ErrUnexpectedContentType = errors.New("unexpected HTTP response content type")
+
+// contentTypeErrorBodySize is a number of response body bytes included into
+// ErrUnexpectedContentType error for diagnosis.
+const contentTypeErrorBodySize = 256
+
+// compressRequestMinSize is a minimal size of request payload to be compressed when
+// CompressRequest option is on. Compressing smaller payloads is not worth CPU time.
+const compressRequestMinSize = 1024
+
 // HTTPCaller is responsible for calling HTTP.
 type HTTPCaller interface {
 	CallHTTP(context.Context, string, http.Header, []byte) ([]byte, error)
 }
 
 type httpCaller struct {
-	Endpoint   string
-	HTTPClient *http.Client
+	Endpoint        string
+	HTTPClient      *http.Client
+	MaxResponseSize int64
+	// ContentType of successful responses (media type only), not checked if empty.
+	ContentType string
+}
+
+// NewHTTPCaller creates new HTTPCaller. Response bodies larger than maxResponseSize bytes
+// result into ErrResponseTooLarge, zero value means defaultMaxResponseSize.
+func NewHTTPCaller(httpClient *http.Client, maxResponseSize int64) HTTPCaller {
+	return newHTTPCaller(httpClient, maxResponseSize, "")
 }
 
-// NewHTTPCaller creates new HTTPCaller.
-func NewHTTPCaller(httpClient *http.Client) HTTPCaller {
+// newHTTPCaller creates HTTPCaller which also returns ErrUnexpectedContentType for successful
+// responses with media type other than contentType – unless contentType is empty.
+func newHTTPCaller(httpClient *http.Client, maxResponseSize int64, contentType string) *httpCaller {
+	if maxResponseSize <= 0 {
+		maxResponseSize = defaultMaxResponseSize
+	}
 	return &httpCaller{
-		HTTPClient: httpClient,
+		HTTPClient:      httpClient,
+		MaxResponseSize: maxResponseSize,
+		ContentType:     contentType,
 	}
 }
 
 func proxyHTTPClient(p configtypes.Proxy, logTraceEntity string) (*http.Client, error) {
 	var tlsConfig *tls.Config
-	if p.HTTP.TLS.Enabled {
+	if p.HTTP.TLS.Enabled || p.HTTP.TLS.InsecureSkipVerify {
+		// InsecureSkipVerify alone is enough to skip verification – TLS is used for https://
+		// endpoints anyway.
+		tlsOptions := p.HTTP.TLS
+		tlsOptions.Enabled = true
 		var err error
-		tlsConfig, err = p.HTTP.TLS.ToGoTLSConfig(logTraceEntity)
+		tlsConfig, err = tlsOptions.ToGoTLSConfig(logTraceEntity)
 		if err != nil {
 			return nil, fmt.Errorf("error creating TLS config: %w", err)
 		}
+		if tlsConfig.InsecureSkipVerify {
+			warnInsecureSkipVerify(logTraceEntity)
+		}
+	}
+	proxyFunc, err := forwardProxyFunc(p)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring forward proxy: %w", err)
+	}
+	connectTimeout := proxyConnectTimeout(p)
+	var transport http.RoundTripper = &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: connectTimeout,
+		MaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+		TLSClientConfig:     tlsConfig,
+		Proxy:               proxyFunc,
+		// Compressed responses are negotiated explicitly with AcceptCompressedResponse
+		// option and decompressed by httpCaller.
+		DisableCompression: true,
+	}
+	if p.HTTPRoundTripperFactory != nil {
+		transport = p.HTTPRoundTripperFactory(transport)
 	}
 	return &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
-			TLSClientConfig:     tlsConfig,
-		},
-		Timeout: p.Timeout.ToDuration(),
+		Transport:     transport,
+		Timeout:       p.Timeout.ToDuration(),
+		CheckRedirect: checkRedirect(p),
 	}, nil
 }
 
+// checkRedirect returns http.Client CheckRedirect func which follows redirects only with
+// FollowRedirects option. Signature of redirected request is computed again for the body
+// sent – body is dropped when POST is redirected with 301, 302 or 303.
+func checkRedirect(p configtypes.Proxy) func(*http.Request, []*http.Request) error {
+	maxRedirects := p.HTTP.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if !p.HTTP.FollowRedirects {
+			return fmt.Errorf("%w: %d to %s", ErrRedirectNotFollowed, req.Response.StatusCode, req.URL.Redacted())
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects at %s", ErrRedirectNotFollowed, maxRedirects, req.URL.Redacted())
+		}
+		if secret, _ := signingSecret(p); secret == "" {
+			return nil
+		}
+		var data []byte
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return fmt.Errorf("error getting body of redirected request: %w", err)
+			}
+			data, err = io.ReadAll(body)
+			if err != nil {
+				return fmt.Errorf("error reading body of redirected request: %w", err)
+			}
+		}
+		req.Header.Del("X-Centrifugo-Signature")
+		maybeSignRequest(p, req.Header, data)
+		return nil
+	}
+}
+
+// proxyConnectTimeout returns timeout of establishing connection to backend.
+func proxyConnectTimeout(p configtypes.Proxy) time.Duration {
+	if p.ConnectTimeout > 0 {
+		return p.ConnectTimeout.ToDuration()
+	}
+	return p.Timeout.ToDuration() / 2
+}
+
+// forwardProxyFunc returns http.Transport Proxy func. Returns nil if forward proxy not configured.
+func forwardProxyFunc(p configtypes.Proxy) (func(*http.Request) (*url.URL, error), error) {
+	if p.HTTP.ForwardProxyURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(string(p.HTTP.ForwardProxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid forward proxy URL: %w", err)
+	}
+	if !slices.Contains([]string{"http", "https", "socks5"}, u.Scheme) || u.Host == "" {
+		return nil, fmt.Errorf("unsupported forward proxy URL %s, must be http://, https:// or socks5:// URL", u.Redacted())
+	}
+	cfg := httpproxy.Config{
+		HTTPProxy:  u.String(),
+		HTTPSProxy: u.String(),
+		NoProxy:    strings.Join(p.HTTP.ForwardProxyNoProxy, ","),
+	}
+	proxyURL := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyURL(req.URL)
+	}, nil
+}
+
+// timeoutHeader passes remaining time budget of proxy call to backend in milliseconds, name
+// may be changed with HTTP.TimeoutHeader option.
+const timeoutHeader = "X-Centrifugo-Timeout-Ms"
+
+// setTimeoutHeader sets timeout header from ctx deadline, nothing is set if ctx has no deadline.
+func setTimeoutHeader(ctx context.Context, proxy Config, header http.Header) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	name := proxy.HTTP.TimeoutHeader
+	if name == "" {
+		name = timeoutHeader
+	}
+	remaining := max(time.Until(deadline).Milliseconds(), 1)
+	header.Set(name, strconv.FormatInt(remaining, 10))
+}
+
+// defaultUserAgent is used for proxy requests when User-Agent was not set explicitly.
+func defaultUserAgent() string {
+	return "Centrifugo/" + build.Version
+}
+
 type statusCodeError struct {
 	Code int
+	// RetryAfter is a delay from Retry-After header of 429 and 503 responses.
+	RetryAfter    time.Duration
+	hasRetryAfter bool
 }
 
 func (e *statusCodeError) Error() string {
+	if e.hasRetryAfter {
+		return fmt.Sprintf("unexpected HTTP status code: %d, retry after %s", e.Code, e.RetryAfter)
+	}
 	return fmt.Sprintf("unexpected HTTP status code: %d", e.Code)
 }
 
@@ -81,21 +261,131 @@ func (c *httpCaller) CallHTTP(ctx context.Context, endpoint string, header http.
 		return nil, fmt.Errorf("error constructing HTTP request: %w", err)
 	}
 	req.Header = header
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", defaultUserAgent())
+	}
 	resp, err := c.HTTPClient.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request error: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusOK {
-		return nil, &statusCodeError{resp.StatusCode}
+		statusErr := &statusCodeError{Code: resp.StatusCode}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			statusErr.RetryAfter, statusErr.hasRetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return nil, statusErr
+	}
+	if c.ContentType != "" {
+		if err := c.checkContentType(resp); err != nil {
+			return nil, err
+		}
 	}
-	respData, err := io.ReadAll(resp.Body)
+	if resp.ContentLength > c.MaxResponseSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, c.MaxResponseSize)
+	}
+	respData, err := io.ReadAll(io.LimitReader(resp.Body, c.MaxResponseSize+1))
 	if err != nil {
+		if isTruncatedRead(err) {
+			return nil, fmt.Errorf("%w: %w", ErrTruncatedResponse, err)
+		}
 		return nil, fmt.Errorf("error reading HTTP body: %w", err)
 	}
+	if int64(len(respData)) > c.MaxResponseSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, c.MaxResponseSize)
+	}
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return c.decompressResponse(respData)
+	}
 	return respData, nil
 }
 
+// isTruncatedRead reports whether error of reading response body means that connection was
+// closed before the end of body – shorter body than Content-Length or an incomplete chunked one.
+func isTruncatedRead(err error) bool {
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, net.ErrClosed)
+}
+
+// checkContentType returns ErrUnexpectedContentType with the beginning of response body if
+// response media type differs from expected one.
+func (c *httpCaller) checkContentType(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && strings.EqualFold(mediaType, c.ContentType) {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, contentTypeErrorBodySize))
+	return fmt.Errorf("%w: %q instead of %q, body starts with: %q", ErrUnexpectedContentType, contentType, c.ContentType, body)
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompressResponse decompresses gzip response body. Some servers set Content-Encoding: gzip
+// but send uncompressed body – such body is returned as is. Size limit is applied to
+// decompressed data too.
+func (c *httpCaller) decompressResponse(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing HTTP body: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+	respData, err := io.ReadAll(io.LimitReader(gz, c.MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing HTTP body: %w", err)
+	}
+	if int64(len(respData)) > c.MaxResponseSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, c.MaxResponseSize)
+	}
+	return respData, nil
+}
+
+// maybeCompressRequest gzips request data if compression enabled and data is large enough.
+// Content-Encoding header is set accordingly.
+func maybeCompressRequest(proxy Config, header http.Header, data []byte) ([]byte, error) {
+	if !proxy.HTTP.CompressRequest || len(data) < compressRequestMinSize {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, fmt.Errorf("error compressing request: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("error compressing request: %w", err)
+	}
+	header.Set("Content-Encoding", "gzip")
+	return buf.Bytes(), nil
+}
+
+// signingSecret returns active request signing secret and its key ID, empty secret means
+// signing is not configured.
+func signingSecret(proxy Config) (string, string) {
+	if len(proxy.HTTP.SigningKeys) > 0 {
+		return proxy.HTTP.SigningKeys[proxy.HTTP.SigningKeyID], proxy.HTTP.SigningKeyID
+	}
+	return string(proxy.HTTP.SigningSecret), proxy.HTTP.SigningKeyID
+}
+
+// maybeSignRequest sets X-Centrifugo-Signature header with hex encoded HMAC-SHA256 of data
+// and X-Centrifugo-Key-Id header with key ID used. Must be called with data as sent over the
+// wire, i.e. after compression.
+func maybeSignRequest(proxy Config, header http.Header, data []byte) {
+	secret, keyID := signingSecret(proxy)
+	if secret == "" {
+		return
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(data)
+	header.Set("X-Centrifugo-Signature", hex.EncodeToString(mac.Sum(nil)))
+	if keyID != "" {
+		header.Set("X-Centrifugo-Key-Id", keyID)
+	}
+}
+
 func transformHTTPStatusError(err error, transforms []configtypes.HttpStatusToCodeTransform) (*proxyproto.Error, *proxyproto.Disconnect) {
 	if len(transforms) == 0 {
 		return nil, nil
@@ -125,7 +415,41 @@ func transformHTTPStatusError(err error, transforms []configtypes.HttpStatusToCo
 }
 
 func httpRequestHeaders(ctx context.Context, proxy Config) http.Header {
-	return requestHeaders(ctx, proxy.HttpHeaders, proxy.GrpcMetadata, proxy.HTTP.StaticHeaders)
+	headers := requestHeaders(ctx, proxy.HttpHeaders, proxy.GrpcMetadata, proxy.HTTP.StaticHeaders)
+	if limit := proxy.HTTP.MaxForwardedHeaderBytes.Bytes(); limit > 0 {
+		dropOversizedHeaders(headers, limit, proxy.HTTP.StaticHeaders)
+	}
+	if proxy.HTTP.UserAgent != "" && headers.Get("User-Agent") == "" {
+		headers.Set("User-Agent", proxy.HTTP.UserAgent)
+	}
+	if proxy.HTTP.AcceptCompressedResponse {
+		// Overrides proxied client value since only gzip may be decompressed.
+		headers.Set("Accept-Encoding", "gzip")
+	}
+	return headers
+}
+
+// dropOversizedHeaders removes forwarded headers which name and values together take more
+// than limit bytes. Static headers are set by configuration, so kept as is unless overridden
+// by a forwarded value.
+func dropOversizedHeaders(headers http.Header, limit int64, staticHeaders map[string]string) {
+	static := make(map[string]string, len(staticHeaders))
+	for k, v := range staticHeaders {
+		static[http.CanonicalHeaderKey(k)] = v
+	}
+	for k, vv := range headers {
+		if v, ok := static[k]; ok && len(vv) == 1 && vv[0] == v {
+			continue
+		}
+		size := int64(0)
+		for _, v := range vv {
+			size += int64(len(k) + len(v))
+		}
+		if size > limit {
+			log.Debug().Str("header", k).Int64("size", size).Int64("limit", limit).Msg("dropping oversized header forwarded to proxy")
+			delete(headers, k)
+		}
+	}
 }
 
 func requestHeaders(ctx context.Context, allowedHeaders, allowedMetaKeys []string, staticHeaders map[string]string) http.Header {
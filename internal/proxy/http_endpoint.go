@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// channelPlaceholder in HTTP proxy endpoint is substituted with URL-escaped channel.
+const channelPlaceholder = "{channel}"
+
+// ErrChannelNotAllowedInEndpoint is returned when channel can't be safely substituted into
+// endpoint template.
+var ErrChannelNotAllowedInEndpoint = errors.New("channel not allowed in endpoint")
+
+// channelEndpoint is an HTTP endpoint which may contain channelPlaceholder. Placeholder in
+// path is substituted with channel as a single path segment – so channels with "/" and
+// dot segments are rejected. Placeholder in query accepts any printable channel.
+type channelEndpoint struct {
+	endpoint string
+	// inQuery is true if placeholder is located in query part of endpoint.
+	inQuery bool
+}
+
+func newChannelEndpoint(endpoint string) channelEndpoint {
+	i := strings.Index(endpoint, channelPlaceholder)
+	if i < 0 {
+		return channelEndpoint{endpoint: endpoint}
+	}
+	return channelEndpoint{
+		endpoint: endpoint,
+		inQuery:  strings.Contains(endpoint[:i], "?"),
+	}
+}
+
+// URL returns endpoint URL for channel.
+func (e channelEndpoint) URL(channel string) (string, error) {
+	if !strings.Contains(e.endpoint, channelPlaceholder) {
+		return e.endpoint, nil
+	}
+	if channel == "" {
+		return "", fmt.Errorf("%w: empty channel", ErrChannelNotAllowedInEndpoint)
+	}
+	if strings.ContainsFunc(channel, unicode.IsControl) {
+		return "", fmt.Errorf("%w: control characters in channel", ErrChannelNotAllowedInEndpoint)
+	}
+	var escaped string
+	if e.inQuery {
+		escaped = url.QueryEscape(channel)
+	} else {
+		if strings.Contains(channel, "/") || channel == "." || channel == ".." {
+			return "", fmt.Errorf("%w: channel is not a valid path segment", ErrChannelNotAllowedInEndpoint)
+		}
+		escaped = url.PathEscape(channel)
+	}
+	return strings.ReplaceAll(e.endpoint, channelPlaceholder, escaped), nil
+}
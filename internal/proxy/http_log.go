@@ -0,0 +1,112 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const redactedValue = "***"
+
+// sensitiveHeaders are never logged as is. Values of configured static headers are also
+// redacted since they are usually used to pass secrets to the backend.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// logHTTPPayload logs proxy request or response payload on debug level if LogPayloads
+// option is on. Binary payloads are not logged – only their size.
+func logHTTPPayload(l *zerolog.Logger, name string, p Config, direction string, header http.Header, data []byte, binary bool) {
+	if !p.HTTP.LogPayloads {
+		return
+	}
+	event := l.Debug()
+	if !event.Enabled() {
+		return
+	}
+	event = event.Str("proxy_name", name).Str("direction", direction).Int("size", len(data))
+	if header != nil {
+		event = event.Interface("headers", redactHeaders(header, p.HTTP.StaticHeaders))
+	}
+	if !binary {
+		event = event.Str("payload", redactJSONPayload(data, p.HTTP.RedactFields))
+	}
+	event.Msg("proxy payload")
+}
+
+func redactHeaders(header http.Header, staticHeaders map[string]string) map[string]string {
+	result := make(map[string]string, len(header))
+	for k, vv := range header {
+		if isSensitiveHeader(k, staticHeaders) {
+			result[k] = redactedValue
+			continue
+		}
+		result[k] = strings.Join(vv, ", ")
+	}
+	return result
+}
+
+func isSensitiveHeader(key string, staticHeaders map[string]string) bool {
+	for _, h := range sensitiveHeaders {
+		if strings.EqualFold(h, key) {
+			return true
+		}
+	}
+	for h := range staticHeaders {
+		if strings.EqualFold(h, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSONPayload replaces values of fields with names from redactFields (case-insensitive,
+// on any nesting level) with ***. Payload which is not a valid JSON is not logged at all
+// since we can't guarantee redaction for it.
+func redactJSONPayload(data []byte, redactFields []string) string {
+	if len(data) == 0 {
+		return ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "<invalid JSON>"
+	}
+	if len(redactFields) > 0 {
+		v = redactJSONValue(v, redactFields)
+	}
+	result, err := json.Marshal(v)
+	if err != nil {
+		return "<invalid JSON>"
+	}
+	return string(result)
+}
+
+func redactJSONValue(v any, redactFields []string) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, fieldValue := range val {
+			if isRedactedField(k, redactFields) {
+				val[k] = redactedValue
+				continue
+			}
+			val[k] = redactJSONValue(fieldValue, redactFields)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = redactJSONValue(item, redactFields)
+		}
+	}
+	return v
+}
+
+func isRedactedField(key string, redactFields []string) bool {
+	for _, f := range redactFields {
+		if strings.EqualFold(f, key) {
+			return true
+		}
+	}
+	return false
+}
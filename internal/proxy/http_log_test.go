@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/middleware"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prevLogger, prevLevel := log.Logger, zerolog.GlobalLevel()
+	log.Logger = zerolog.New(zerolog.SyncWriter(&buf))
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() {
+		log.Logger = prevLogger
+		zerolog.SetGlobalLevel(prevLevel)
+	})
+	return &buf
+}
+
+func TestRedactJSONPayload(t *testing.T) {
+	payload := []byte(`{"user":"42","token":"secret-token","data":{"Password":"secret-password","items":[{"token":"secret-nested"}]},"n":12345678901234567890}`)
+	result := redactJSONPayload(payload, []string{"token", "password"})
+	require.NotContains(t, result, "secret")
+	require.JSONEq(t, `{"user":"42","token":"***","data":{"Password":"***","items":[{"token":"***"}]},"n":12345678901234567890}`, result)
+
+	require.Equal(t, "<invalid JSON>", redactJSONPayload([]byte(`{"token":"secret`), []string{"token"}))
+}
+
+func TestCacheEmptyProxyHTTPLogPayloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	}
+	cfg.HttpHeaders = []string{"authorization", "x-request-id"}
+	cfg.HTTP.StaticHeaders = map[string]string{"X-Api-Key": "secret-api-key"}
+	cfg.HTTP.LogPayloads = true
+	cfg.HTTP.RedactFields = []string{"channel"}
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+
+	buf := captureLogs(t)
+	ctx := middleware.SetHeadersToContext(context.Background(), http.Header{
+		"Authorization": []string{"Bearer secret-jwt"},
+		"X-Request-Id":  []string{"request-1"},
+	})
+	resp, err := p.ProxyCacheEmpty(ctx, &proxyproto.NotifyCacheEmptyRequest{Channel: "secret-channel"})
+	require.NoError(t, err)
+	require.True(t, resp.Result.Populated)
+
+	output := buf.String()
+	require.NotContains(t, output, "secret")
+	require.Contains(t, output, `"direction":"request"`)
+	require.Contains(t, output, `"direction":"response"`)
+	require.Contains(t, output, `\"channel\":\"***\"`)
+	require.Contains(t, output, `"Authorization":"***"`)
+	require.Contains(t, output, `"X-Api-Key":"***"`)
+	require.Contains(t, output, `"X-Request-Id":"request-1"`)
+	require.Contains(t, output, `populated`)
+}
+
+func TestCacheEmptyProxyHTTPLogPayloadsDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{}}`))
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	buf := captureLogs(t)
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+}
@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// loggingProxy may be implemented by proxy to provide logger configured with LogLevel option.
+type loggingProxy interface {
+	logger() *zerolog.Logger
+}
+
+// logSlowCall logs warning if call of proxy p for channel took longer than SlowCallThreshold.
+func logSlowCall(p CacheEmptyProxy, config Config, name string, channel string, duration time.Duration) {
+	threshold := config.SlowCallThreshold.ToDuration()
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+	logger := &log.Logger
+	if lp, ok := p.(loggingProxy); ok {
+		logger = lp.logger()
+	}
+	logChannel(logger.Warn().Str("proxy_name", name).Str("protocol", p.Protocol()), p, channel).
+		Dur("duration", duration).Dur("threshold", threshold).Msg("slow proxy call")
+}
+
+// newProxyLogger returns logger for messages related to proxy. Without LogLevel option global
+// logger is used as is. Note that messages below global log level are dropped in any case.
+func newProxyLogger(p Config) *zerolog.Logger {
+	level, ok := parseProxyLogLevel(p.LogLevel)
+	if !ok {
+		return &log.Logger
+	}
+	l := log.Logger.Level(level)
+	return &l
+}
+
+func parseProxyLogLevel(level string) (zerolog.Level, bool) {
+	level = strings.ToLower(level)
+	if level == "" {
+		return zerolog.NoLevel, false
+	}
+	if level == "none" {
+		return zerolog.Disabled, true
+	}
+	l, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return zerolog.NoLevel, false
+	}
+	return l, true
+}
@@ -0,0 +1,152 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEmptyProxyLogLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"populated":false}}`))
+	}))
+	defer server.Close()
+
+	buf := captureLogs(t)
+
+	newProxy := func(path string, logLevel string) *HTTPCacheEmptyProxy {
+		cfg := Config{
+			Endpoint: configtypes.String(server.URL + path),
+			Timeout:  configtypes.Duration(time.Second),
+			LogLevel: logLevel,
+		}
+		cfg.HTTP.LogPayloads = true
+		p, err := NewHTTPCacheEmptyProxy("test", cfg)
+		require.NoError(t, err)
+		return p
+	}
+
+	quiet := newProxy("/", "warn")
+	quietFailing := newProxy("/fail", "warn")
+	verbose := newProxy("/", "")
+	handler := newTestCacheEmptyHandler(t, CacheEmptyHandlerConfig{
+		Proxies: map[string]CacheEmptyProxy{
+			"quiet":         quiet,
+			"quiet_failing": quietFailing,
+			"verbose":       verbose,
+		},
+		ProxyName: func(channel string) (string, bool) { return channel, true },
+	}).Handle()
+
+	quiet.logger().Info().Msg("quiet info")
+	quiet.logger().Warn().Msg("quiet warn")
+	_, err := handler(context.Background(), "quiet")
+	require.NoError(t, err)
+	require.NotContains(t, buf.String(), "quiet info")
+	require.Contains(t, buf.String(), "quiet warn")
+	require.NotContains(t, buf.String(), "proxy payload")
+	require.NotContains(t, buf.String(), "did not populate cache")
+
+	_, err = handler(context.Background(), "quiet_failing")
+	require.Error(t, err)
+	require.Contains(t, buf.String(), "error calling cache empty proxy")
+
+	// Proxy without log level uses global logger.
+	buf.Reset()
+	_, err = handler(context.Background(), "verbose")
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "proxy payload")
+	require.Contains(t, buf.String(), `"proxy_name":"verbose"`)
+	require.Contains(t, buf.String(), "did not populate cache")
+}
+
+// slowCallLogs returns slow proxy call log entries.
+func slowCallLogs(t *testing.T, logs string) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+		if !strings.Contains(line, "slow proxy call") {
+			continue
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestCacheEmptyProxyHTTPSlowCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	defer server.Close()
+	buf := captureLogs(t)
+
+	cfg := Config{
+		Endpoint:          configtypes.String(server.URL),
+		Timeout:           configtypes.Duration(time.Second),
+		SlowCallThreshold: configtypes.Duration(500 * time.Millisecond),
+	}
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "ns:secret"})
+	require.NoError(t, err)
+	require.Empty(t, slowCallLogs(t, buf.String()))
+
+	cfg.SlowCallThreshold = configtypes.Duration(20 * time.Millisecond)
+	p, err = NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "ns:secret"})
+	require.NoError(t, err)
+	entries := slowCallLogs(t, buf.String())
+	require.Len(t, entries, 1)
+	require.Equal(t, "warn", entries[0]["level"])
+	require.Equal(t, "test", entries[0]["proxy_name"])
+	require.Equal(t, "http", entries[0]["protocol"])
+	require.Equal(t, "ns", entries[0]["channel"])
+	require.GreaterOrEqual(t, entries[0]["duration"], float64(50))
+	require.NotContains(t, buf.String(), "secret")
+}
+
+func TestCacheEmptyProxyGRPCSlowCall(t *testing.T) {
+	buf := captureLogs(t)
+	cfg := newBufconnProxyConfig(t, &slowCacheEmptyServer{})
+	cfg.SlowCallThreshold = configtypes.Duration(20 * time.Millisecond)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	// Failed calls are logged too.
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "ns:secret"})
+	require.Error(t, err)
+	entries := slowCallLogs(t, buf.String())
+	require.Len(t, entries, 1)
+	require.Equal(t, "grpc", entries[0]["protocol"])
+	require.Equal(t, "ns", entries[0]["channel"])
+}
+
+func TestParseProxyLogLevel(t *testing.T) {
+	_, ok := parseProxyLogLevel("")
+	require.False(t, ok)
+	_, ok = parseProxyLogLevel("unknown")
+	require.False(t, ok)
+	for level, expected := range map[string]string{"none": "disabled", "DEBUG": "debug", "warn": "warn"} {
+		l, ok := parseProxyLogLevel(level)
+		require.True(t, ok, level)
+		require.Equal(t, expected, l.String())
+	}
+}
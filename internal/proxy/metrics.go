@@ -1,7 +1,19 @@
 package proxy
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var metricsNamespace = "centrifugo"
@@ -33,6 +45,24 @@ var (
 		Name:      "inflight_requests",
 		Help:      "Number of inflight proxy requests.",
 	}, []string{"protocol", "type", "name"})
+	proxyCacheEmptyResultCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "proxy",
+		Name:      "cache_empty_results",
+		Help:      "Number of cache empty results by source: proxy for results of successful proxy calls, stale for last good results returned upon proxy call error, no_cache for results returned without proxy call since backend asked to not call it for channel.",
+	}, []string{"name", "source"})
+	proxyCacheEmptyObservedResultCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "proxy",
+		Name:      "cache_empty_observed_results",
+		Help:      "Number of cache empty proxy results ignored in observe-only mode by result: populated, not_populated or error.",
+	}, []string{"name", "result"})
+	proxyCallErrorClassCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "proxy",
+		Name:      "call_errors",
+		Help:      "Number of failed proxy calls by protocol and error class: timeout, canceled, connection, transport or application.",
+	}, []string{"protocol", "class"})
 )
 
 func init() {
@@ -40,4 +70,83 @@ func init() {
 	prometheus.MustRegister(proxyCallDurationHistogram)
 	prometheus.MustRegister(proxyCallErrorCount)
 	prometheus.MustRegister(proxyCallInflightRequests)
+	prometheus.MustRegister(proxyCacheEmptyResultCount)
+	prometheus.MustRegister(proxyCacheEmptyObservedResultCount)
+	prometheus.MustRegister(proxyCallErrorClassCount)
+
+	callLatencyHistogram := newCallLatencyHistogram(DefaultCallLatencyBuckets)
+	prometheus.MustRegister(callLatencyHistogram)
+	proxyCallLatencyHistogram.Store(callLatencyHistogram)
+}
+
+// DefaultCallLatencyBuckets of proxy call latency histogram, tuned for sub-second realtime workloads.
+var DefaultCallLatencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// proxyCallLatencyHistogram tracks transport-level latency of proxy calls, may be replaced
+// with SetCallLatencyBuckets.
+var proxyCallLatencyHistogram atomic.Pointer[prometheus.HistogramVec]
+
+func newCallLatencyHistogram(buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "proxy",
+		Name:      "call_latency_seconds",
+		Buckets:   buckets,
+		Help:      "Histogram of transport-level latency of proxy call by protocol and outcome.",
+	}, []string{"protocol", "outcome"})
+}
+
+// SetCallLatencyBuckets replaces buckets of proxy call latency histogram. Should be called
+// on start before proxy calls are made – observations made before are dropped.
+func SetCallLatencyBuckets(buckets []float64) error {
+	if len(buckets) == 0 {
+		return errors.New("no buckets")
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return fmt.Errorf("buckets must be in increasing order, got %v", buckets)
+		}
+	}
+	h := newCallLatencyHistogram(buckets)
+	prometheus.Unregister(proxyCallLatencyHistogram.Load())
+	if err := prometheus.Register(h); err != nil {
+		return fmt.Errorf("error registering proxy call latency histogram: %w", err)
+	}
+	proxyCallLatencyHistogram.Store(h)
+	return nil
+}
+
+// callOutcome returns outcome label of proxy call.
+func callOutcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return "timeout"
+	}
+	return "error"
+}
+
+// observeCallLatency records latency of proxy call started at started. When ctx contains valid
+// span context trace ID is attached to observation as exemplar – so that slow calls may be
+// looked up in traces.
+func observeCallLatency(ctx context.Context, protocol string, started time.Time, err error) {
+	observer := proxyCallLatencyHistogram.Load().WithLabelValues(protocol, callOutcome(err))
+	latency := time.Since(started).Seconds()
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(latency, prometheus.Labels{"trace_id": sc.TraceID().String()})
+			return
+		}
+	}
+	observer.Observe(latency)
+}
+
+// observeCallError counts failed proxy call by error class.
+func observeCallError(protocol string, err error, statusToCodeTransforms configtypes.HttpStatusToCodeTransforms) {
+	if err == nil {
+		return
+	}
+	proxyCallErrorClassCount.WithLabelValues(protocol, string(ClassifyError(err, statusToCodeTransforms))).Inc()
 }
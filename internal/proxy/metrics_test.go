@@ -0,0 +1,186 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requireSingleLatencySeries asserts observations were only made with given labels.
+func requireSingleLatencySeries(t *testing.T, protocol string, outcome string) {
+	t.Helper()
+	h := proxyCallLatencyHistogram.Load()
+	require.Equal(t, 1, testutil.CollectAndCount(h, "centrifugo_proxy_call_latency_seconds"))
+	require.True(t, h.DeleteLabelValues(protocol, outcome), "no observations for %s/%s", protocol, outcome)
+	require.Equal(t, 0, testutil.CollectAndCount(h, "centrifugo_proxy_call_latency_seconds"))
+}
+
+func TestCallLatencyHistogramHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	defer server.Close()
+
+	for path, outcome := range map[string]string{"/ok": "ok", "/error": "error", "/slow": "timeout"} {
+		t.Run(outcome, func(t *testing.T) {
+			proxyCallLatencyHistogram.Load().Reset()
+			p, err := NewHTTPCacheEmptyProxy("test", Config{
+				Endpoint: configtypes.String(server.URL + path),
+				Timeout:  configtypes.Duration(50 * time.Millisecond),
+			})
+			require.NoError(t, err)
+			_, _ = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+			requireSingleLatencySeries(t, "http", outcome)
+		})
+	}
+}
+
+type cacheEmptyScriptedServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+}
+
+func (s *cacheEmptyScriptedServer) NotifyCacheEmpty(ctx context.Context, req *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	switch req.Channel {
+	case "error":
+		return nil, status.Error(codes.Internal, "boom")
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &proxyproto.NotifyCacheEmptyResponse{Result: &proxyproto.NotifyCacheEmptyResult{}}, nil
+}
+
+func TestCallLatencyHistogramGRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	proxyproto.RegisterCentrifugoProxyServer(server, &cacheEmptyScriptedServer{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	p, err := NewGRPCCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(listener.Addr().String()),
+		Timeout:  configtypes.Duration(50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+	// Establish connection first so that connecting is not accounted in timeout case.
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "ok"})
+	require.NoError(t, err)
+
+	for _, outcome := range []string{"ok", "error", "timeout"} {
+		t.Run(outcome, func(t *testing.T) {
+			channel := map[string]string{"ok": "ok", "error": "error", "timeout": "slow"}[outcome]
+			proxyCallLatencyHistogram.Load().Reset()
+			_, _ = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: channel})
+			requireSingleLatencySeries(t, "grpc", outcome)
+		})
+	}
+}
+
+func TestSetCallLatencyBuckets(t *testing.T) {
+	defer func() { require.NoError(t, SetCallLatencyBuckets(DefaultCallLatencyBuckets)) }()
+
+	require.Error(t, SetCallLatencyBuckets(nil))
+	require.Error(t, SetCallLatencyBuckets([]float64{0.1, 0.1}))
+	require.Error(t, SetCallLatencyBuckets([]float64{0.5, 0.1}))
+
+	old := proxyCallLatencyHistogram.Load()
+	require.NoError(t, SetCallLatencyBuckets([]float64{0.01, 0.1, 1}))
+	require.NotSame(t, old, proxyCallLatencyHistogram.Load())
+	observeCallLatency(context.Background(), "http", time.Now(), nil)
+	requireSingleLatencySeries(t, "http", "ok")
+}
+
+// latencyExemplars returns labels of exemplars attached to latency histogram buckets.
+func latencyExemplars(t *testing.T, protocol string, outcome string) []map[string]string {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, proxyCallLatencyHistogram.Load().WithLabelValues(protocol, outcome).(prometheus.Metric).Write(&m))
+	var exemplars []map[string]string
+	for _, b := range m.GetHistogram().GetBucket() {
+		if b.GetExemplar() == nil {
+			continue
+		}
+		labels := map[string]string{}
+		for _, l := range b.GetExemplar().GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		exemplars = append(exemplars, labels)
+	}
+	return exemplars
+}
+
+func TestCallLatencyHistogramExemplar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	defer server.Close()
+	cfg := Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	}
+
+	// No span context – no exemplar.
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.Empty(t, latencyExemplars(t, "http", "ok"))
+	requireSingleLatencySeries(t, "http", "ok")
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+	cfg.TracerProvider = tp
+	p, err = NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	exemplars := latencyExemplars(t, "http", "ok")
+	require.Len(t, exemplars, 1)
+	require.Equal(t, map[string]string{"trace_id": spans[0].SpanContext.TraceID().String()}, exemplars[0])
+	requireSingleLatencySeries(t, "http", "ok")
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCallOutcome(t *testing.T) {
+	require.Equal(t, "ok", callOutcome(nil))
+	require.Equal(t, "error", callOutcome(errors.New("boom")))
+	require.Equal(t, "error", callOutcome(status.Error(codes.Unavailable, "unavailable")))
+	require.Equal(t, "timeout", callOutcome(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+	require.Equal(t, "timeout", callOutcome(status.Error(codes.DeadlineExceeded, "deadline")))
+	require.Equal(t, "timeout", callOutcome(fmt.Errorf("HTTP request error: %w", timeoutError{})))
+}
@@ -1,14 +1,35 @@
 package proxy
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
 	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+
+	"github.com/rs/zerolog/log"
 )
 
 type Config = configtypes.Proxy
 
+// proxyCallContext returns context for proxy call with deadline which is the earliest of
+// incoming ctx deadline and proxy timeout (context.WithTimeout never extends parent deadline) –
+// so backend never gets more time than caller waits. GRPC passes the resulting deadline to
+// backend in grpc-timeout header, HTTP proxies use timeoutHeader.
+func proxyCallContext(ctx context.Context, p Config) (context.Context, context.CancelFunc) {
+	timeout := p.Timeout.ToDuration()
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// warnInsecureSkipVerify is called upon proxy creation so that disabled verification of backend
+// certificate (only meant for development with self-signed certificates) is never unnoticed.
+func warnInsecureSkipVerify(entity string) {
+	log.Warn().Str("entity", entity).Msg("INSECURE: TLS certificate verification of proxy backend is disabled by insecure_skip_verify option, connection is open to man-in-the-middle attacks – never use this in production")
+}
+
 func getEncoding(useBase64 bool) string {
 	if useBase64 {
 		return "binary"
@@ -24,7 +45,8 @@ func GetConnectProxy(name string, p Config) (ConnectProxy, error) {
 	for i, header := range p.HttpHeaders {
 		p.HttpHeaders[i] = strings.ToLower(header)
 	}
-	if isHttpEndpoint(p.Endpoint) {
+	p = withDefaultTimeout(p)
+	if isHttpEndpoint(string(p.Endpoint)) {
 		return NewHTTPConnectProxy(p)
 	}
 	return NewGRPCConnectProxy(name, p)
@@ -34,7 +56,8 @@ func GetRefreshProxy(name string, p Config) (RefreshProxy, error) {
 	for i, header := range p.HttpHeaders {
 		p.HttpHeaders[i] = strings.ToLower(header)
 	}
-	if isHttpEndpoint(p.Endpoint) {
+	p = withDefaultTimeout(p)
+	if isHttpEndpoint(string(p.Endpoint)) {
 		return NewHTTPRefreshProxy(p)
 	}
 	return NewGRPCRefreshProxy(name, p)
@@ -44,7 +67,8 @@ func GetRpcProxy(name string, p Config) (RPCProxy, error) {
 	for i, header := range p.HttpHeaders {
 		p.HttpHeaders[i] = strings.ToLower(header)
 	}
-	if isHttpEndpoint(p.Endpoint) {
+	p = withDefaultTimeout(p)
+	if isHttpEndpoint(string(p.Endpoint)) {
 		return NewHTTPRPCProxy(p)
 	}
 	return NewGRPCRPCProxy(name, p)
@@ -54,7 +78,8 @@ func GetSubRefreshProxy(name string, p Config) (SubRefreshProxy, error) {
 	for i, header := range p.HttpHeaders {
 		p.HttpHeaders[i] = strings.ToLower(header)
 	}
-	if isHttpEndpoint(p.Endpoint) {
+	p = withDefaultTimeout(p)
+	if isHttpEndpoint(string(p.Endpoint)) {
 		return NewHTTPSubRefreshProxy(p)
 	}
 	return NewGRPCSubRefreshProxy(name, p)
@@ -64,7 +89,8 @@ func GetPublishProxy(name string, p Config) (PublishProxy, error) {
 	for i, header := range p.HttpHeaders {
 		p.HttpHeaders[i] = strings.ToLower(header)
 	}
-	if isHttpEndpoint(p.Endpoint) {
+	p = withDefaultTimeout(p)
+	if isHttpEndpoint(string(p.Endpoint)) {
 		return NewHTTPPublishProxy(p)
 	}
 	return NewGRPCPublishProxy(name, p)
@@ -74,7 +100,8 @@ func GetSubscribeProxy(name string, p Config) (SubscribeProxy, error) {
 	for i, header := range p.HttpHeaders {
 		p.HttpHeaders[i] = strings.ToLower(header)
 	}
-	if isHttpEndpoint(p.Endpoint) {
+	p = withDefaultTimeout(p)
+	if isHttpEndpoint(string(p.Endpoint)) {
 		return NewHTTPSubscribeProxy(p)
 	}
 	return NewGRPCSubscribeProxy(name, p)
@@ -84,8 +111,9 @@ func GetCacheEmptyProxy(name string, p Config) (CacheEmptyProxy, error) {
 	for i, header := range p.HttpHeaders {
 		p.HttpHeaders[i] = strings.ToLower(header)
 	}
-	if isHttpEndpoint(p.Endpoint) {
-		return NewHTTPCacheEmptyProxy(p)
+	p = withDefaultTimeout(p)
+	if isHttpEndpoint(string(p.Endpoint)) {
+		return NewHTTPCacheEmptyProxy(name, p)
 	}
 	return NewGRPCCacheEmptyProxy(name, p)
 }
@@ -3,9 +3,14 @@ package proxy
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/centrifugal/centrifugo/v6/internal/build"
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
 	"github.com/centrifugal/centrifugo/v6/internal/middleware"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/metadata"
@@ -209,3 +214,66 @@ func TestRequestMetadata_StaticMetadataOverride(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPCallerUserAgent(t *testing.T) {
+	userAgents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		_, _ = w.Write([]byte(`{"result":{}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		userAgent     string
+		staticHeaders map[string]string
+		clientHeaders http.Header
+		expected      string
+	}{
+		{
+			name:     "default",
+			expected: "Centrifugo/" + build.Version,
+		},
+		{
+			name:      "override",
+			userAgent: "my-gateway-client/1.0",
+			expected:  "my-gateway-client/1.0",
+		},
+		{
+			name:          "static header has priority",
+			userAgent:     "my-gateway-client/1.0",
+			staticHeaders: map[string]string{"User-Agent": "static/1.0"},
+			expected:      "static/1.0",
+		},
+		{
+			name:          "proxied client header has priority",
+			userAgent:     "my-gateway-client/1.0",
+			clientHeaders: http.Header{"User-Agent": []string{"browser/1.0"}},
+			expected:      "browser/1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Endpoint: configtypes.String(server.URL),
+				Timeout:  configtypes.Duration(time.Second),
+			}
+			cfg.HTTP.UserAgent = tt.userAgent
+			cfg.HTTP.StaticHeaders = tt.staticHeaders
+			if tt.clientHeaders != nil {
+				cfg.HttpHeaders = []string{"user-agent"}
+			}
+			p, err := NewHTTPCacheEmptyProxy("test", cfg)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if tt.clientHeaders != nil {
+				ctx = middleware.SetHeadersToContext(ctx, tt.clientHeaders)
+			}
+			_, err = p.ProxyCacheEmpty(ctx, &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, <-userAgents)
+		})
+	}
+}
@@ -19,7 +19,7 @@ var _ PublishProxy = (*GRPCPublishProxy)(nil)
 
 // NewGRPCPublishProxy ...
 func NewGRPCPublishProxy(name string, p Config) (*GRPCPublishProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	host, err := getGrpcHost(string(p.Endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("error getting grpc host: %v", err)
 	}
@@ -58,3 +58,7 @@ func (p *GRPCPublishProxy) UseBase64() bool {
 func (p *GRPCPublishProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *GRPCPublishProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
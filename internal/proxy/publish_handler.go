@@ -64,7 +64,7 @@ func (h *PublishHandler) Handle(node *centrifuge.Node) PublishHandlerFunc {
 		proxyEnabled := chOpts.PublishProxyEnabled
 		proxyName := chOpts.PublishProxyName
 		if !proxyEnabled {
-			log.Info().Str("channel", e.Channel).Msg("publish proxy not enabled for a channel")
+			logChannel(log.Info(), nil, e.Channel).Msg("publish proxy not enabled for a channel")
 			return centrifuge.PublishReply{}, centrifuge.ErrorNotAvailable
 		}
 		p = h.config.Proxies[proxyName]
@@ -105,7 +105,7 @@ func (h *PublishHandler) Handle(node *centrifuge.Node) PublishHandlerFunc {
 			summary.Observe(duration)
 			histogram.Observe(duration)
 			errors.Inc()
-			log.Error().Err(err).Str("client", client.ID()).Str("channel", e.Channel).Msg("error proxying publish")
+			logChannel(log.Error().Err(err).Str("client", client.ID()), p, e.Channel).Msg("error proxying publish")
 			return centrifuge.PublishReply{}, err
 		}
 		summary.Observe(duration)
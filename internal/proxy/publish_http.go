@@ -35,7 +35,7 @@ func NewHTTPPublishProxy(p Config) (*HTTPPublishProxy, error) {
 		return nil, fmt.Errorf("error creating HTTP client: %w", err)
 	}
 	return &HTTPPublishProxy{
-		httpCaller: NewHTTPCaller(httpClient),
+		httpCaller: NewHTTPCaller(httpClient, p.HTTP.MaxResponseSize.Bytes()),
 		config:     p,
 	}, nil
 }
@@ -46,7 +46,7 @@ func (p *HTTPPublishProxy) ProxyPublish(ctx context.Context, req *proxyproto.Pub
 	if err != nil {
 		return nil, err
 	}
-	respData, err := p.httpCaller.CallHTTP(ctx, p.config.Endpoint, httpRequestHeaders(ctx, p.config), data)
+	respData, err := p.httpCaller.CallHTTP(ctx, string(p.config.Endpoint), httpRequestHeaders(ctx, p.config), data)
 	if err != nil {
 		return transformPublishResponse(err, p.config.HTTP.StatusToCodeTransforms)
 	}
@@ -67,3 +67,7 @@ func (p *HTTPPublishProxy) UseBase64() bool {
 func (p *HTTPPublishProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *HTTPPublishProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
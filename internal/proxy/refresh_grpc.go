@@ -19,7 +19,7 @@ var _ RefreshProxy = (*GRPCRefreshProxy)(nil)
 
 // NewGRPCRefreshProxy ...
 func NewGRPCRefreshProxy(name string, p Config) (*GRPCRefreshProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	host, err := getGrpcHost(string(p.Endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("error getting grpc host: %v", err)
 	}
@@ -23,7 +23,7 @@ func NewHTTPRefreshProxy(p Config) (*HTTPRefreshProxy, error) {
 	}
 	return &HTTPRefreshProxy{
 		config:     p,
-		httpCaller: NewHTTPCaller(httpClient),
+		httpCaller: NewHTTPCaller(httpClient, p.HTTP.MaxResponseSize.Bytes()),
 	}, nil
 }
 
@@ -33,7 +33,7 @@ func (p *HTTPRefreshProxy) ProxyRefresh(ctx context.Context, req *proxyproto.Ref
 	if err != nil {
 		return nil, err
 	}
-	respData, err := p.httpCaller.CallHTTP(ctx, p.config.Endpoint, httpRequestHeaders(ctx, p.config), data)
+	respData, err := p.httpCaller.CallHTTP(ctx, string(p.config.Endpoint), httpRequestHeaders(ctx, p.config), data)
 	if err != nil {
 		return transformRefreshResponse(err, p.config.HTTP.StatusToCodeTransforms)
 	}
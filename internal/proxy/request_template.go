@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+)
+
+// errInvalidTemplateJSON returned when request template produced invalid JSON.
+var errInvalidTemplateJSON = errors.New("request template produced invalid JSON")
+
+// templateProxyEncoder encodes cache empty requests with RequestTemplate, other requests are
+// encoded as JSON.
+type templateProxyEncoder struct {
+	jsonProxyEncoder
+	template *template.Template
+}
+
+var _ ProxyEncoder = (*templateProxyEncoder)(nil)
+
+// newTemplateProxyEncoder parses request template and executes it for a sample request, so
+// that references to unknown fields and templates producing invalid JSON fail on start.
+func newTemplateProxyEncoder(text string) (*templateProxyEncoder, error) {
+	t, err := template.New("request").Funcs(template.FuncMap{"json": templateJSON}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request template: %w", err)
+	}
+	e := &templateProxyEncoder{template: t}
+	if _, err := e.EncodeNotifyCacheEmptyRequest(&proxyproto.NotifyCacheEmptyRequest{
+		Channel: "channel",
+		Client:  "client",
+		User:    "user",
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *templateProxyEncoder) EncodeNotifyCacheEmptyRequest(req *proxyproto.NotifyCacheEmptyRequest) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := e.template.Execute(&buf, req); err != nil {
+		return nil, fmt.Errorf("error executing request template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, errInvalidTemplateJSON
+	}
+	return buf.Bytes(), nil
+}
+
+// templateJSON is a template function which encodes value as JSON – so that strings are
+// properly quoted and escaped.
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
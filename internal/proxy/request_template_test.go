@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEmptyProxyHTTPRequestTemplate(t *testing.T) {
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		bodies <- string(body)
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	}
+	cfg.HTTP.RequestTemplate = `{"event":"cache_empty","data":{"ch":{{json .Channel}}{{if .User}},"user":{{json .User}}{{end}}}}`
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+
+	resp, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: `ns:"quoted"`})
+	require.NoError(t, err)
+	require.True(t, resp.Result.Populated)
+	require.JSONEq(t, `{"event":"cache_empty","data":{"ch":"ns:\"quoted\""}}`, <-bodies)
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "ns:chan", User: "42"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"event":"cache_empty","data":{"ch":"ns:chan","user":"42"}}`, <-bodies)
+}
+
+func TestCacheEmptyProxyHTTPInvalidRequestTemplate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		template string
+		encoding string
+		err      string
+	}{
+		"syntax":        {template: `{"ch":{{json .Channel}`, err: "error parsing request template"},
+		"unknown field": {template: `{"ch":{{json .Chan}}}`, err: "error executing request template"},
+		"invalid JSON":  {template: `{"ch":{{.Channel}}}`, err: errInvalidTemplateJSON.Error()},
+		"encoding":      {template: `{"ch":{{json .Channel}}}`, encoding: configtypes.ProxyEncodingProtobuf, err: "not supported with protobuf encoding"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{
+				Endpoint: "http://localhost:3000",
+				Timeout:  configtypes.Duration(time.Second),
+			}
+			cfg.HTTP.RequestTemplate = tc.template
+			cfg.HTTP.Encoding = tc.encoding
+			_, err := NewHTTPCacheEmptyProxy("test", cfg)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+)
+
+const (
+	defaultRetryBackoff  = 100 * time.Millisecond
+	defaultRetryMaxDelay = 5 * time.Second
+)
+
+// retryCall calls fn until it succeeds or returns error which is not retryable, up to MaxRetries
+// times after the first attempt. Delay between attempts starts from RetryBackoff and doubles,
+// delay suggested by backend with Retry-After header takes precedence. Delay is capped with
+// RetryMaxDelay.
+// RetryBudget wraps all attempts into one deadline – once it does not leave time for the next
+// attempt the last error is returned immediately.
+func retryCall[T any](ctx context.Context, p Config, retryable func(error) bool, fn func(context.Context) (T, error)) (T, error) {
+	if p.MaxRetries <= 0 {
+		return fn(ctx)
+	}
+	if budget := p.RetryBudget.ToDuration(); budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, time.Now().Add(budget))
+		defer cancel()
+	}
+	backoff := p.RetryBackoff.ToDuration()
+	if backoff <= 0 {
+		backoff = CurrentDefaults().RetryBackoff
+	}
+	maxDelay := p.RetryMaxDelay.ToDuration()
+	if maxDelay <= 0 {
+		maxDelay = CurrentDefaults().RetryMaxDelay
+	}
+	for attempt := 0; ; attempt++ {
+		result, err := fn(ctx)
+		if err == nil || attempt >= p.MaxRetries || !retryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+		delay := retryDelay(backoff, maxDelay, attempt)
+		if retryAfter, ok := RetryAfterFromError(err); ok {
+			delay = min(retryAfter, maxDelay)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return result, err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+func retryDelay(backoff time.Duration, maxDelay time.Duration, attempt int) time.Duration {
+	delay := backoff
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// RetryAfterFromError returns delay suggested by HTTP backend with Retry-After header of
+// response which resulted into err.
+func RetryAfterFromError(err error) (time.Duration, bool) {
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) && statusErr.hasRetryAfter {
+		return statusErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses Retry-After header value in delay-seconds or HTTP-date form. Date in
+// the past results into zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(min(seconds, int64(math.MaxInt64/time.Second))) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// isRetryableError returns retry predicate which allows retrying errors of retryable classes,
+// see ClassifyError.
+func isRetryableError(statusToCodeTransforms configtypes.HttpStatusToCodeTransforms) func(error) bool {
+	return func(err error) bool {
+		return ClassifyError(err, statusToCodeTransforms).Retryable()
+	}
+}
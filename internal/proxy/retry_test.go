@@ -0,0 +1,267 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errRetryTest = errors.New("test error")
+
+func TestRetryCall(t *testing.T) {
+	p := Config{MaxRetries: 3, RetryBackoff: configtypes.Duration(time.Millisecond)}
+	var attempts int
+	result, err := retryCall(context.Background(), p, func(error) bool { return true }, func(context.Context) (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, errRetryTest
+		}
+		return attempts, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+
+	attempts = 0
+	_, err = retryCall(context.Background(), p, func(error) bool { return true }, func(context.Context) (int, error) {
+		attempts++
+		return 0, errRetryTest
+	})
+	require.ErrorIs(t, err, errRetryTest)
+	require.Equal(t, 4, attempts)
+
+	attempts = 0
+	_, err = retryCall(context.Background(), p, func(error) bool { return false }, func(context.Context) (int, error) {
+		attempts++
+		return 0, errRetryTest
+	})
+	require.ErrorIs(t, err, errRetryTest)
+	require.Equal(t, 1, attempts)
+}
+
+func TestRetryCallBudgetExhaustedBeforeBackoff(t *testing.T) {
+	p := Config{
+		MaxRetries:   5,
+		RetryBackoff: configtypes.Duration(time.Second),
+		RetryBudget:  configtypes.Duration(100 * time.Millisecond),
+	}
+	var attempts int
+	started := time.Now()
+	_, err := retryCall(context.Background(), p, func(error) bool { return true }, func(context.Context) (int, error) {
+		attempts++
+		return 0, errRetryTest
+	})
+	// Backoff does not fit into budget, so the last error is returned without waiting.
+	require.ErrorIs(t, err, errRetryTest)
+	require.Equal(t, 1, attempts)
+	require.Less(t, time.Since(started), 100*time.Millisecond)
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, 100*time.Millisecond, retryDelay(100*time.Millisecond, defaultRetryMaxDelay, 0))
+	require.Equal(t, 400*time.Millisecond, retryDelay(100*time.Millisecond, defaultRetryMaxDelay, 2))
+	require.Equal(t, defaultRetryMaxDelay, retryDelay(100*time.Millisecond, defaultRetryMaxDelay, 100))
+	require.Equal(t, time.Second, retryDelay(100*time.Millisecond, time.Second, 100))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "2", expected: 2 * time.Second, ok: true},
+		{value: " 0 ", expected: 0, ok: true},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), expected: 30 * time.Second, ok: true},
+		// Date in the past means retry immediately.
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0, ok: true},
+		{value: ""},
+		{value: "-1"},
+		{value: "soon"},
+	}
+	for _, tc := range testCases {
+		d, ok := parseRetryAfter(tc.value, now)
+		require.Equal(t, tc.ok, ok, tc.value)
+		require.Equal(t, tc.expected, d, tc.value)
+	}
+}
+
+func newRetryAfterServer(t *testing.T, status int, retryAfter string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func TestCacheEmptyProxyHTTPRetryAfter(t *testing.T) {
+	server, attempts := newRetryAfterServer(t, http.StatusTooManyRequests, "1")
+	cfg := Config{
+		Endpoint:     configtypes.String(server.URL),
+		Timeout:      configtypes.Duration(time.Second),
+		MaxRetries:   1,
+		RetryBackoff: configtypes.Duration(time.Millisecond),
+	}
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+
+	started := time.Now()
+	resp, err := p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.True(t, resp.GetResult().GetPopulated())
+	require.Equal(t, int64(2), attempts.Load())
+	require.GreaterOrEqual(t, time.Since(started), time.Second)
+}
+
+func TestCacheEmptyProxyHTTPRetryAfterClamped(t *testing.T) {
+	server, attempts := newRetryAfterServer(t, http.StatusServiceUnavailable, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	cfg := Config{
+		Endpoint:      configtypes.String(server.URL),
+		Timeout:       configtypes.Duration(time.Second),
+		MaxRetries:    1,
+		RetryBackoff:  configtypes.Duration(time.Millisecond),
+		RetryMaxDelay: configtypes.Duration(50 * time.Millisecond),
+	}
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+
+	started := time.Now()
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), attempts.Load())
+	require.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+	require.Less(t, time.Since(started), time.Second)
+}
+
+func TestCacheEmptyProxyHTTPRetryAfterNoRetries(t *testing.T) {
+	for name, retryAfter := range map[string]string{
+		"seconds": "2",
+		"date":    time.Now().Add(time.Minute).UTC().Format(http.TimeFormat),
+	} {
+		t.Run(name, func(t *testing.T) {
+			server, attempts := newRetryAfterServer(t, http.StatusServiceUnavailable, retryAfter)
+			p, err := NewHTTPCacheEmptyProxy("test", Config{
+				Endpoint: configtypes.String(server.URL),
+				Timeout:  configtypes.Duration(time.Second),
+			})
+			require.NoError(t, err)
+			_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+			require.Error(t, err)
+			require.Equal(t, int64(1), attempts.Load())
+			delay, ok := RetryAfterFromError(err)
+			require.True(t, ok)
+			if name == "seconds" {
+				require.Equal(t, 2*time.Second, delay)
+				require.ErrorContains(t, err, "retry after 2s")
+			} else {
+				// HTTP-date has seconds precision.
+				require.InDelta(t, time.Minute, delay, float64(time.Second))
+			}
+		})
+	}
+}
+
+func TestRetryAfterFromErrorNotStatus(t *testing.T) {
+	_, ok := RetryAfterFromError(errRetryTest)
+	require.False(t, ok)
+	_, ok = RetryAfterFromError(&statusCodeError{Code: http.StatusServiceUnavailable})
+	require.False(t, ok)
+}
+
+func TestCacheEmptyProxyHTTPRetryBudget(t *testing.T) {
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		time.Sleep(80 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Endpoint:     configtypes.String(server.URL),
+		Timeout:      configtypes.Duration(time.Second),
+		MaxRetries:   10,
+		RetryBackoff: configtypes.Duration(10 * time.Millisecond),
+		RetryBudget:  configtypes.Duration(250 * time.Millisecond),
+	}
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+
+	started := time.Now()
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Error(t, err)
+	require.Less(t, time.Since(started), 500*time.Millisecond)
+	require.GreaterOrEqual(t, attempts.Load(), int64(2))
+	require.Less(t, attempts.Load(), int64(cfg.MaxRetries+1))
+}
+
+func TestCacheEmptyProxyHTTPNoRetryOnClientError(t *testing.T) {
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Endpoint:     configtypes.String(server.URL),
+		Timeout:      configtypes.Duration(time.Second),
+		MaxRetries:   3,
+		RetryBackoff: configtypes.Duration(time.Millisecond),
+	}
+	p, err := NewHTTPCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Error(t, err)
+	require.Equal(t, int64(1), attempts.Load())
+}
+
+type slowCacheEmptyServer struct {
+	proxyproto.UnimplementedCentrifugoProxyServer
+	calls atomic.Int64
+}
+
+func (s *slowCacheEmptyServer) NotifyCacheEmpty(ctx context.Context, _ *proxyproto.NotifyCacheEmptyRequest) (*proxyproto.NotifyCacheEmptyResponse, error) {
+	s.calls.Add(1)
+	select {
+	case <-time.After(80 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	return nil, status.Error(codes.Unavailable, "overloaded")
+}
+
+func TestGRPCCacheEmptyProxyRetryBudget(t *testing.T) {
+	srv := &slowCacheEmptyServer{}
+	cfg := newBufconnProxyConfig(t, srv)
+	cfg.MaxRetries = 10
+	cfg.RetryBackoff = configtypes.Duration(10 * time.Millisecond)
+	cfg.RetryBudget = configtypes.Duration(250 * time.Millisecond)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	started := time.Now()
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.Error(t, err)
+	require.Less(t, time.Since(started), 500*time.Millisecond)
+	require.GreaterOrEqual(t, srv.calls.Load(), int64(2))
+	require.Less(t, srv.calls.Load(), int64(cfg.MaxRetries+1))
+}
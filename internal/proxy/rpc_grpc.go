@@ -19,7 +19,7 @@ var _ RPCProxy = (*GRPCRPCProxy)(nil)
 
 // NewGRPCRPCProxy ...
 func NewGRPCRPCProxy(name string, p Config) (*GRPCRPCProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	host, err := getGrpcHost(string(p.Endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("error getting grpc host: %v", err)
 	}
@@ -23,7 +23,7 @@ func NewHTTPRPCProxy(p Config) (*HTTPRPCProxy, error) {
 	}
 	return &HTTPRPCProxy{
 		config:     p,
-		httpCaller: NewHTTPCaller(httpClient),
+		httpCaller: NewHTTPCaller(httpClient, p.HTTP.MaxResponseSize.Bytes()),
 	}, nil
 }
 
@@ -33,7 +33,7 @@ func (p *HTTPRPCProxy) ProxyRPC(ctx context.Context, req *proxyproto.RPCRequest)
 	if err != nil {
 		return nil, err
 	}
-	respData, err := p.httpCaller.CallHTTP(ctx, p.config.Endpoint, httpRequestHeaders(ctx, p.config), data)
+	respData, err := p.httpCaller.CallHTTP(ctx, string(p.config.Endpoint), httpRequestHeaders(ctx, p.config), data)
 	if err != nil {
 		return transformRPCResponse(err, p.config.HTTP.StatusToCodeTransforms)
 	}
@@ -19,7 +19,7 @@ var _ SubRefreshProxy = (*GRPCSubRefreshProxy)(nil)
 
 // NewGRPCSubRefreshProxy ...
 func NewGRPCSubRefreshProxy(name string, p Config) (*GRPCSubRefreshProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	host, err := getGrpcHost(string(p.Endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("error getting grpc host: %v", err)
 	}
@@ -58,3 +58,7 @@ func (p *GRPCSubRefreshProxy) UseBase64() bool {
 func (p *GRPCSubRefreshProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *GRPCSubRefreshProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
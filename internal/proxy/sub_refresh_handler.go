@@ -67,7 +67,7 @@ func (h *SubRefreshHandler) Handle() SubRefreshHandlerFunc {
 		proxyEnabled := chOpts.SubRefreshProxyEnabled
 		proxyName := chOpts.SubRefreshProxyName
 		if !proxyEnabled {
-			log.Info().Str("channel", e.Channel).Msg("sub refresh proxy not configured for a channel")
+			logChannel(log.Info(), nil, e.Channel).Msg("sub refresh proxy not configured for a channel")
 			return centrifuge.SubRefreshReply{}, SubRefreshExtra{}, centrifuge.ErrorNotAvailable
 		}
 		p = h.config.Proxies[proxyName]
@@ -102,7 +102,7 @@ func (h *SubRefreshHandler) Handle() SubRefreshHandlerFunc {
 			summary.Observe(duration)
 			histogram.Observe(duration)
 			errors.Inc()
-			log.Error().Err(err).Str("client", client.ID()).Str("channel", e.Channel).Msg("error proxying sub refresh")
+			logChannel(log.Error().Err(err).Str("client", client.ID()), p, e.Channel).Msg("error proxying sub refresh")
 			// In case of an error give connection one more minute to live and
 			// then try to check again. This way we gracefully handle temporary
 			// problems on application backend side.
@@ -23,7 +23,7 @@ func NewHTTPSubRefreshProxy(p Config) (*HTTPSubRefreshProxy, error) {
 	}
 	return &HTTPSubRefreshProxy{
 		config:     p,
-		httpCaller: NewHTTPCaller(httpClient),
+		httpCaller: NewHTTPCaller(httpClient, p.HTTP.MaxResponseSize.Bytes()),
 	}, nil
 }
 
@@ -33,7 +33,7 @@ func (p *HTTPSubRefreshProxy) ProxySubRefresh(ctx context.Context, req *proxypro
 	if err != nil {
 		return nil, err
 	}
-	respData, err := p.httpCaller.CallHTTP(ctx, p.config.Endpoint, httpRequestHeaders(ctx, p.config), data)
+	respData, err := p.httpCaller.CallHTTP(ctx, string(p.config.Endpoint), httpRequestHeaders(ctx, p.config), data)
 	if err != nil {
 		return transformSubRefreshResponse(err, p.config.HTTP.StatusToCodeTransforms)
 	}
@@ -54,3 +54,7 @@ func (p *HTTPSubRefreshProxy) UseBase64() bool {
 func (p *HTTPSubRefreshProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *HTTPSubRefreshProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
@@ -19,7 +19,7 @@ var _ SubscribeProxy = (*GRPCSubscribeProxy)(nil)
 
 // NewGRPCSubscribeProxy ...
 func NewGRPCSubscribeProxy(name string, p Config) (*GRPCSubscribeProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	host, err := getGrpcHost(string(p.Endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("error getting grpc host: %v", err)
 	}
@@ -58,3 +58,7 @@ func (p *GRPCSubscribeProxy) UseBase64() bool {
 func (p *GRPCSubscribeProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *GRPCSubscribeProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
@@ -68,7 +68,7 @@ func (h *SubscribeHandler) Handle() SubscribeHandlerFunc {
 		proxyEnabled := chOpts.SubscribeProxyEnabled
 		proxyName := chOpts.SubscribeProxyName
 		if !proxyEnabled {
-			log.Info().Str("channel", e.Channel).Msg("subscribe proxy not enabled for a channel")
+			logChannel(log.Info(), nil, e.Channel).Msg("subscribe proxy not enabled for a channel")
 			return centrifuge.SubscribeReply{}, SubscribeExtra{}, centrifuge.ErrorNotAvailable
 		}
 		p = h.config.Proxies[proxyName]
@@ -109,7 +109,7 @@ func (h *SubscribeHandler) Handle() SubscribeHandlerFunc {
 			summary.Observe(duration)
 			histogram.Observe(duration)
 			errors.Inc()
-			log.Error().Err(err).Str("client", client.ID()).Str("channel", e.Channel).Msg("error proxying subscribe")
+			logChannel(log.Error().Err(err).Str("client", client.ID()), p, e.Channel).Msg("error proxying subscribe")
 			return centrifuge.SubscribeReply{}, SubscribeExtra{}, err
 		}
 		summary.Observe(duration)
@@ -23,7 +23,7 @@ func NewHTTPSubscribeProxy(p Config) (*HTTPSubscribeProxy, error) {
 	}
 	return &HTTPSubscribeProxy{
 		config:     p,
-		httpCaller: NewHTTPCaller(httpClient),
+		httpCaller: NewHTTPCaller(httpClient, p.HTTP.MaxResponseSize.Bytes()),
 	}, nil
 }
 
@@ -33,7 +33,7 @@ func (p *HTTPSubscribeProxy) ProxySubscribe(ctx context.Context, req *proxyproto
 	if err != nil {
 		return nil, err
 	}
-	respData, err := p.httpCaller.CallHTTP(ctx, p.config.Endpoint, httpRequestHeaders(ctx, p.config), data)
+	respData, err := p.httpCaller.CallHTTP(ctx, string(p.config.Endpoint), httpRequestHeaders(ctx, p.config), data)
 	if err != nil {
 		return transformSubscribeResponse(err, p.config.HTTP.StatusToCodeTransforms)
 	}
@@ -54,3 +54,7 @@ func (p *HTTPSubscribeProxy) UseBase64() bool {
 func (p *HTTPSubscribeProxy) IncludeMeta() bool {
 	return p.config.IncludeConnectionMeta
 }
+
+func (p *HTTPSubscribeProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
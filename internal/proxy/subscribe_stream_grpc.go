@@ -15,7 +15,7 @@ type SubscribeStreamProxy struct {
 }
 
 func NewSubscribeStreamProxy(name string, p Config) (*SubscribeStreamProxy, error) {
-	host, err := getGrpcHost(p.Endpoint)
+	host, err := getGrpcHost(string(p.Endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("error getting grpc host: %v", err)
 	}
@@ -43,3 +43,7 @@ func (p *SubscribeStreamProxy) SubscribeUnidirectional(ctx context.Context, req
 func (p *SubscribeStreamProxy) SubscribeBidirectional(ctx context.Context) (proxyproto.CentrifugoProxy_SubscribeBidirectionalClient, error) {
 	return p.client.SubscribeBidirectional(grpcRequestContext(ctx, p.config))
 }
+
+func (p *SubscribeStreamProxy) channelLabelMode() string {
+	return p.config.ChannelLabelMode
+}
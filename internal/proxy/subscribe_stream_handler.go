@@ -79,7 +79,7 @@ func (h *SubscribeStreamHandler) Handle() SubscribeStreamHandlerFunc {
 		proxyEnabled := chOpts.SubscribeStreamProxyEnabled
 		proxyName := chOpts.SubscribeStreamProxyName
 		if !proxyEnabled {
-			log.Info().Str("channel", e.Channel).Msg("subscribe stream proxy not enabled for a channel")
+			logChannel(log.Info(), nil, e.Channel).Msg("subscribe stream proxy not enabled for a channel")
 			return centrifuge.SubscribeReply{}, nil, nil, centrifuge.ErrorNotAvailable
 		}
 		p = h.config.Proxies[proxyName]
@@ -162,7 +162,7 @@ func (h *SubscribeStreamHandler) Handle() SubscribeStreamHandlerFunc {
 			summary.Observe(duration)
 			histogram.Observe(duration)
 			errCounter.Inc()
-			log.Error().Err(err).Str("client", client.ID()).Str("channel", e.Channel).Msg("error from subscribe stream proxy")
+			logChannel(log.Error().Err(err).Str("client", client.ID()), p, e.Channel).Msg("error from subscribe stream proxy")
 			//proxyCallErrorCount.WithLabelValues(proxyName, "subscribe", "internal").Inc()
 			return centrifuge.SubscribeReply{}, nil, nil, err
 		}
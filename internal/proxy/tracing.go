@@ -0,0 +1,97 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/centrifugal/centrifugo/v6/internal/tools"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const proxyTracerName = "github.com/centrifugal/centrifugo/v6/internal/proxy"
+
+// traceContextPropagator injects W3C traceparent into outgoing proxy requests.
+var traceContextPropagator = propagation.TraceContext{}
+
+// startProxySpan starts client span for proxy call. If proxy config has no tracer
+// provider then non-recording span from ctx is returned and nothing is allocated.
+func startProxySpan(ctx context.Context, p Config, name string, protocol string, method string, channel string) (context.Context, trace.Span) {
+	if p.TracerProvider == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("centrifugo.proxy.name", name),
+		attribute.String("centrifugo.proxy.protocol", protocol),
+		attribute.String("centrifugo.proxy.endpoint", tools.RedactedLogURLs(string(p.Endpoint))[0]),
+	}
+	if p.TraceChannel && channel != "" {
+		attrs = append(attrs, attribute.String("centrifugo.channel", channel))
+	}
+	return p.TracerProvider.Tracer(proxyTracerName).Start(
+		ctx, "proxy."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endProxySpan records call status and ends span.
+func endProxySpan(span trace.Span, err error) {
+	if !span.IsRecording() {
+		return
+	}
+	if err != nil {
+		span.SetAttributes(attribute.String("centrifugo.proxy.status", "error"))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.String("centrifugo.proxy.status", "ok"))
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+}
+
+// injectHTTPTraceContext sets traceparent header when ctx contains recording span.
+func injectHTTPTraceContext(ctx context.Context, header http.Header) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+	traceContextPropagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// injectGRPCTraceContext adds traceparent to outgoing GRPC metadata when ctx contains
+// recording span.
+func injectGRPCTraceContext(ctx context.Context) context.Context {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	traceContextPropagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+type metadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = metadataCarrier{}
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
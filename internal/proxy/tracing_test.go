@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]string {
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func TestCacheEmptyProxyHTTPTracing(t *testing.T) {
+	traceParents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents <- r.Header.Get("Traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&proxyproto.NotifyCacheEmptyResponse{
+			Result: &proxyproto.NotifyCacheEmptyResult{Populated: true},
+		})
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint:       configtypes.String(server.URL),
+		Timeout:        configtypes.Duration(time.Second),
+		TracerProvider: tp,
+	})
+	require.NoError(t, err)
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test:channel"})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "proxy.cache_empty", span.Name)
+	require.Equal(t, trace.SpanKindClient, span.SpanKind)
+	require.Equal(t, codes.Ok, span.Status.Code)
+	attrs := spanAttributes(span)
+	require.Equal(t, "test", attrs["centrifugo.proxy.name"])
+	require.Equal(t, "http", attrs["centrifugo.proxy.protocol"])
+	require.Equal(t, server.URL, attrs["centrifugo.proxy.endpoint"])
+	require.Equal(t, "ok", attrs["centrifugo.proxy.status"])
+	_, hasChannel := attrs["centrifugo.channel"]
+	require.False(t, hasChannel, "channel must be omitted by default")
+
+	traceParent := <-traceParents
+	require.NotEmpty(t, traceParent)
+	require.Contains(t, traceParent, span.SpanContext.TraceID().String())
+	require.Contains(t, traceParent, span.SpanContext.SpanID().String())
+}
+
+func TestCacheEmptyProxyHTTPTracingError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint:       configtypes.String(server.URL),
+		Timeout:        configtypes.Duration(time.Second),
+		TracerProvider: tp,
+		ProxyCommon: configtypes.ProxyCommon{
+			TraceChannel: true,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test:channel"})
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+	attrs := spanAttributes(spans[0])
+	require.Equal(t, "error", attrs["centrifugo.proxy.status"])
+	require.Equal(t, "test:channel", attrs["centrifugo.channel"])
+}
+
+func TestCacheEmptyProxyHTTPNoTracing(t *testing.T) {
+	traceParents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents <- r.Header.Get("Traceparent")
+		_, _ = w.Write([]byte(`{"result":{}}`))
+	}))
+	defer server.Close()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test:channel"})
+	require.NoError(t, err)
+	require.Empty(t, <-traceParents)
+}
+
+func TestInjectGRPCTraceContext(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", "secret"))
+	ctx, span := tp.Tracer("test").Start(ctx, "test")
+	defer span.End()
+
+	ctx = injectGRPCTraceContext(ctx)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, []string{"secret"}, md.Get("authorization"))
+	require.Len(t, md.Get("traceparent"), 1)
+	require.Contains(t, md.Get("traceparent")[0], span.SpanContext().TraceID().String())
+}
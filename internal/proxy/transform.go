@@ -1,6 +1,8 @@
 package proxy
 
 import (
+	"fmt"
+
 	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
 	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
 )
@@ -71,7 +73,41 @@ func transformRefreshResponse(err error, statusToCodeTransforms configtypes.Http
 	return nil, err
 }
 
+// TransformedError is returned by cache empty proxy when backend status matched one of
+// StatusToCodeTransforms (HTTP or GRPC). Since NotifyCacheEmptyResponse can't carry error,
+// transformed protocol error or disconnect is passed with error. Original error is kept for
+// logging and remains available with errors.As.
+type TransformedError struct {
+	ProtocolError      *proxyproto.Error
+	ProtocolDisconnect *proxyproto.Disconnect
+	// Err is original error with backend status.
+	Err error
+}
+
+func (e *TransformedError) Error() string {
+	if e.ProtocolError != nil {
+		return fmt.Sprintf("proxy error %d (%s): %v", e.ProtocolError.Code, e.ProtocolError.Message, e.Err)
+	}
+	return fmt.Sprintf("proxy disconnect %d (%s): %v", e.ProtocolDisconnect.Code, e.ProtocolDisconnect.Reason, e.Err)
+}
+
+func (e *TransformedError) Unwrap() error {
+	return e.Err
+}
+
+func newTransformedError(err error, protocolError *proxyproto.Error, protocolDisconnect *proxyproto.Disconnect) error {
+	if protocolError == nil && protocolDisconnect == nil {
+		return err
+	}
+	return &TransformedError{ProtocolError: protocolError, ProtocolDisconnect: protocolDisconnect, Err: err}
+}
+
 func transformCacheEmptyResponse(err error, statusToCodeTransforms configtypes.HttpStatusToCodeTransforms) (*proxyproto.NotifyCacheEmptyResponse, error) {
-	// Cache empty proxy does not use error/disconnect transforms, just return error as is.
-	return nil, err
+	protocolError, protocolDisconnect := transformHTTPStatusError(err, statusToCodeTransforms)
+	return nil, newTransformedError(err, protocolError, protocolDisconnect)
+}
+
+func transformCacheEmptyGRPCError(err error, statusToCodeTransforms configtypes.GrpcStatusToCodeTransforms) error {
+	protocolError, protocolDisconnect := transformGRPCStatusError(err, statusToCodeTransforms)
+	return newTransformedError(err, protocolError, protocolDisconnect)
 }
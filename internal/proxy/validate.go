@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+)
+
+// validateProxyChannel is a channel of probe calls made by ValidateProxies. Backend receives
+// cache empty notification for it, so it should answer without populating anything.
+const validateProxyChannel = "_centrifugo_validate_proxy"
+
+// ValidateProxies makes a probe call to every proxy concurrently and waits until all of them
+// are done or ctx is done – so that misconfigured proxies (wrong endpoint, TLS mismatch, backend
+// not implementing protocol) are found on start rather than upon the first client event. Any
+// response is considered a success. Returns error listing all failed proxies sorted by name.
+func ValidateProxies(ctx context.Context, proxies map[string]CacheEmptyProxy) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = map[string]error{}
+	)
+	for name, p := range proxies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.ProxyCacheEmpty(ctx, &proxyproto.NotifyCacheEmptyRequest{Channel: validateProxyChannel})
+			if err != nil {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	joined := make([]error, 0, len(names))
+	for _, name := range names {
+		joined = append(joined, fmt.Errorf("proxy %s: %w", name, errs[name]))
+	}
+	return fmt.Errorf("%d of %d proxies failed validation: %w", len(errs), len(proxies), errors.Join(joined...))
+}
@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProxies(t *testing.T) {
+	var paths sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths.Store(r.URL.Path, struct{}{})
+		_, _ = w.Write([]byte(`{"result":{}}`))
+	}))
+	defer server.Close()
+	healthy, err := NewHTTPCacheEmptyProxy("healthy", Config{
+		Endpoint: configtypes.String(server.URL + "/{channel}"),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	// Reserve a port and close listener, so that connection to it is refused.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	unreachable, err := NewHTTPCacheEmptyProxy("unreachable", Config{
+		Endpoint: configtypes.String("http://" + addr),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ValidateProxies(context.Background(), map[string]CacheEmptyProxy{"healthy": healthy}))
+	_, ok := paths.Load("/" + validateProxyChannel)
+	require.True(t, ok)
+
+	err = ValidateProxies(context.Background(), map[string]CacheEmptyProxy{
+		"healthy":     healthy,
+		"unreachable": unreachable,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "1 of 2 proxies failed validation")
+	require.Contains(t, err.Error(), "proxy unreachable: ")
+	require.NotContains(t, err.Error(), "proxy healthy")
+	require.Equal(t, ErrorClassConnection, ClassifyError(err, nil))
+}
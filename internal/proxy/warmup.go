@@ -0,0 +1,60 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WarmupProxy may be implemented by proxy which is able to establish connections to backend in
+// advance – so that the first proxy call does not pay connection setup latency.
+type WarmupProxy interface {
+	Warmup(ctx context.Context) error
+}
+
+// WarmupProxies warms up proxies concurrently and waits until all of them are done or ctx is
+// done. Warmup failures are logged but otherwise ignored since proxy still connects upon the
+// first call.
+func WarmupProxies(ctx context.Context, proxies map[string]WarmupProxy) {
+	var wg sync.WaitGroup
+	for name, p := range proxies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started := time.Now()
+			if err := p.Warmup(ctx); err != nil {
+				log.Warn().Err(err).Str("proxy_name", name).Msg("error warming up proxy")
+				return
+			}
+			log.Debug().Str("proxy_name", name).Str("duration", time.Since(started).String()).Msg("proxy warmed up")
+		}()
+	}
+	wg.Wait()
+}
+
+// warmupHTTP sends HEAD request to endpoint origin, so that connection to backend is established
+// and kept in client's idle pool. Any response status is considered a success.
+func warmupHTTP(ctx context.Context, client *http.Client, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("error parsing endpoint: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.Scheme+"://"+u.Host+"/", nil)
+	if err != nil {
+		return fmt.Errorf("error constructing HTTP request: %w", err)
+	}
+	req.Header.Set("User-Agent", defaultUserAgent())
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %w", err)
+	}
+	// Drain body to let connection be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifugo/v6/internal/configtypes"
+	"github.com/centrifugal/centrifugo/v6/internal/proxyproto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestHTTPCacheEmptyProxyWarmup(t *testing.T) {
+	var newConns atomic.Int64
+	var heads atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"populated":true}}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	p, err := NewHTTPCacheEmptyProxy("test", Config{
+		Endpoint: configtypes.String(server.URL + "/channels/{channel}/populate"),
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.Warmup(context.Background()))
+	require.Equal(t, int64(1), heads.Load())
+	require.Equal(t, int64(1), newConns.Load())
+
+	// Call reuses connection established upon warmup.
+	_, err = p.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), newConns.Load())
+}
+
+func TestGRPCCacheEmptyProxyWarmup(t *testing.T) {
+	_, cfg := newBufconnCacheEmptyServer(t)
+	p, err := NewGRPCCacheEmptyProxy("test", cfg)
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+	require.Equal(t, connectivity.Idle, p.conns.conns[0].conn.GetState())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, p.Warmup(ctx))
+	require.Equal(t, connectivity.Ready, p.conns.conns[0].conn.GetState())
+}
+
+type failingWarmupProxy struct {
+	calls atomic.Int64
+}
+
+func (p *failingWarmupProxy) Warmup(ctx context.Context) error {
+	p.calls.Add(1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWarmupProxiesFailureNotFatal(t *testing.T) {
+	buf := captureLogs(t)
+	_, cfg := newBufconnCacheEmptyServer(t)
+	grpcProxy, err := NewGRPCCacheEmptyProxy("grpc", cfg)
+	require.NoError(t, err)
+	defer func() { _ = grpcProxy.Close() }()
+	httpProxy, err := NewHTTPCacheEmptyProxy("http", Config{
+		Endpoint: "http://127.0.0.1:1",
+		Timeout:  configtypes.Duration(time.Second),
+	})
+	require.NoError(t, err)
+	stuck := &failingWarmupProxy{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	WarmupProxies(ctx, map[string]WarmupProxy{"grpc": grpcProxy, "http": httpProxy, "stuck": stuck})
+	require.Less(t, time.Since(started), time.Second)
+	require.True(t, errors.Is(ctx.Err(), context.DeadlineExceeded))
+
+	require.Equal(t, int64(1), stuck.calls.Load())
+	require.Equal(t, connectivity.Ready, grpcProxy.conns.conns[0].conn.GetState())
+	require.Contains(t, buf.String(), `"proxy_name":"http"`)
+	require.Contains(t, buf.String(), `"proxy_name":"stuck"`)
+	require.Contains(t, buf.String(), "error warming up proxy")
+
+	// Failures do not affect proxies warmed up successfully.
+	_, err = grpcProxy.ProxyCacheEmpty(context.Background(), &proxyproto.NotifyCacheEmptyRequest{Channel: "test"})
+	require.NoError(t, err)
+}
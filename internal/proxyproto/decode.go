@@ -1,6 +1,10 @@
 package proxyproto
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+)
 
 type ResponseDecoder interface {
 	DecodeConnectResponse(data []byte) (*ConnectResponse, error)
@@ -13,6 +17,8 @@ type ResponseDecoder interface {
 }
 
 var _ ResponseDecoder = (*JSONDecoder)(nil)
+var _ ResponseDecoder = (*ProtobufDecoder)(nil)
+var _ ResponseDecoder = (*MsgpackDecoder)(nil)
 
 type JSONDecoder struct{}
 
@@ -78,3 +84,133 @@ func (e *JSONDecoder) DecodeNotifyCacheEmptyResponse(data []byte) (*NotifyCacheE
 	}
 	return &resp, nil
 }
+
+type ProtobufDecoder struct{}
+
+func (e *ProtobufDecoder) DecodeConnectResponse(data []byte) (*ConnectResponse, error) {
+	var resp ConnectResponse
+	err := proto.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *ProtobufDecoder) DecodeRefreshResponse(data []byte) (*RefreshResponse, error) {
+	var resp RefreshResponse
+	err := proto.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *ProtobufDecoder) DecodeRPCResponse(data []byte) (*RPCResponse, error) {
+	var resp RPCResponse
+	err := proto.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *ProtobufDecoder) DecodeSubscribeResponse(data []byte) (*SubscribeResponse, error) {
+	var resp SubscribeResponse
+	err := proto.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *ProtobufDecoder) DecodePublishResponse(data []byte) (*PublishResponse, error) {
+	var resp PublishResponse
+	err := proto.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *ProtobufDecoder) DecodeSubRefreshResponse(data []byte) (*SubRefreshResponse, error) {
+	var resp SubRefreshResponse
+	err := proto.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *ProtobufDecoder) DecodeNotifyCacheEmptyResponse(data []byte) (*NotifyCacheEmptyResponse, error) {
+	var resp NotifyCacheEmptyResponse
+	err := proto.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+type MsgpackDecoder struct{}
+
+func (e *MsgpackDecoder) DecodeConnectResponse(data []byte) (*ConnectResponse, error) {
+	var resp ConnectResponse
+	err := UnmarshalMsgpack(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *MsgpackDecoder) DecodeRefreshResponse(data []byte) (*RefreshResponse, error) {
+	var resp RefreshResponse
+	err := UnmarshalMsgpack(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *MsgpackDecoder) DecodeRPCResponse(data []byte) (*RPCResponse, error) {
+	var resp RPCResponse
+	err := UnmarshalMsgpack(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *MsgpackDecoder) DecodeSubscribeResponse(data []byte) (*SubscribeResponse, error) {
+	var resp SubscribeResponse
+	err := UnmarshalMsgpack(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *MsgpackDecoder) DecodePublishResponse(data []byte) (*PublishResponse, error) {
+	var resp PublishResponse
+	err := UnmarshalMsgpack(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *MsgpackDecoder) DecodeSubRefreshResponse(data []byte) (*SubRefreshResponse, error) {
+	var resp SubRefreshResponse
+	err := UnmarshalMsgpack(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (e *MsgpackDecoder) DecodeNotifyCacheEmptyResponse(data []byte) (*NotifyCacheEmptyResponse, error) {
+	var resp NotifyCacheEmptyResponse
+	err := UnmarshalMsgpack(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -1,6 +1,10 @@
 package proxyproto
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+)
 
 type RequestEncoder interface {
 	EncodeConnectRequest(req *ConnectRequest) ([]byte, error)
@@ -13,33 +17,106 @@ type RequestEncoder interface {
 }
 
 var _ RequestEncoder = (*JSONEncoder)(nil)
+var _ RequestEncoder = (*ProtobufEncoder)(nil)
+var _ RequestEncoder = (*MsgpackEncoder)(nil)
 
-type JSONEncoder struct{}
+type JSONEncoder struct {
+	// Indent makes encoder produce indented JSON, by default JSON is compact.
+	Indent bool
+}
+
+// marshal never appends trailing newline to encoded JSON in both compact and indented forms.
+func (e *JSONEncoder) marshal(v any) ([]byte, error) {
+	if e.Indent {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
+}
 
 func (e *JSONEncoder) EncodeConnectRequest(req *ConnectRequest) ([]byte, error) {
-	return json.Marshal(req)
+	return e.marshal(req)
 }
 
 func (e *JSONEncoder) EncodeRefreshRequest(req *RefreshRequest) ([]byte, error) {
-	return json.Marshal(req)
+	return e.marshal(req)
 }
 
 func (e *JSONEncoder) EncodeRPCRequest(req *RPCRequest) ([]byte, error) {
-	return json.Marshal(req)
+	return e.marshal(req)
 }
 
 func (e *JSONEncoder) EncodeSubscribeRequest(req *SubscribeRequest) ([]byte, error) {
-	return json.Marshal(req)
+	return e.marshal(req)
 }
 
 func (e *JSONEncoder) EncodePublishRequest(req *PublishRequest) ([]byte, error) {
-	return json.Marshal(req)
+	return e.marshal(req)
 }
 
 func (e *JSONEncoder) EncodeSubRefreshRequest(req *SubRefreshRequest) ([]byte, error) {
-	return json.Marshal(req)
+	return e.marshal(req)
 }
 
 func (e *JSONEncoder) EncodeNotifyCacheEmptyRequest(req *NotifyCacheEmptyRequest) ([]byte, error) {
-	return json.Marshal(req)
+	return e.marshal(req)
+}
+
+type ProtobufEncoder struct{}
+
+func (e *ProtobufEncoder) EncodeConnectRequest(req *ConnectRequest) ([]byte, error) {
+	return proto.Marshal(req)
+}
+
+func (e *ProtobufEncoder) EncodeRefreshRequest(req *RefreshRequest) ([]byte, error) {
+	return proto.Marshal(req)
+}
+
+func (e *ProtobufEncoder) EncodeRPCRequest(req *RPCRequest) ([]byte, error) {
+	return proto.Marshal(req)
+}
+
+func (e *ProtobufEncoder) EncodeSubscribeRequest(req *SubscribeRequest) ([]byte, error) {
+	return proto.Marshal(req)
+}
+
+func (e *ProtobufEncoder) EncodePublishRequest(req *PublishRequest) ([]byte, error) {
+	return proto.Marshal(req)
+}
+
+func (e *ProtobufEncoder) EncodeSubRefreshRequest(req *SubRefreshRequest) ([]byte, error) {
+	return proto.Marshal(req)
+}
+
+func (e *ProtobufEncoder) EncodeNotifyCacheEmptyRequest(req *NotifyCacheEmptyRequest) ([]byte, error) {
+	return proto.Marshal(req)
+}
+
+type MsgpackEncoder struct{}
+
+func (e *MsgpackEncoder) EncodeConnectRequest(req *ConnectRequest) ([]byte, error) {
+	return MarshalMsgpack(req)
+}
+
+func (e *MsgpackEncoder) EncodeRefreshRequest(req *RefreshRequest) ([]byte, error) {
+	return MarshalMsgpack(req)
+}
+
+func (e *MsgpackEncoder) EncodeRPCRequest(req *RPCRequest) ([]byte, error) {
+	return MarshalMsgpack(req)
+}
+
+func (e *MsgpackEncoder) EncodeSubscribeRequest(req *SubscribeRequest) ([]byte, error) {
+	return MarshalMsgpack(req)
+}
+
+func (e *MsgpackEncoder) EncodePublishRequest(req *PublishRequest) ([]byte, error) {
+	return MarshalMsgpack(req)
+}
+
+func (e *MsgpackEncoder) EncodeSubRefreshRequest(req *SubRefreshRequest) ([]byte, error) {
+	return MarshalMsgpack(req)
+}
+
+func (e *MsgpackEncoder) EncodeNotifyCacheEmptyRequest(req *NotifyCacheEmptyRequest) ([]byte, error) {
+	return MarshalMsgpack(req)
 }
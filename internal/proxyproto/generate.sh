@@ -14,7 +14,7 @@ protoc-gen-go --version
 protoc-gen-go-grpc --version
 
 protoc -I ./ \
-  proxy.proto \
+  proxy.proto proxy_batch.proto \
   --go_out=. \
   --go-grpc_out=.
 
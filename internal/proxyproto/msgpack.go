@@ -0,0 +1,545 @@
+package proxyproto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Minimal MessagePack (https://github.com/msgpack/msgpack/blob/master/spec.md) codec for
+// proxy messages. Structs are encoded as maps keyed by JSON field names, so the payload
+// structure is the same as with JSON encoding – except that Raw and []byte fields are
+// encoded as MessagePack binary instead of embedded JSON.
+
+var errMsgpackTruncated = errors.New("msgpack: unexpected end of data")
+
+type msgpackField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+var msgpackFieldsCache sync.Map // reflect.Type -> []msgpackField
+
+func msgpackFields(t reflect.Type) []msgpackField {
+	if cached, ok := msgpackFieldsCache.Load(t); ok {
+		return cached.([]msgpackField)
+	}
+	var fields []msgpackField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, msgpackField{index: i, name: name, omitEmpty: opts == "omitempty"})
+	}
+	msgpackFieldsCache.Store(t, fields)
+	return fields
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+// MarshalMsgpack encodes v into MessagePack.
+func MarshalMsgpack(v any) ([]byte, error) {
+	var buf []byte
+	return appendMsgpack(buf, reflect.ValueOf(v))
+}
+
+func appendMsgpack(b []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return append(b, 0xc0), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return append(b, 0xc0), nil
+		}
+		return appendMsgpack(b, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendMsgpackInt(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return appendMsgpackUint(b, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		b = append(b, 0xcb)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		return appendMsgpackString(b, v.String()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.IsNil() {
+				return append(b, 0xc0), nil
+			}
+			return appendMsgpackBinary(b, v.Bytes()), nil
+		}
+		if v.IsNil() {
+			return append(b, 0xc0), nil
+		}
+		b = appendMsgpackHeader(b, v.Len(), 0x90, 16, 0xdc, 0xdd)
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if b, err = appendMsgpack(b, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("msgpack: unsupported map key type %s", v.Type().Key())
+		}
+		if v.IsNil() {
+			return append(b, 0xc0), nil
+		}
+		b = appendMsgpackHeader(b, v.Len(), 0x80, 16, 0xde, 0xdf)
+		var err error
+		iter := v.MapRange()
+		for iter.Next() {
+			b = appendMsgpackString(b, iter.Key().String())
+			if b, err = appendMsgpack(b, iter.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Struct:
+		fields := msgpackFields(v.Type())
+		n := 0
+		for _, f := range fields {
+			if !f.omitEmpty || !isEmptyValue(v.Field(f.index)) {
+				n++
+			}
+		}
+		b = appendMsgpackHeader(b, n, 0x80, 16, 0xde, 0xdf)
+		var err error
+		for _, f := range fields {
+			fv := v.Field(f.index)
+			if f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			b = appendMsgpackString(b, f.name)
+			if b, err = appendMsgpack(b, fv); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+}
+
+// appendMsgpackHeader appends array or map header: fix format for n < fixMax, otherwise
+// 16 or 32 bit length.
+func appendMsgpackHeader(b []byte, n int, fixCode byte, fixMax int, code16, code32 byte) []byte {
+	switch {
+	case n < fixMax:
+		return append(b, fixCode|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, code16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, code32), uint32(n))
+	}
+}
+
+func appendMsgpackInt(b []byte, n int64) []byte {
+	if n >= 0 {
+		return appendMsgpackUint(b, uint64(n))
+	}
+	switch {
+	case n >= -32:
+		return append(b, byte(n))
+	case n >= math.MinInt8:
+		return append(b, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(n))
+	}
+}
+
+func appendMsgpackUint(b []byte, n uint64) []byte {
+	switch {
+	case n <= 127:
+		return append(b, byte(n))
+	case n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), n)
+	}
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackBinary(b []byte, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, data...)
+}
+
+// UnmarshalMsgpack decodes MessagePack data into v which must be a non-nil pointer.
+// Unknown struct fields are skipped.
+func UnmarshalMsgpack(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("msgpack: decode target must be a non-nil pointer")
+	}
+	d := &msgpackDecoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return errors.New("msgpack: unexpected data after top-level value")
+	}
+	return nil
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgpackTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+type msgpackKind int
+
+const (
+	msgpackNil msgpackKind = iota
+	msgpackBool
+	msgpackInt
+	msgpackUint
+	msgpackFloat
+	msgpackString
+	msgpackBinary
+	msgpackArray
+	msgpackMap
+)
+
+// msgpackToken holds decoded scalar value or number of elements in array or map.
+type msgpackToken struct {
+	kind  msgpackKind
+	b     bool
+	i     int64
+	u     uint64
+	f     float64
+	bytes []byte
+	n     int
+}
+
+func (d *msgpackDecoder) next() (msgpackToken, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return msgpackToken{}, err
+	}
+	c := b[0]
+	var length uint64
+	switch {
+	case c <= 0x7f:
+		return msgpackToken{kind: msgpackUint, u: uint64(c)}, nil
+	case c >= 0xe0:
+		return msgpackToken{kind: msgpackInt, i: int64(int8(c))}, nil
+	case c&0xf0 == 0x80:
+		return msgpackToken{kind: msgpackMap, n: int(c & 0x0f)}, nil
+	case c&0xf0 == 0x90:
+		return msgpackToken{kind: msgpackArray, n: int(c & 0x0f)}, nil
+	case c&0xe0 == 0xa0:
+		s, err := d.read(int(c & 0x1f))
+		return msgpackToken{kind: msgpackString, bytes: s}, err
+	}
+	switch c {
+	case 0xc0:
+		return msgpackToken{kind: msgpackNil}, nil
+	case 0xc2, 0xc3:
+		return msgpackToken{kind: msgpackBool, b: c == 0xc3}, nil
+	case 0xc4, 0xc5, 0xc6:
+		if length, err = d.readUint(1 << (c - 0xc4)); err != nil {
+			return msgpackToken{}, err
+		}
+		data, err := d.read(int(length))
+		return msgpackToken{kind: msgpackBinary, bytes: data}, err
+	case 0xca:
+		u, err := d.readUint(4)
+		return msgpackToken{kind: msgpackFloat, f: float64(math.Float32frombits(uint32(u)))}, err
+	case 0xcb:
+		u, err := d.readUint(8)
+		return msgpackToken{kind: msgpackFloat, f: math.Float64frombits(u)}, err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (c - 0xcc))
+		return msgpackToken{kind: msgpackUint, u: u}, err
+	case 0xd0:
+		u, err := d.readUint(1)
+		return msgpackToken{kind: msgpackInt, i: int64(int8(u))}, err
+	case 0xd1:
+		u, err := d.readUint(2)
+		return msgpackToken{kind: msgpackInt, i: int64(int16(u))}, err
+	case 0xd2:
+		u, err := d.readUint(4)
+		return msgpackToken{kind: msgpackInt, i: int64(int32(u))}, err
+	case 0xd3:
+		u, err := d.readUint(8)
+		return msgpackToken{kind: msgpackInt, i: int64(u)}, err
+	case 0xd9, 0xda, 0xdb:
+		if length, err = d.readUint(1 << (c - 0xd9)); err != nil {
+			return msgpackToken{}, err
+		}
+		s, err := d.read(int(length))
+		return msgpackToken{kind: msgpackString, bytes: s}, err
+	case 0xdc, 0xdd:
+		length, err = d.readUint(2 << (c - 0xdc))
+		return msgpackToken{kind: msgpackArray, n: int(length)}, err
+	case 0xde, 0xdf:
+		length, err = d.readUint(2 << (c - 0xde))
+		return msgpackToken{kind: msgpackMap, n: int(length)}, err
+	default:
+		return msgpackToken{}, fmt.Errorf("msgpack: unsupported format 0x%x", c)
+	}
+}
+
+// skip skips n values.
+func (d *msgpackDecoder) skip(n int) error {
+	for ; n > 0; n-- {
+		tok, err := d.next()
+		if err != nil {
+			return err
+		}
+		switch tok.kind {
+		case msgpackArray:
+			if err := d.skip(tok.n); err != nil {
+				return err
+			}
+		case msgpackMap:
+			if err := d.skip(2 * tok.n); err != nil {
+				return err
+			}
+		default:
+		}
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) decode(v reflect.Value) error {
+	tok, err := d.next()
+	if err != nil {
+		return err
+	}
+	return d.decodeToken(tok, v)
+}
+
+func (d *msgpackDecoder) decodeToken(tok msgpackToken, v reflect.Value) error {
+	if tok.kind == msgpackNil {
+		v.SetZero()
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeToken(tok, v.Elem())
+	}
+	mismatch := func() error {
+		return fmt.Errorf("msgpack: can not decode value into %s", v.Type())
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if tok.kind != msgpackBool {
+			return mismatch()
+		}
+		v.SetBool(tok.b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch tok.kind {
+		case msgpackInt:
+			n = tok.i
+		case msgpackUint:
+			if tok.u > math.MaxInt64 {
+				return mismatch()
+			}
+			n = int64(tok.u)
+		default:
+			return mismatch()
+		}
+		if v.OverflowInt(n) {
+			return mismatch()
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch tok.kind {
+		case msgpackUint:
+			n = tok.u
+		case msgpackInt:
+			if tok.i < 0 {
+				return mismatch()
+			}
+			n = uint64(tok.i)
+		default:
+			return mismatch()
+		}
+		if v.OverflowUint(n) {
+			return mismatch()
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch tok.kind {
+		case msgpackFloat:
+			v.SetFloat(tok.f)
+		case msgpackInt:
+			v.SetFloat(float64(tok.i))
+		case msgpackUint:
+			v.SetFloat(float64(tok.u))
+		default:
+			return mismatch()
+		}
+	case reflect.String:
+		if tok.kind != msgpackString && tok.kind != msgpackBinary {
+			return mismatch()
+		}
+		v.SetString(string(tok.bytes))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if tok.kind != msgpackBinary && tok.kind != msgpackString {
+				return mismatch()
+			}
+			v.SetBytes(append([]byte{}, tok.bytes...))
+			return nil
+		}
+		if tok.kind != msgpackArray {
+			return mismatch()
+		}
+		if tok.n > len(d.data)-d.pos {
+			return errMsgpackTruncated
+		}
+		s := reflect.MakeSlice(v.Type(), tok.n, tok.n)
+		for i := 0; i < tok.n; i++ {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		if tok.kind != msgpackMap || v.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		if tok.n > (len(d.data)-d.pos)/2 {
+			return errMsgpackTruncated
+		}
+		m := reflect.MakeMapWithSize(v.Type(), tok.n)
+		for i := 0; i < tok.n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.decode(key); err != nil {
+				return err
+			}
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(value); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		if tok.kind != msgpackMap {
+			return mismatch()
+		}
+		fields := msgpackFields(v.Type())
+		for i := 0; i < tok.n; i++ {
+			keyTok, err := d.next()
+			if err != nil {
+				return err
+			}
+			if keyTok.kind != msgpackString {
+				return errors.New("msgpack: struct field name must be a string")
+			}
+			name := string(keyTok.bytes)
+			found := false
+			for _, f := range fields {
+				if f.name == name {
+					found = true
+					if err := d.decode(v.Field(f.index)); err != nil {
+						return fmt.Errorf("msgpack: field %s: %w", name, err)
+					}
+					break
+				}
+			}
+			if !found {
+				if err := d.skip(1); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return mismatch()
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package proxyproto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	binaryData := []byte{0x00, 0xff, 0xc1, 0x7f, 0x80}
+	resp := &ConnectResponse{
+		Result: &ConnectResult{
+			User:     "user",
+			ExpireAt: 1700000000,
+			Data:     binaryData,
+			Channels: []string{"a", "b", strings.Repeat("c", 300)},
+			Subs: map[string]*SubscribeOptions{
+				"news": {ExpireAt: -1, Info: []byte(`{"x":1}`)},
+			},
+			Caps: []*ChannelsCapability{{Channels: []string{"x"}, Allow: []string{"sub"}}},
+		},
+		Disconnect: &Disconnect{Code: 4500, Reason: "bye"},
+	}
+
+	data, err := MarshalMsgpack(resp)
+	require.NoError(t, err)
+
+	decoded, err := (&MsgpackDecoder{}).DecodeConnectResponse(data)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(resp, decoded))
+	require.Equal(t, binaryData, []byte(decoded.Result.Data))
+}
+
+func TestMsgpackBinaryFieldNotBase64(t *testing.T) {
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	data, err := (&MsgpackEncoder{}).EncodeRPCRequest(&RPCRequest{Method: "m", Data: payload})
+	require.NoError(t, err)
+	// Binary field is sent as MessagePack bin 8 with raw bytes.
+	require.Contains(t, string(data), string(append([]byte{0xc4, 0x04}, payload...)))
+
+	var req RPCRequest
+	require.NoError(t, UnmarshalMsgpack(data, &req))
+	require.Equal(t, payload, []byte(req.Data))
+	require.Empty(t, req.B64Data)
+}
+
+func TestMsgpackUnknownFieldsSkipped(t *testing.T) {
+	// {"unknown": [1, {"a": nil}], "result": {"populated": true}}
+	data := []byte{0x82,
+		0xa7, 'u', 'n', 'k', 'n', 'o', 'w', 'n', 0x92, 0x01, 0x81, 0xa1, 'a', 0xc0,
+		0xa6, 'r', 'e', 's', 'u', 'l', 't', 0x81, 0xa9, 'p', 'o', 'p', 'u', 'l', 'a', 't', 'e', 'd', 0xc3,
+	}
+	resp, err := (&MsgpackDecoder{}).DecodeNotifyCacheEmptyResponse(data)
+	require.NoError(t, err)
+	require.True(t, resp.Result.Populated)
+}
+
+func TestMsgpackDecodeErrors(t *testing.T) {
+	full, err := MarshalMsgpack(&NotifyCacheEmptyResponse{Result: &NotifyCacheEmptyResult{Populated: true}})
+	require.NoError(t, err)
+
+	var resp NotifyCacheEmptyResponse
+	require.Error(t, UnmarshalMsgpack(full[:len(full)-1], &resp))
+	require.Error(t, UnmarshalMsgpack(append(full, 0x00), &resp))
+	// Type mismatch: string instead of bool.
+	require.Error(t, UnmarshalMsgpack([]byte{0x81, 0xa6, 'r', 'e', 's', 'u', 'l', 't', 0x81, 0xa9, 'p', 'o', 'p', 'u', 'l', 'a', 't', 'e', 'd', 0xa1, 'x'}, &resp))
+	// Array header larger than data.
+	require.Error(t, UnmarshalMsgpack([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}, &[]string{}))
+	require.Error(t, UnmarshalMsgpack([]byte{0xc0}, (*NotifyCacheEmptyResponse)(nil)))
+}
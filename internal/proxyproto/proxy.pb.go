@@ -2055,8 +2055,13 @@ func (x *StreamSubscribeResponse) GetPublication() *Publication {
 }
 
 type NotifyCacheEmptyRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Channel       string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Channel string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	// Fields below describe subscriber which triggered cache empty event, only set when known.
+	Client        string `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	Encoding      string `protobuf:"bytes,3,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	User          string `protobuf:"bytes,10,opt,name=user,proto3" json:"user,omitempty"`
+	Meta          Raw    `protobuf:"bytes,11,opt,name=meta,proto3" json:"meta,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -2098,6 +2103,34 @@ func (x *NotifyCacheEmptyRequest) GetChannel() string {
 	return ""
 }
 
+func (x *NotifyCacheEmptyRequest) GetClient() string {
+	if x != nil {
+		return x.Client
+	}
+	return ""
+}
+
+func (x *NotifyCacheEmptyRequest) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+func (x *NotifyCacheEmptyRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *NotifyCacheEmptyRequest) GetMeta() []byte {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
 type NotifyCacheEmptyResponse struct {
 	state         protoimpl.MessageState  `protogen:"open.v1"`
 	Result        *NotifyCacheEmptyResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
@@ -2143,8 +2176,11 @@ func (x *NotifyCacheEmptyResponse) GetResult() *NotifyCacheEmptyResult {
 }
 
 type NotifyCacheEmptyResult struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Populated     bool                   `protobuf:"varint,1,opt,name=populated,proto3" json:"populated,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Populated bool                   `protobuf:"varint,1,opt,name=populated,proto3" json:"populated,omitempty"`
+	// no_cache tells that channel is not cacheable, so Centrifugo does not call proxy for it
+	// during configured period after this result.
+	NoCache       bool `protobuf:"varint,2,opt,name=no_cache,json=noCache,proto3" json:"no_cache,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -2186,6 +2222,13 @@ func (x *NotifyCacheEmptyResult) GetPopulated() bool {
 	return false
 }
 
+func (x *NotifyCacheEmptyResult) GetNoCache() bool {
+	if x != nil {
+		return x.NoCache
+	}
+	return false
+}
+
 type NotifyChannelStateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Events        []*ChannelEvent        `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
@@ -2566,13 +2609,19 @@ const file_proxy_proto_rawDesc = "" +
 	"\vpublication\x18\x02 \x01(\v2).centrifugal.centrifugo.proxy.PublicationR\vpublication\"\xc6\x01\n" +
 	"\x17StreamSubscribeResponse\x12^\n" +
 	"\x12subscribe_response\x18\x01 \x01(\v2/.centrifugal.centrifugo.proxy.SubscribeResponseR\x11subscribeResponse\x12K\n" +
-	"\vpublication\x18\x02 \x01(\v2).centrifugal.centrifugo.proxy.PublicationR\vpublication\"3\n" +
+	"\vpublication\x18\x02 \x01(\v2).centrifugal.centrifugo.proxy.PublicationR\vpublication\"\x8f\x01\n" +
 	"\x17NotifyCacheEmptyRequest\x12\x18\n" +
-	"\achannel\x18\x01 \x01(\tR\achannel\"h\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12\x16\n" +
+	"\x06client\x18\x02 \x01(\tR\x06client\x12\x1a\n" +
+	"\bencoding\x18\x03 \x01(\tR\bencoding\x12\x12\n" +
+	"\x04user\x18\n" +
+	" \x01(\tR\x04user\x12\x12\n" +
+	"\x04meta\x18\v \x01(\fR\x04meta\"h\n" +
 	"\x18NotifyCacheEmptyResponse\x12L\n" +
-	"\x06result\x18\x01 \x01(\v24.centrifugal.centrifugo.proxy.NotifyCacheEmptyResultR\x06result\"6\n" +
+	"\x06result\x18\x01 \x01(\v24.centrifugal.centrifugo.proxy.NotifyCacheEmptyResultR\x06result\"Q\n" +
 	"\x16NotifyCacheEmptyResult\x12\x1c\n" +
-	"\tpopulated\x18\x01 \x01(\bR\tpopulated\"_\n" +
+	"\tpopulated\x18\x01 \x01(\bR\tpopulated\x12\x19\n" +
+	"\bno_cache\x18\x02 \x01(\bR\anoCache\"_\n" +
 	"\x19NotifyChannelStateRequest\x12B\n" +
 	"\x06events\x18\x01 \x03(\v2*.centrifugal.centrifugo.proxy.ChannelEventR\x06events\"U\n" +
 	"\fChannelEvent\x12\x17\n" +
@@ -2582,7 +2631,8 @@ const file_proxy_proto_rawDesc = "" +
 	"\x1aNotifyChannelStateResponse\x12N\n" +
 	"\x06result\x18\x01 \x01(\v26.centrifugal.centrifugo.proxy.NotifyChannelStateResultR\x06result\x129\n" +
 	"\x05error\x18\x02 \x01(\v2#.centrifugal.centrifugo.proxy.ErrorR\x05error\"\x1a\n" +
-	"\x18NotifyChannelStateResult2\xa3\t\n" +
+	"\x18NotifyChannelStateResult2\xaf\n" +
+	"\n" +
 	"\x0fCentrifugoProxy\x12f\n" +
 	"\aConnect\x12,.centrifugal.centrifugo.proxy.ConnectRequest\x1a-.centrifugal.centrifugo.proxy.ConnectResponse\x12f\n" +
 	"\aRefresh\x12,.centrifugal.centrifugo.proxy.RefreshRequest\x1a-.centrifugal.centrifugo.proxy.RefreshResponse\x12l\n" +
@@ -2593,7 +2643,8 @@ const file_proxy_proto_rawDesc = "" +
 	"SubRefresh\x12/.centrifugal.centrifugo.proxy.SubRefreshRequest\x1a0.centrifugal.centrifugo.proxy.SubRefreshResponse\x12\x82\x01\n" +
 	"\x17SubscribeUnidirectional\x12..centrifugal.centrifugo.proxy.SubscribeRequest\x1a5.centrifugal.centrifugo.proxy.StreamSubscribeResponse0\x01\x12\x89\x01\n" +
 	"\x16SubscribeBidirectional\x124.centrifugal.centrifugo.proxy.StreamSubscribeRequest\x1a5.centrifugal.centrifugo.proxy.StreamSubscribeResponse(\x010\x01\x12\x81\x01\n" +
-	"\x10NotifyCacheEmpty\x125.centrifugal.centrifugo.proxy.NotifyCacheEmptyRequest\x1a6.centrifugal.centrifugo.proxy.NotifyCacheEmptyResponse\x12\x87\x01\n" +
+	"\x10NotifyCacheEmpty\x125.centrifugal.centrifugo.proxy.NotifyCacheEmptyRequest\x1a6.centrifugal.centrifugo.proxy.NotifyCacheEmptyResponse\x12\x89\x01\n" +
+	"\x16NotifyCacheEmptyStream\x125.centrifugal.centrifugo.proxy.NotifyCacheEmptyRequest\x1a6.centrifugal.centrifugo.proxy.NotifyCacheEmptyResponse0\x01\x12\x87\x01\n" +
 	"\x12NotifyChannelState\x127.centrifugal.centrifugo.proxy.NotifyChannelStateRequest\x1a8.centrifugal.centrifugo.proxy.NotifyChannelStateResponseB\x0fZ\r./;proxyprotob\x06proto3"
 
 var (
@@ -2696,19 +2747,21 @@ var file_proxy_proto_depIdxs = []int32{
 	10, // 44: centrifugal.centrifugo.proxy.CentrifugoProxy.SubscribeUnidirectional:input_type -> centrifugal.centrifugo.proxy.SubscribeRequest
 	26, // 45: centrifugal.centrifugo.proxy.CentrifugoProxy.SubscribeBidirectional:input_type -> centrifugal.centrifugo.proxy.StreamSubscribeRequest
 	28, // 46: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyCacheEmpty:input_type -> centrifugal.centrifugo.proxy.NotifyCacheEmptyRequest
-	31, // 47: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyChannelState:input_type -> centrifugal.centrifugo.proxy.NotifyChannelStateRequest
-	6,  // 48: centrifugal.centrifugo.proxy.CentrifugoProxy.Connect:output_type -> centrifugal.centrifugo.proxy.ConnectResponse
-	9,  // 49: centrifugal.centrifugo.proxy.CentrifugoProxy.Refresh:output_type -> centrifugal.centrifugo.proxy.RefreshResponse
-	15, // 50: centrifugal.centrifugo.proxy.CentrifugoProxy.Subscribe:output_type -> centrifugal.centrifugo.proxy.SubscribeResponse
-	18, // 51: centrifugal.centrifugo.proxy.CentrifugoProxy.Publish:output_type -> centrifugal.centrifugo.proxy.PublishResponse
-	21, // 52: centrifugal.centrifugo.proxy.CentrifugoProxy.RPC:output_type -> centrifugal.centrifugo.proxy.RPCResponse
-	24, // 53: centrifugal.centrifugo.proxy.CentrifugoProxy.SubRefresh:output_type -> centrifugal.centrifugo.proxy.SubRefreshResponse
-	27, // 54: centrifugal.centrifugo.proxy.CentrifugoProxy.SubscribeUnidirectional:output_type -> centrifugal.centrifugo.proxy.StreamSubscribeResponse
-	27, // 55: centrifugal.centrifugo.proxy.CentrifugoProxy.SubscribeBidirectional:output_type -> centrifugal.centrifugo.proxy.StreamSubscribeResponse
-	29, // 56: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyCacheEmpty:output_type -> centrifugal.centrifugo.proxy.NotifyCacheEmptyResponse
-	33, // 57: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyChannelState:output_type -> centrifugal.centrifugo.proxy.NotifyChannelStateResponse
-	48, // [48:58] is the sub-list for method output_type
-	38, // [38:48] is the sub-list for method input_type
+	28, // 47: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyCacheEmptyStream:input_type -> centrifugal.centrifugo.proxy.NotifyCacheEmptyRequest
+	31, // 48: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyChannelState:input_type -> centrifugal.centrifugo.proxy.NotifyChannelStateRequest
+	6,  // 49: centrifugal.centrifugo.proxy.CentrifugoProxy.Connect:output_type -> centrifugal.centrifugo.proxy.ConnectResponse
+	9,  // 50: centrifugal.centrifugo.proxy.CentrifugoProxy.Refresh:output_type -> centrifugal.centrifugo.proxy.RefreshResponse
+	15, // 51: centrifugal.centrifugo.proxy.CentrifugoProxy.Subscribe:output_type -> centrifugal.centrifugo.proxy.SubscribeResponse
+	18, // 52: centrifugal.centrifugo.proxy.CentrifugoProxy.Publish:output_type -> centrifugal.centrifugo.proxy.PublishResponse
+	21, // 53: centrifugal.centrifugo.proxy.CentrifugoProxy.RPC:output_type -> centrifugal.centrifugo.proxy.RPCResponse
+	24, // 54: centrifugal.centrifugo.proxy.CentrifugoProxy.SubRefresh:output_type -> centrifugal.centrifugo.proxy.SubRefreshResponse
+	27, // 55: centrifugal.centrifugo.proxy.CentrifugoProxy.SubscribeUnidirectional:output_type -> centrifugal.centrifugo.proxy.StreamSubscribeResponse
+	27, // 56: centrifugal.centrifugo.proxy.CentrifugoProxy.SubscribeBidirectional:output_type -> centrifugal.centrifugo.proxy.StreamSubscribeResponse
+	29, // 57: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyCacheEmpty:output_type -> centrifugal.centrifugo.proxy.NotifyCacheEmptyResponse
+	29, // 58: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyCacheEmptyStream:output_type -> centrifugal.centrifugo.proxy.NotifyCacheEmptyResponse
+	33, // 59: centrifugal.centrifugo.proxy.CentrifugoProxy.NotifyChannelState:output_type -> centrifugal.centrifugo.proxy.NotifyChannelStateResponse
+	49, // [49:60] is the sub-list for method output_type
+	38, // [38:49] is the sub-list for method input_type
 	38, // [38:38] is the sub-list for extension type_name
 	38, // [38:38] is the sub-list for extension extendee
 	0,  // [0:38] is the sub-list for field type_name
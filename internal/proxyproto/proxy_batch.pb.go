@@ -0,0 +1,231 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.1
+// source: proxy_batch.proto
+
+package proxyproto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type NotifyCacheEmptyBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Channels      []string               `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyCacheEmptyBatchRequest) Reset() {
+	*x = NotifyCacheEmptyBatchRequest{}
+	mi := &file_proxy_batch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyCacheEmptyBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyCacheEmptyBatchRequest) ProtoMessage() {}
+
+func (x *NotifyCacheEmptyBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_batch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyCacheEmptyBatchRequest.ProtoReflect.Descriptor instead.
+func (*NotifyCacheEmptyBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proxy_batch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NotifyCacheEmptyBatchRequest) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type NotifyCacheEmptyBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Results for channels from request, channels missing in results are considered not populated.
+	Results       []*NotifyCacheEmptyBatchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyCacheEmptyBatchResponse) Reset() {
+	*x = NotifyCacheEmptyBatchResponse{}
+	mi := &file_proxy_batch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyCacheEmptyBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyCacheEmptyBatchResponse) ProtoMessage() {}
+
+func (x *NotifyCacheEmptyBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_batch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyCacheEmptyBatchResponse.ProtoReflect.Descriptor instead.
+func (*NotifyCacheEmptyBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proxy_batch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NotifyCacheEmptyBatchResponse) GetResults() []*NotifyCacheEmptyBatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type NotifyCacheEmptyBatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Channel       string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Populated     bool                   `protobuf:"varint,2,opt,name=populated,proto3" json:"populated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyCacheEmptyBatchResult) Reset() {
+	*x = NotifyCacheEmptyBatchResult{}
+	mi := &file_proxy_batch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyCacheEmptyBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyCacheEmptyBatchResult) ProtoMessage() {}
+
+func (x *NotifyCacheEmptyBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_batch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyCacheEmptyBatchResult.ProtoReflect.Descriptor instead.
+func (*NotifyCacheEmptyBatchResult) Descriptor() ([]byte, []int) {
+	return file_proxy_batch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NotifyCacheEmptyBatchResult) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *NotifyCacheEmptyBatchResult) GetPopulated() bool {
+	if x != nil {
+		return x.Populated
+	}
+	return false
+}
+
+var File_proxy_batch_proto protoreflect.FileDescriptor
+
+const file_proxy_batch_proto_rawDesc = "" +
+	"\n" +
+	"\x11proxy_batch.proto\x12\x1ccentrifugal.centrifugo.proxy\":\n" +
+	"\x1cNotifyCacheEmptyBatchRequest\x12\x1a\n" +
+	"\bchannels\x18\x01 \x03(\tR\bchannels\"t\n" +
+	"\x1dNotifyCacheEmptyBatchResponse\x12S\n" +
+	"\aresults\x18\x01 \x03(\v29.centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchResultR\aresults\"U\n" +
+	"\x1bNotifyCacheEmptyBatchResult\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12\x1c\n" +
+	"\tpopulated\x18\x02 \x01(\bR\tpopulated2\xa9\x01\n" +
+	"\x14CentrifugoProxyBatch\x12\x90\x01\n" +
+	"\x15NotifyCacheEmptyBatch\x12:.centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchRequest\x1a;.centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchResponseB\x0fZ\r./;proxyprotob\x06proto3"
+
+var (
+	file_proxy_batch_proto_rawDescOnce sync.Once
+	file_proxy_batch_proto_rawDescData []byte
+)
+
+func file_proxy_batch_proto_rawDescGZIP() []byte {
+	file_proxy_batch_proto_rawDescOnce.Do(func() {
+		file_proxy_batch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proxy_batch_proto_rawDesc), len(file_proxy_batch_proto_rawDesc)))
+	})
+	return file_proxy_batch_proto_rawDescData
+}
+
+var file_proxy_batch_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proxy_batch_proto_goTypes = []any{
+	(*NotifyCacheEmptyBatchRequest)(nil),  // 0: centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchRequest
+	(*NotifyCacheEmptyBatchResponse)(nil), // 1: centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchResponse
+	(*NotifyCacheEmptyBatchResult)(nil),   // 2: centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchResult
+}
+var file_proxy_batch_proto_depIdxs = []int32{
+	2, // 0: centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchResponse.results:type_name -> centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchResult
+	0, // 1: centrifugal.centrifugo.proxy.CentrifugoProxyBatch.NotifyCacheEmptyBatch:input_type -> centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchRequest
+	1, // 2: centrifugal.centrifugo.proxy.CentrifugoProxyBatch.NotifyCacheEmptyBatch:output_type -> centrifugal.centrifugo.proxy.NotifyCacheEmptyBatchResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proxy_batch_proto_init() }
+func file_proxy_batch_proto_init() {
+	if File_proxy_batch_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_batch_proto_rawDesc), len(file_proxy_batch_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proxy_batch_proto_goTypes,
+		DependencyIndexes: file_proxy_batch_proto_depIdxs,
+		MessageInfos:      file_proxy_batch_proto_msgTypes,
+	}.Build()
+	File_proxy_batch_proto = out.File
+	file_proxy_batch_proto_goTypes = nil
+	file_proxy_batch_proto_depIdxs = nil
+}
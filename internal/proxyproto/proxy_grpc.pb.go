@@ -28,6 +28,7 @@ const (
 	CentrifugoProxy_SubscribeUnidirectional_FullMethodName = "/centrifugal.centrifugo.proxy.CentrifugoProxy/SubscribeUnidirectional"
 	CentrifugoProxy_SubscribeBidirectional_FullMethodName  = "/centrifugal.centrifugo.proxy.CentrifugoProxy/SubscribeBidirectional"
 	CentrifugoProxy_NotifyCacheEmpty_FullMethodName        = "/centrifugal.centrifugo.proxy.CentrifugoProxy/NotifyCacheEmpty"
+	CentrifugoProxy_NotifyCacheEmptyStream_FullMethodName  = "/centrifugal.centrifugo.proxy.CentrifugoProxy/NotifyCacheEmptyStream"
 	CentrifugoProxy_NotifyChannelState_FullMethodName      = "/centrifugal.centrifugo.proxy.CentrifugoProxy/NotifyChannelState"
 )
 
@@ -59,6 +60,10 @@ type CentrifugoProxyClient interface {
 	SubscribeBidirectional(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamSubscribeRequest, StreamSubscribeResponse], error)
 	// NotifyCacheEmpty is an EXPERIMENTAL method which allows to load documents from the backend.
 	NotifyCacheEmpty(ctx context.Context, in *NotifyCacheEmptyRequest, opts ...grpc.CallOption) (*NotifyCacheEmptyResponse, error)
+	// NotifyCacheEmptyStream is an EXPERIMENTAL streaming variant of NotifyCacheEmpty for backends
+	// which populate cache incrementally. Backend streams NotifyCacheEmptyResponse messages with
+	// progress, Centrifugo considers cache populated once it receives result with populated flag.
+	NotifyCacheEmptyStream(ctx context.Context, in *NotifyCacheEmptyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NotifyCacheEmptyResponse], error)
 	// NotifyChannelState can be used to receive channel events such as channel "occupied" and "vacated".
 	// This is a feature in a preview state and is only available in Centrifugo PRO.
 	NotifyChannelState(ctx context.Context, in *NotifyChannelStateRequest, opts ...grpc.CallOption) (*NotifyChannelStateResponse, error)
@@ -174,6 +179,25 @@ func (c *centrifugoProxyClient) NotifyCacheEmpty(ctx context.Context, in *Notify
 	return out, nil
 }
 
+func (c *centrifugoProxyClient) NotifyCacheEmptyStream(ctx context.Context, in *NotifyCacheEmptyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NotifyCacheEmptyResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CentrifugoProxy_ServiceDesc.Streams[2], CentrifugoProxy_NotifyCacheEmptyStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[NotifyCacheEmptyRequest, NotifyCacheEmptyResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CentrifugoProxy_NotifyCacheEmptyStreamClient = grpc.ServerStreamingClient[NotifyCacheEmptyResponse]
+
 func (c *centrifugoProxyClient) NotifyChannelState(ctx context.Context, in *NotifyChannelStateRequest, opts ...grpc.CallOption) (*NotifyChannelStateResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(NotifyChannelStateResponse)
@@ -212,6 +236,10 @@ type CentrifugoProxyServer interface {
 	SubscribeBidirectional(grpc.BidiStreamingServer[StreamSubscribeRequest, StreamSubscribeResponse]) error
 	// NotifyCacheEmpty is an EXPERIMENTAL method which allows to load documents from the backend.
 	NotifyCacheEmpty(context.Context, *NotifyCacheEmptyRequest) (*NotifyCacheEmptyResponse, error)
+	// NotifyCacheEmptyStream is an EXPERIMENTAL streaming variant of NotifyCacheEmpty for backends
+	// which populate cache incrementally. Backend streams NotifyCacheEmptyResponse messages with
+	// progress, Centrifugo considers cache populated once it receives result with populated flag.
+	NotifyCacheEmptyStream(*NotifyCacheEmptyRequest, grpc.ServerStreamingServer[NotifyCacheEmptyResponse]) error
 	// NotifyChannelState can be used to receive channel events such as channel "occupied" and "vacated".
 	// This is a feature in a preview state and is only available in Centrifugo PRO.
 	NotifyChannelState(context.Context, *NotifyChannelStateRequest) (*NotifyChannelStateResponse, error)
@@ -252,6 +280,9 @@ func (UnimplementedCentrifugoProxyServer) SubscribeBidirectional(grpc.BidiStream
 func (UnimplementedCentrifugoProxyServer) NotifyCacheEmpty(context.Context, *NotifyCacheEmptyRequest) (*NotifyCacheEmptyResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method NotifyCacheEmpty not implemented")
 }
+func (UnimplementedCentrifugoProxyServer) NotifyCacheEmptyStream(*NotifyCacheEmptyRequest, grpc.ServerStreamingServer[NotifyCacheEmptyResponse]) error {
+	return status.Error(codes.Unimplemented, "method NotifyCacheEmptyStream not implemented")
+}
 func (UnimplementedCentrifugoProxyServer) NotifyChannelState(context.Context, *NotifyChannelStateRequest) (*NotifyChannelStateResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method NotifyChannelState not implemented")
 }
@@ -420,6 +451,17 @@ func _CentrifugoProxy_NotifyCacheEmpty_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CentrifugoProxy_NotifyCacheEmptyStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NotifyCacheEmptyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CentrifugoProxyServer).NotifyCacheEmptyStream(m, &grpc.GenericServerStream[NotifyCacheEmptyRequest, NotifyCacheEmptyResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CentrifugoProxy_NotifyCacheEmptyStreamServer = grpc.ServerStreamingServer[NotifyCacheEmptyResponse]
+
 func _CentrifugoProxy_NotifyChannelState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(NotifyChannelStateRequest)
 	if err := dec(in); err != nil {
@@ -490,6 +532,11 @@ var CentrifugoProxy_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "NotifyCacheEmptyStream",
+			Handler:       _CentrifugoProxy_NotifyCacheEmptyStream_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proxy.proto",
 }
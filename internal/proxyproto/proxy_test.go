@@ -23,3 +23,27 @@ func TestMarshalProtobuf(t *testing.T) {
 	_, err := proto.Marshal(msg)
 	require.NoError(t, err)
 }
+
+func TestJSONDecoderInt64Precision(t *testing.T) {
+	// 2^53 + 1 can not be represented as float64.
+	const expireAt int64 = 1<<53 + 1
+	data := []byte(`{"result":{"expire_at":9007199254740993}}`)
+	d := &JSONDecoder{}
+
+	connectResp, err := d.DecodeConnectResponse(data)
+	require.NoError(t, err)
+	require.Equal(t, expireAt, connectResp.Result.ExpireAt)
+
+	refreshResp, err := d.DecodeRefreshResponse(data)
+	require.NoError(t, err)
+	require.Equal(t, expireAt, refreshResp.Result.ExpireAt)
+
+	subRefreshResp, err := d.DecodeSubRefreshResponse(data)
+	require.NoError(t, err)
+	require.Equal(t, expireAt, subRefreshResp.Result.ExpireAt)
+
+	// Large numbers in fields unknown to NotifyCacheEmptyResult do not break decoding.
+	cacheEmptyResp, err := d.DecodeNotifyCacheEmptyResponse([]byte(`{"result":{"populated":true,"expire_at":9007199254740993}}`))
+	require.NoError(t, err)
+	require.True(t, cacheEmptyResp.Result.Populated)
+}